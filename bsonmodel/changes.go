@@ -0,0 +1,62 @@
+package bsonmodel
+
+import (
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ChangeKind is the kind of a change.
+type ChangeKind int
+
+const (
+	// ChangeUpdated means the value on the path has been set.
+	ChangeUpdated ChangeKind = iota + 1
+	// ChangeRemoved means the value on the path has been removed.
+	ChangeRemoved
+)
+
+func (kind ChangeKind) String() string {
+	switch kind {
+	case ChangeUpdated:
+		return "updated"
+	case ChangeRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is a changed path of a model.
+type Change struct {
+	// Path is the BSON dot notation of the changed value.
+	Path DotNotation
+	// Kind is the kind of the change.
+	Kind ChangeKind
+	// Value is the new BSON value, nil for removed values.
+	Value interface{}
+}
+
+// ChangesOf returns the changed and removed paths of the model, in the same
+// granularity as AppendUpdates(). The changes are sorted by their paths.
+func ChangesOf(model BsonModel) []Change {
+	changes := make([]Change, 0)
+	if !model.AnyUpdated() {
+		return changes
+	}
+	updates := model.AppendUpdates(bson.M{})
+	if dset, ok := updates["$set"].(bson.M); ok {
+		for name, value := range dset {
+			changes = append(changes, Change{Path: &path{name}, Kind: ChangeUpdated, Value: value})
+		}
+	}
+	if unset, ok := updates["$unset"].(bson.M); ok {
+		for name := range unset {
+			changes = append(changes, Change{Path: &path{name}, Kind: ChangeRemoved})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path.Value() < changes[j].Path.Value()
+	})
+	return changes
+}
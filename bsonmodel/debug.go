@@ -0,0 +1,26 @@
+package bsonmodel
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CheckReleased panics if the model has been released back to its pool.
+//
+// The check is only enabled in debug builds, which are built with the
+// `bsonmodel_debug` tag, otherwise it does nothing.
+func CheckReleased(released bool, name string) {
+	if Debug && released {
+		panic(errors.New(fmt.Sprintf("The model `%s` has been released", name)))
+	}
+}
+
+// CheckValueReleased panics if the map value has been released with the
+// root model it belonged to.
+//
+// The check is only enabled in debug builds, like CheckReleased().
+func CheckValueReleased(value MapValueModel, name string) {
+	if Debug && value.isReleased() {
+		panic(errors.New(fmt.Sprintf("The model `%s` has been released", name)))
+	}
+}
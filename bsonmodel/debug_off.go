@@ -0,0 +1,7 @@
+//go:build !bsonmodel_debug
+// +build !bsonmodel_debug
+
+package bsonmodel
+
+// Debug is whether the debug checks are enabled.
+const Debug = false
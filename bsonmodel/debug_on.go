@@ -0,0 +1,10 @@
+//go:build bsonmodel_debug
+// +build bsonmodel_debug
+
+package bsonmodel
+
+// Debug is whether the debug checks are enabled.
+//
+// In debug builds, released models are never reused by their pools, so that
+// any use after release can be detected by CheckReleased().
+const Debug = true
@@ -0,0 +1,109 @@
+package bsonmodel
+
+import (
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Diff returns the minimal update, with $set and $unset using dot notation,
+// which changes the old document into the new one.
+//
+// Embedded documents are compared field by field, other values, including
+// arrays, are replaced as a whole when they are different. Numbers are
+// compared by their values, so int32(1), int64(1) and float64(1) are equal.
+func Diff(oldDoc bson.M, newDoc bson.M) bson.M {
+	updates := bson.M{}
+	appendDiff(updates, RootPath(), oldDoc, newDoc)
+	return updates
+}
+
+func appendDiff(updates bson.M, xpath DotNotation, oldDoc bson.M, newDoc bson.M) {
+	for name, newValue := range newDoc {
+		oldValue, ok := oldDoc[name]
+		if ok {
+			oldEmbedded, oldIsDoc := oldValue.(bson.M)
+			newEmbedded, newIsDoc := newValue.(bson.M)
+			if oldIsDoc && newIsDoc {
+				appendDiff(updates, xpath.Resolve(name), oldEmbedded, newEmbedded)
+				continue
+			}
+			if ValueEquals(oldValue, newValue) {
+				continue
+			}
+		}
+		FixedEmbedded(updates, "$set")[xpath.Resolve(name).Value()] = newValue
+	}
+	for name := range oldDoc {
+		if _, ok := newDoc[name]; !ok {
+			FixedEmbedded(updates, "$unset")[xpath.Resolve(name).Value()] = ""
+		}
+	}
+}
+
+// ValueEquals returns whether the two BSON values are equal. Numbers are
+// compared by their values.
+func ValueEquals(a interface{}, b interface{}) bool {
+	if x, ok := intNumber(a); ok {
+		if y, ok := intNumber(b); ok {
+			return x == y
+		}
+		y, ok := b.(float64)
+		return ok && float64(x) == y
+	}
+	if x, ok := a.(float64); ok {
+		if y, ok := intNumber(b); ok {
+			return x == float64(y)
+		}
+		y, ok := b.(float64)
+		return ok && x == y
+	}
+	if x, ok := a.(bson.M); ok {
+		y, ok := b.(bson.M)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			w, ok := y[k]
+			if !ok || !ValueEquals(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	if x, ok := arrayValue(a); ok {
+		y, ok := arrayValue(b)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i, v := range x {
+			if !ValueEquals(v, y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func intNumber(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+func arrayValue(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case bson.A:
+		return v, true
+	case []interface{}:
+		return v, true
+	}
+	return nil, false
+}
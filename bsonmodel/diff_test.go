@@ -0,0 +1,58 @@
+package bsonmodel
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDiff(t *testing.T) {
+	oldDoc := bson.M{
+		"a": int32(1),
+		"b": bson.M{"x": int32(1), "y": "y", "z": bson.M{"k": 1.0}},
+		"c": bson.A{int32(1), int32(2)},
+		"d": "removed",
+		"e": bson.M{"x": 1},
+	}
+	newDoc := bson.M{
+		"a": int64(1),
+		"b": bson.M{"x": 1.0, "y": "changed", "z": bson.M{"k": 1}, "n": true},
+		"c": bson.A{int32(1), int32(3)},
+		"e": "replaced",
+		"f": bson.M{"x": 1},
+	}
+	expected := bson.M{
+		"$set": bson.M{
+			"b.y": "changed",
+			"b.n": true,
+			"c":   bson.A{int32(1), int32(3)},
+			"e":   "replaced",
+			"f":   bson.M{"x": 1},
+		},
+		"$unset": bson.M{"d": ""},
+	}
+	updates := Diff(oldDoc, newDoc)
+	if !reflect.DeepEqual(expected, updates) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, updates)
+	}
+	updates = Diff(newDoc, newDoc)
+	if len(updates) != 0 {
+		t.Errorf("The value expected <%v> but was <%v>", bson.M{}, updates)
+	}
+}
+
+func TestValueEquals(t *testing.T) {
+	if !ValueEquals(int32(1), 1.0) {
+		t.Error("The value expected true but was false")
+	}
+	if ValueEquals(int64(1)<<60+1, int64(1)<<60) {
+		t.Error("The value expected false but was true")
+	}
+	if !ValueEquals(bson.A{int32(1)}, []interface{}{int64(1)}) {
+		t.Error("The value expected true but was false")
+	}
+	if ValueEquals("1", 1) {
+		t.Error("The value expected false but was true")
+	}
+}
@@ -0,0 +1,80 @@
+package bsonmodel
+
+import (
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// JsonObjectWriter writes a JSON object into the stream, adding the comma
+// between fields.
+type JsonObjectWriter struct {
+	stream *jsoniter.Stream
+	more   bool
+}
+
+// BeginJsonObject writes the start of a JSON object into the stream.
+func BeginJsonObject(stream *jsoniter.Stream) JsonObjectWriter {
+	stream.WriteObjectStart()
+	return JsonObjectWriter{stream: stream}
+}
+
+// Field writes the name of the next field, the value should be written into
+// the stream just after it.
+func (w *JsonObjectWriter) Field(name string) {
+	if w.more {
+		w.stream.WriteMore()
+	} else {
+		w.more = true
+	}
+	w.stream.WriteObjectField(name)
+}
+
+// End writes the end of the JSON object.
+func (w *JsonObjectWriter) End() {
+	w.stream.WriteObjectEnd()
+}
+
+func encodeToString(encode func(stream *jsoniter.Stream)) (string, error) {
+	stream := jsoniter.ConfigDefault.BorrowStream(nil)
+	defer jsoniter.ConfigDefault.ReturnStream(stream)
+	encode(stream)
+	if stream.Error != nil {
+		return "", stream.Error
+	}
+	return string(stream.Buffer()), nil
+}
+
+func encodeToWriter(w io.Writer, encode func(stream *jsoniter.Stream)) error {
+	stream := jsoniter.ConfigDefault.BorrowStream(w)
+	defer jsoniter.ConfigDefault.ReturnStream(stream)
+	encode(stream)
+	if stream.Error != nil {
+		return stream.Error
+	}
+	return stream.Flush()
+}
+
+// DataJsonOf returns the JSON of the model in the same structure as ToData(),
+// encoded directly by EncodeData().
+func DataJsonOf(model BsonModel) (string, error) {
+	return encodeToString(model.EncodeData)
+}
+
+// SyncJsonOf returns the JSON of the model in the same structure as ToSync(),
+// encoded directly by EncodeSync().
+func SyncJsonOf(model BsonModel) (string, error) {
+	return encodeToString(model.EncodeSync)
+}
+
+// WriteDataJson writes the JSON of the model in the same structure as
+// ToData() into the writer.
+func WriteDataJson(w io.Writer, model BsonModel) error {
+	return encodeToWriter(w, model.EncodeData)
+}
+
+// WriteSyncJson writes the JSON of the model in the same structure as
+// ToSync() into the writer.
+func WriteSyncJson(w io.Writer, model BsonModel) error {
+	return encodeToWriter(w, model.EncodeSync)
+}
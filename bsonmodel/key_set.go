@@ -0,0 +1,144 @@
+package bsonmodel
+
+import "strconv"
+
+// intKeySet is the set of int keys used to track the changes of maps.
+type intKeySet map[int]struct{}
+
+func (s intKeySet) Add(key int) {
+	s[key] = struct{}{}
+}
+
+func (s intKeySet) Remove(key int) {
+	delete(s, key)
+}
+
+func (s intKeySet) Contains(key int) bool {
+	_, ok := s[key]
+	return ok
+}
+
+func (s intKeySet) Len() int {
+	return len(s)
+}
+
+func (s intKeySet) Clear() {
+	for key := range s {
+		delete(s, key)
+	}
+}
+
+func (s intKeySet) Clone() intKeySet {
+	clone := make(intKeySet, len(s))
+	for key := range s {
+		clone[key] = struct{}{}
+	}
+	return clone
+}
+
+func (s intKeySet) restore(key int, contained bool) {
+	if contained {
+		s[key] = struct{}{}
+	} else {
+		delete(s, key)
+	}
+}
+
+// stringKeySet is the set of string keys used to track the changes of maps.
+type stringKeySet map[string]struct{}
+
+func (s stringKeySet) Add(key string) {
+	s[key] = struct{}{}
+}
+
+func (s stringKeySet) Remove(key string) {
+	delete(s, key)
+}
+
+func (s stringKeySet) Contains(key string) bool {
+	_, ok := s[key]
+	return ok
+}
+
+func (s stringKeySet) Len() int {
+	return len(s)
+}
+
+func (s stringKeySet) Clear() {
+	for key := range s {
+		delete(s, key)
+	}
+}
+
+func (s stringKeySet) Clone() stringKeySet {
+	clone := make(stringKeySet, len(s))
+	for key := range s {
+		clone[key] = struct{}{}
+	}
+	return clone
+}
+
+func (s stringKeySet) restore(key string, contained bool) {
+	if contained {
+		s[key] = struct{}{}
+	} else {
+		delete(s, key)
+	}
+}
+
+// intKeyPaths caches the path values of the entries of a map with int keys.
+type intKeyPaths struct {
+	xpath DotNotation
+	paths map[int]string
+}
+
+func (c *intKeyPaths) get(xpath DotNotation, key int) string {
+	if c.paths == nil || c.xpath != xpath {
+		c.xpath = xpath
+		c.paths = make(map[int]string)
+	}
+	value, ok := c.paths[key]
+	if !ok {
+		value = xpath.Resolve(strconv.Itoa(key)).Value()
+		c.paths[key] = value
+	}
+	return value
+}
+
+func (c *intKeyPaths) remove(key int) {
+	delete(c.paths, key)
+}
+
+func (c *intKeyPaths) clear() {
+	c.xpath = nil
+	c.paths = nil
+}
+
+// stringKeyPaths caches the path values of the entries of a map with string
+// keys.
+type stringKeyPaths struct {
+	xpath DotNotation
+	paths map[string]string
+}
+
+func (c *stringKeyPaths) get(xpath DotNotation, key string) string {
+	if c.paths == nil || c.xpath != xpath {
+		c.xpath = xpath
+		c.paths = make(map[string]string)
+	}
+	value, ok := c.paths[key]
+	if !ok {
+		value = xpath.Resolve(key).Value()
+		c.paths[key] = value
+	}
+	return value
+}
+
+func (c *stringKeyPaths) remove(key string) {
+	delete(c.paths, key)
+}
+
+func (c *stringKeyPaths) clear() {
+	c.xpath = nil
+	c.paths = nil
+}
@@ -0,0 +1,45 @@
+package bsonmodel
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// lazySource holds the source of a map which is loaded lazily, the map is
+// decoded from it on first access.
+type lazySource struct {
+	document bson.M
+	raw      bson.Raw
+	err      error
+}
+
+func (s *lazySource) pending() bool {
+	return s.document != nil || s.raw != nil
+}
+
+func (s *lazySource) setDocument(document bson.M) {
+	s.document = document
+	s.raw = nil
+	s.err = nil
+}
+
+func (s *lazySource) setRaw(raw bson.Raw) {
+	s.document = nil
+	// the raw may be reused by the BSON decoder, so it must be copied
+	s.raw = append(bson.Raw(nil), raw...)
+	s.err = nil
+}
+
+func (s *lazySource) clear() {
+	s.document = nil
+	s.raw = nil
+	s.err = nil
+}
+
+func (s *lazySource) load(fillDocument func(document bson.M) error, fillRaw func(raw bson.Raw) error) {
+	document, raw := s.document, s.raw
+	s.document = nil
+	s.raw = nil
+	if document != nil {
+		s.err = fillDocument(document)
+	} else {
+		s.err = fillRaw(raw)
+	}
+}
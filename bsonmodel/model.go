@@ -1,7 +1,8 @@
 package bsonmodel
 
 import (
-	mapset "github.com/deckarep/golang-set"
+	"io"
+
 	jsoniter "github.com/json-iterator/go"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -11,6 +12,7 @@ type BsonModel interface {
 	ToBson() interface{}
 	ToData() interface{}
 	LoadJsoniter(any jsoniter.Any) error
+	LoadJsonIterator(iter *jsoniter.Iterator) error
 	Reset()
 	AnyUpdated() bool
 	AnyDeleted() bool
@@ -21,13 +23,25 @@ type BsonModel interface {
 	ToDelete() interface{}
 	ToDataJson() (string, error)
 	ToSyncJson() (string, error)
+	EncodeData(stream *jsoniter.Stream)
+	EncodeSync(stream *jsoniter.Stream)
+	WriteDataJson(w io.Writer) error
+	WriteSyncJson(w io.Writer) error
 	ToDeleteJson() (string, error)
+	ToMergePatch() interface{}
+	ToJsonPatch() []JsonPatchOperation
+	Changes() []Change
+	GetField(name string) (interface{}, error)
+	SetField(name string, value interface{}) error
+	GetPath(xpath DotNotation) (interface{}, error)
+	SetPath(xpath DotNotation, value interface{}) error
 }
 
 type DocumentModel interface {
 	BsonModel
 	ToDocument() bson.M
 	LoadDocument(document bson.M) error
+	LoadRaw(raw bson.Raw) error
 	DeletedSize() int
 }
 
@@ -35,53 +49,107 @@ type ObjectModel interface {
 	DocumentModel
 	FullyUpdate() bool
 	SetFullyUpdate(fullyUpdate bool)
+	Snapshot() (restore func())
+	LoadDocumentTracked(document bson.M) error
+	Schema() *ModelSchema
 }
 
 type RootModel interface {
 	ObjectModel
 	ToUpdate() bson.M
+	ToOrderedDocument() bson.D
+	ToOrderedUpdate() bson.D
+	LoadPartialDocument(document bson.M, projection bson.M) error
+	FieldLoaded(bname string) bool
 	MarshalToJsonString() (string, error)
+	UnmarshalDataJson(data []byte) error
+	MarshalBSON() ([]byte, error)
+	UnmarshalBSON(data []byte) error
+	Observers() *ChangeObservers
+	Transaction() *Transaction
+	SetSyncReplaceMarker(enabled bool)
+	SyncReplaceMarker() bool
+	Begin() error
+	Commit() error
+	Rollback() error
+	Release()
 }
 
 type MapValueModel interface {
 	ObjectModel
 	setParent(parent BsonModel)
 	unbind()
+	release()
+	isReleased() bool
 	EmitUpdated()
 }
 
 type baseMapValue struct {
-	parent BsonModel
+	parent   BsonModel
+	xpath    PathCache
+	released bool
 }
 
 func (v *baseMapValue) setParent(parent BsonModel) {
 	v.parent = parent
+	v.xpath.Invalidate()
+}
+
+func (v *baseMapValue) release() {
+	v.released = true
+}
+
+func (v *baseMapValue) isReleased() bool {
+	return v.released
 }
 
 type mapModel interface {
 	DocumentModel
 	Size() int
 	Clear()
-	emitUpdated(key interface{})
-}
-
-type baseMap struct {
-	parent      BsonModel
-	name        string
-	updatedKeys mapset.Set
-	removedKeys mapset.Set
+	// Discard drops all entries, including the source which has not been
+	// decoded yet, without tracking any change. It is called when the root
+	// model is released, the map and its values can not be used any more in
+	// debug builds.
+	Discard()
+	LazyModel
 }
 
-func (smap *baseMap) emitUpdated(key interface{}) {
-	smap.updatedKeys.Add(key)
+// LazyModel is the model which can keep its source undecoded until it is
+// first accessed.
+//
+// Loading lazily never fails, any error occurs in decoding is reported by
+// Load(). Changes tracking and update generation do not decode the source.
+//
+// Root models load their `lazy: true` maps lazily in LoadDocument() and
+// LoadRaw(), callers should check Load() of the lazy maps if the decoding
+// errors matter before the maps are accessed.
+type LazyModel interface {
+	// LoadDocumentLazily replaces all data with the document, which is
+	// decoded on first access.
+	LoadDocumentLazily(document bson.M)
+	// LoadRawLazily replaces all data with the raw document, which is
+	// decoded on first access.
+	LoadRawLazily(raw bson.Raw)
+	// Loaded returns whether the source has been decoded.
+	Loaded() bool
+	// Load decodes the source if it has not been decoded, and returns the
+	// error occurs in decoding.
+	Load() error
 }
 
-func (smap *baseMap) AnyUpdated() bool {
-	return smap.updatedKeys.Cardinality() > 0 || smap.AnyDeleted()
+type baseMap struct {
+	parent   BsonModel
+	name     string
+	xpath    PathCache
+	lazy     lazySource
+	released bool
 }
 
-func (smap *baseMap) AnyDeleted() bool {
-	return smap.DeletedSize() > 0
+// checkReleased panics if the map has been discarded with the root model it
+// belonged to, in debug builds.
+func (smap *baseMap) checkReleased() {
+	CheckReleased(smap.released, smap.name)
 }
 
 func (smap *baseMap) Parent() BsonModel {
@@ -89,9 +157,41 @@ func (smap *baseMap) Parent() BsonModel {
 }
 
 func (smap *baseMap) XPath() DotNotation {
-	return smap.parent.XPath().Resolve(smap.name)
+	return smap.xpath.Resolve(smap.parent.XPath(), smap.name)
+}
+
+type intMapKeys struct {
+	updatedKeys intKeySet
+	removedKeys intKeySet
+	keyPaths    intKeyPaths
+}
+
+func (keys *intMapKeys) AnyUpdated() bool {
+	return keys.updatedKeys.Len() > 0 || keys.AnyDeleted()
+}
+
+func (keys *intMapKeys) AnyDeleted() bool {
+	return keys.DeletedSize() > 0
+}
+
+func (keys *intMapKeys) DeletedSize() int {
+	return keys.removedKeys.Len()
+}
+
+type stringMapKeys struct {
+	updatedKeys stringKeySet
+	removedKeys stringKeySet
+	keyPaths    stringKeyPaths
+}
+
+func (keys *stringMapKeys) AnyUpdated() bool {
+	return keys.updatedKeys.Len() > 0 || keys.AnyDeleted()
+}
+
+func (keys *stringMapKeys) AnyDeleted() bool {
+	return keys.DeletedSize() > 0
 }
 
-func (smap *baseMap) DeletedSize() int {
-	return smap.removedKeys.Cardinality()
+func (keys *stringMapKeys) DeletedSize() int {
+	return keys.removedKeys.Len()
 }
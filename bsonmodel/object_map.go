@@ -2,12 +2,13 @@ package bsonmodel
 
 import (
 	"encoding/json"
+	"io"
 	"strconv"
 	"unsafe"
 
-	mapset "github.com/deckarep/golang-set"
 	jsoniter "github.com/json-iterator/go"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 )
 
 type IntObjectMapValueModel interface {
@@ -26,20 +27,23 @@ func (v *BaseIntObjectMapValue) Parent() BsonModel {
 }
 
 func (v *BaseIntObjectMapValue) XPath() DotNotation {
-	return v.parent.XPath().Resolve(strconv.Itoa(v.key))
+	return v.xpath.ResolveIndex(v.parent.XPath(), v.key)
 }
 
 func (v *BaseIntObjectMapValue) setParent(parent BsonModel) {
 	v.parent = parent
+	v.xpath.Invalidate()
 }
 
 func (v *BaseIntObjectMapValue) unbind() {
 	v.parent = nil
 	v.key = 0
+	v.xpath.Invalidate()
 }
 
 func (v *BaseIntObjectMapValue) setKey(key int) {
 	v.key = key
+	v.xpath.Invalidate()
 }
 
 func (v *BaseIntObjectMapValue) Key() int {
@@ -48,7 +52,7 @@ func (v *BaseIntObjectMapValue) Key() int {
 
 func (v *BaseIntObjectMapValue) EmitUpdated() {
 	if v.parent != nil {
-		v.parent.(mapModel).emitUpdated(v.key)
+		v.parent.(*intObjectMap).emitUpdated(v.key)
 	}
 }
 
@@ -58,40 +62,101 @@ type IntObjectMapModel interface {
 	Get(key int) IntObjectMapValueModel
 	Put(key int, value IntObjectMapValueModel) IntObjectMapValueModel
 	Remove(key int) bool
-	SetUpdated(key string)
+	SetUpdated(key int)
 }
 
 type IntObjectMapValueFactory func() IntObjectMapValueModel
 
 type intObjectMap struct {
 	baseMap
+	intMapKeys
 	valueFactory IntObjectMapValueFactory
 	data         map[int]IntObjectMapValueModel
 }
 
 func (imap *intObjectMap) MarshalJSON() ([]byte, error) {
+	imap.load()
 	return json.Marshal(imap.data)
 }
 
 func (imap *intObjectMap) Size() int {
+	imap.load()
 	return len(imap.data)
 }
 
+func (imap *intObjectMap) emitUpdated(key int) {
+	imap.saveKey(key)
+	imap.updatedKeys.Add(key)
+}
+
+func (imap *intObjectMap) saveKey(key int) {
+	tx := TransactionOf(imap)
+	if tx == nil {
+		return
+	}
+	tx.save(mapEntry{imap, key}, func() func() {
+		value, existed := imap.data[key]
+		updated := imap.updatedKeys.Contains(key)
+		removed := imap.removedKeys.Contains(key)
+		return func() {
+			if existed {
+				imap.data[key] = value
+				value.setKey(key)
+				value.setParent(imap)
+			} else {
+				delete(imap.data, key)
+			}
+			imap.updatedKeys.restore(key, updated)
+			imap.removedKeys.restore(key, removed)
+		}
+	})
+}
+
+func (imap *intObjectMap) saveAll() {
+	tx := TransactionOf(imap)
+	if tx == nil {
+		return
+	}
+	tx.save(imap, func() func() {
+		data := make(map[int]IntObjectMapValueModel, len(imap.data))
+		for k, v := range imap.data {
+			data[k] = v
+		}
+		updatedKeys := imap.updatedKeys.Clone()
+		removedKeys := imap.removedKeys.Clone()
+		lazy := imap.lazy
+		return func() {
+			for k, v := range data {
+				v.setKey(k)
+				v.setParent(imap)
+			}
+			imap.data = data
+			imap.updatedKeys = updatedKeys
+			imap.removedKeys = removedKeys
+			imap.lazy = lazy
+		}
+	})
+}
+
 func (imap *intObjectMap) Clear() {
+	imap.load()
+	imap.saveAll()
 	imap.updatedKeys.Clear()
 	removedKeys := imap.removedKeys
 	data := imap.data
 	for k := range data {
 		removedKeys.Add(k)
 	}
-	for k := range data {
+	for k, v := range data {
 		delete(data, k)
+		emitIntKeyChange(imap, k, v, nil)
 	}
 }
 
 func (imap *intObjectMap) Keys() []int {
+	imap.load()
 	data := imap.data
-	keys := make([]int, len(data))
+	keys := make([]int, 0, len(data))
 	for k := range data {
 		keys = append(keys, k)
 	}
@@ -99,6 +164,7 @@ func (imap *intObjectMap) Keys() []int {
 }
 
 func (imap *intObjectMap) Get(key int) IntObjectMapValueModel {
+	imap.load()
 	value, ok := imap.data[key]
 	if ok {
 		return value
@@ -107,6 +173,8 @@ func (imap *intObjectMap) Get(key int) IntObjectMapValueModel {
 }
 
 func (imap *intObjectMap) Put(key int, value IntObjectMapValueModel) IntObjectMapValueModel {
+	imap.load()
+	imap.saveKey(key)
 	data := imap.data
 	old, ok := data[key]
 	if ok {
@@ -117,6 +185,7 @@ func (imap *intObjectMap) Put(key int, value IntObjectMapValueModel) IntObjectMa
 			value.setParent(imap)
 			value.SetFullyUpdate(true)
 			old.unbind()
+			emitIntKeyChange(imap, key, old, value)
 		}
 		return old
 	}
@@ -126,10 +195,13 @@ func (imap *intObjectMap) Put(key int, value IntObjectMapValueModel) IntObjectMa
 	value.setKey(key)
 	value.setParent(imap)
 	value.SetFullyUpdate(true)
+	emitIntKeyChange(imap, key, nil, value)
 	return nil
 }
 
 func (imap *intObjectMap) Remove(key int) bool {
+	imap.load()
+	imap.saveKey(key)
 	data := imap.data
 	old, ok := data[key]
 	if ok {
@@ -137,12 +209,14 @@ func (imap *intObjectMap) Remove(key int) bool {
 		imap.updatedKeys.Remove(key)
 		imap.removedKeys.Add(key)
 		old.unbind()
+		emitIntKeyChange(imap, key, old, nil)
 		return true
 	}
 	return false
 }
 
-func (imap *intObjectMap) SetUpdated(key string) {
+func (imap *intObjectMap) SetUpdated(key int) {
+	imap.load()
 	imap.updatedKeys.Add(key)
 }
 
@@ -151,6 +225,7 @@ func (imap *intObjectMap) ToBson() interface{} {
 }
 
 func (imap *intObjectMap) ToData() interface{} {
+	imap.load()
 	data := make(map[int]interface{})
 	for key, value := range imap.data {
 		data[key] = value.ToData()
@@ -159,16 +234,22 @@ func (imap *intObjectMap) ToData() interface{} {
 }
 
 func (imap *intObjectMap) Reset() {
+	imap.saveAll()
 	data := imap.data
-	for _, k := range imap.updatedKeys.ToSlice() {
-		data[k.(int)].Reset()
+	for k := range imap.updatedKeys {
+		data[k].Reset()
 	}
 	imap.updatedKeys.Clear()
+	for key := range imap.removedKeys {
+		imap.keyPaths.remove(key)
+	}
 	imap.removedKeys.Clear()
 }
 
 func (imap *intObjectMap) LoadJsoniter(any jsoniter.Any) error {
+	imap.checkReleased()
 	imap.Reset()
+	imap.lazy.clear()
 	data := imap.data
 	for k, v := range data {
 		v.unbind()
@@ -196,13 +277,51 @@ func (imap *intObjectMap) LoadJsoniter(any jsoniter.Any) error {
 	return nil
 }
 
+func (imap *intObjectMap) LoadJsonIterator(iter *jsoniter.Iterator) error {
+	imap.checkReleased()
+	imap.Reset()
+	imap.lazy.clear()
+	data := imap.data
+	for k, v := range data {
+		v.unbind()
+		delete(data, k)
+	}
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		return IterError(iter)
+	}
+	valueFactory := imap.valueFactory
+	var err error
+	iter.ReadMapCB(func(iter *jsoniter.Iterator, key string) bool {
+		k, e := strconv.Atoi(key)
+		if e != nil {
+			// skip key that not be an int
+			iter.Skip()
+			return true
+		}
+		value := valueFactory()
+		e = value.LoadJsonIterator(iter)
+		if e != nil {
+			err = e
+			return false
+		}
+		data[k] = value
+		value.setParent(imap)
+		value.setKey(k)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return IterError(iter)
+}
+
 func (imap *intObjectMap) AppendUpdates(updates bson.M) bson.M {
 	data := imap.data
 	updatedKeys := imap.updatedKeys
-	if updatedKeys.Cardinality() > 0 {
+	if updatedKeys.Len() > 0 {
 		dset := FixedEmbedded(updates, "$set")
-		for _, uk := range updatedKeys.ToSlice() {
-			key := uk.(int)
+		for key := range updatedKeys {
 			value := data[key]
 			if value.FullyUpdate() {
 				dset[value.XPath().Value()] = value.ToBson()
@@ -212,19 +331,18 @@ func (imap *intObjectMap) AppendUpdates(updates bson.M) bson.M {
 		}
 	}
 	removedKeys := imap.removedKeys
-	if removedKeys.Cardinality() > 0 {
+	if removedKeys.Len() > 0 {
 		unset := FixedEmbedded(updates, "$unset")
-		for _, uk := range removedKeys.ToSlice() {
-			key := uk.(int)
-			k := strconv.Itoa(key)
-			name := imap.XPath().Resolve(k)
-			unset[name.Value()] = ""
+		xpath := imap.XPath()
+		for key := range removedKeys {
+			unset[imap.keyPaths.get(xpath, key)] = ""
 		}
 	}
 	return updates
 }
 
 func (imap *intObjectMap) ToDocument() bson.M {
+	imap.load()
 	doc := bson.M{}
 	for k, v := range imap.data {
 		key := strconv.Itoa(k)
@@ -235,7 +353,13 @@ func (imap *intObjectMap) ToDocument() bson.M {
 }
 
 func (imap *intObjectMap) LoadDocument(document bson.M) error {
+	imap.checkReleased()
 	imap.Reset()
+	imap.lazy.clear()
+	return imap.fillDocument(document)
+}
+
+func (imap *intObjectMap) fillDocument(document bson.M) error {
 	data := imap.data
 	for k := range data {
 		delete(data, k)
@@ -264,13 +388,47 @@ func (imap *intObjectMap) LoadDocument(document bson.M) error {
 	return nil
 }
 
+func (imap *intObjectMap) LoadRaw(raw bson.Raw) error {
+	imap.checkReleased()
+	imap.Reset()
+	imap.lazy.clear()
+	return imap.fillRaw(raw)
+}
+
+func (imap *intObjectMap) fillRaw(raw bson.Raw) error {
+	data := imap.data
+	for k := range data {
+		delete(data, k)
+	}
+	valueFactory := imap.valueFactory
+	return rangeRaw(raw, func(key string, value bson.RawValue) error {
+		k, err := strconv.Atoi(key)
+		if err != nil {
+			// skip key that not be an int
+			return nil
+		}
+		if value.Type != bsontype.EmbeddedDocument {
+			// skip value that not be an embedded document
+			return nil
+		}
+		v := valueFactory()
+		err = v.LoadRaw(value.Document())
+		if err != nil {
+			return err
+		}
+		data[k] = v
+		v.setParent(imap)
+		v.setKey(k)
+		return nil
+	})
+}
+
 func (imap *intObjectMap) ToSync() interface{} {
 	sync := make(map[int]interface{})
 	data := imap.data
 	updatedKeys := imap.updatedKeys
-	if updatedKeys.Cardinality() > 0 {
-		for _, uk := range updatedKeys.ToSlice() {
-			key := uk.(int)
+	if updatedKeys.Len() > 0 {
+		for key := range updatedKeys {
 			value := data[key]
 			sync[key] = value.ToSync()
 		}
@@ -281,9 +439,8 @@ func (imap *intObjectMap) ToSync() interface{} {
 func (imap *intObjectMap) ToDelete() interface{} {
 	delete := make(map[int]int)
 	removedKeys := imap.removedKeys
-	if removedKeys.Cardinality() > 0 {
-		for _, uk := range removedKeys.ToSlice() {
-			key := uk.(int)
+	if removedKeys.Len() > 0 {
+		for key := range removedKeys {
 			delete[key] = 1
 		}
 	}
@@ -291,23 +448,161 @@ func (imap *intObjectMap) ToDelete() interface{} {
 }
 
 func (imap *intObjectMap) ToDataJson() (string, error) {
-	return jsoniter.MarshalToString(imap.ToData())
+	return DataJsonOf(imap)
 }
 
 func (imap *intObjectMap) ToSyncJson() (string, error) {
-	return jsoniter.MarshalToString(imap.ToSync())
+	return SyncJsonOf(imap)
+}
+
+func (imap *intObjectMap) EncodeData(stream *jsoniter.Stream) {
+	imap.load()
+	object := BeginJsonObject(stream)
+	for key, value := range imap.data {
+		object.Field(strconv.Itoa(key))
+		value.EncodeData(stream)
+	}
+	object.End()
+}
+
+func (imap *intObjectMap) EncodeSync(stream *jsoniter.Stream) {
+	object := BeginJsonObject(stream)
+	data := imap.data
+	for key := range imap.updatedKeys {
+		object.Field(strconv.Itoa(key))
+		data[key].EncodeSync(stream)
+	}
+	object.End()
+}
+
+func (imap *intObjectMap) WriteDataJson(w io.Writer) error {
+	return WriteDataJson(w, imap)
+}
+
+func (imap *intObjectMap) WriteSyncJson(w io.Writer) error {
+	return WriteSyncJson(w, imap)
 }
 
 func (imap *intObjectMap) ToDeleteJson() (string, error) {
 	return jsoniter.MarshalToString(imap.ToDelete())
 }
 
+func (imap *intObjectMap) ToMergePatch() interface{} {
+	return MergePatchOf(imap)
+}
+
+func (imap *intObjectMap) ToJsonPatch() []JsonPatchOperation {
+	return JsonPatchOf(imap)
+}
+
+func (imap *intObjectMap) Changes() []Change {
+	return ChangesOf(imap)
+}
+
+func (imap *intObjectMap) GetField(name string) (interface{}, error) {
+	imap.load()
+	key, err := strconv.Atoi(name)
+	if err != nil {
+		return nil, NoSuchFieldError(name)
+	}
+	value, ok := imap.data[key]
+	if !ok {
+		return nil, NoSuchFieldError(name)
+	}
+	return value, nil
+}
+
+func (imap *intObjectMap) SetField(name string, value interface{}) error {
+	key, err := strconv.Atoi(name)
+	if err != nil {
+		return NoSuchFieldError(name)
+	}
+	if value == nil {
+		imap.Remove(key)
+		return nil
+	}
+	document, err := ParseEmbedded(value)
+	if err != nil {
+		return err
+	}
+	v := imap.valueFactory()
+	err = v.LoadDocument(document)
+	if err != nil {
+		return err
+	}
+	imap.Put(key, v)
+	return nil
+}
+
+func (imap *intObjectMap) LoadDocumentLazily(document bson.M) {
+	imap.checkReleased()
+	imap.Reset()
+	data := imap.data
+	for k, v := range data {
+		v.unbind()
+		delete(data, k)
+	}
+	imap.lazy.setDocument(document)
+}
+
+func (imap *intObjectMap) LoadRawLazily(raw bson.Raw) {
+	imap.checkReleased()
+	imap.Reset()
+	data := imap.data
+	for k, v := range data {
+		v.unbind()
+		delete(data, k)
+	}
+	imap.lazy.setRaw(raw)
+}
+
+func (imap *intObjectMap) Loaded() bool {
+	return !imap.lazy.pending()
+}
+
+func (imap *intObjectMap) Load() error {
+	imap.load()
+	return imap.lazy.err
+}
+
+func (imap *intObjectMap) load() {
+	imap.checkReleased()
+	if imap.lazy.pending() {
+		imap.lazy.load(imap.fillDocument, imap.fillRaw)
+	}
+}
+
+func (imap *intObjectMap) Discard() {
+	imap.Reset()
+	imap.lazy.clear()
+	imap.keyPaths.clear()
+	data := imap.data
+	for k, v := range data {
+		if Debug {
+			v.release()
+		}
+		v.unbind()
+		delete(data, k)
+	}
+	if Debug {
+		imap.released = true
+	}
+}
+
+func (imap *intObjectMap) GetPath(xpath DotNotation) (interface{}, error) {
+	return GetPath(imap, xpath)
+}
+
+func (imap *intObjectMap) SetPath(xpath DotNotation, value interface{}) error {
+	return SetPath(imap, xpath, value)
+}
+
 func NewIntObjectMapModel(parent BsonModel, name string, valueFactory IntObjectMapValueFactory) IntObjectMapModel {
 	mapModel := &intObjectMap{}
 	mapModel.parent = parent
 	mapModel.name = name
-	mapModel.updatedKeys = mapset.NewThreadUnsafeSet()
-	mapModel.removedKeys = mapset.NewThreadUnsafeSet()
+	mapModel.updatedKeys = make(intKeySet)
+	mapModel.removedKeys = make(intKeySet)
 	mapModel.valueFactory = valueFactory
 	mapModel.data = make(map[int]IntObjectMapValueModel)
 	return mapModel
@@ -329,20 +624,23 @@ func (v *BaseStringObjectMapValue) Parent() BsonModel {
 }
 
 func (v *BaseStringObjectMapValue) XPath() DotNotation {
-	return v.parent.XPath().Resolve(v.key)
+	return v.xpath.Resolve(v.parent.XPath(), v.key)
 }
 
 func (v *BaseStringObjectMapValue) setParent(parent BsonModel) {
 	v.parent = parent
+	v.xpath.Invalidate()
 }
 
 func (v *BaseStringObjectMapValue) unbind() {
 	v.parent = nil
 	v.key = ""
+	v.xpath.Invalidate()
 }
 
 func (v *BaseStringObjectMapValue) setKey(key string) {
 	v.key = key
+	v.xpath.Invalidate()
 }
 
 func (v *BaseStringObjectMapValue) Key() string {
@@ -351,7 +649,7 @@ func (v *BaseStringObjectMapValue) Key() string {
 
 func (v *BaseStringObjectMapValue) EmitUpdated() {
 	if v.parent != nil {
-		v.parent.(mapModel).emitUpdated(v.key)
+		v.parent.(*stringObjectMap).emitUpdated(v.key)
 	}
 }
 
@@ -368,33 +666,94 @@ type StringObjectMapValueFactory func() StringObjectMapValueModel
 
 type stringObjectMap struct {
 	baseMap
+	stringMapKeys
 	valueFactory StringObjectMapValueFactory
 	data         map[string]StringObjectMapValueModel
 }
 
 func (smap *stringObjectMap) MarshalJSON() ([]byte, error) {
+	smap.load()
 	return json.Marshal(smap.data)
 }
 
 func (smap *stringObjectMap) Size() int {
+	smap.load()
 	return len(smap.data)
 }
 
+func (smap *stringObjectMap) emitUpdated(key string) {
+	smap.saveKey(key)
+	smap.updatedKeys.Add(key)
+}
+
+func (smap *stringObjectMap) saveKey(key string) {
+	tx := TransactionOf(smap)
+	if tx == nil {
+		return
+	}
+	tx.save(mapEntry{smap, key}, func() func() {
+		value, existed := smap.data[key]
+		updated := smap.updatedKeys.Contains(key)
+		removed := smap.removedKeys.Contains(key)
+		return func() {
+			if existed {
+				smap.data[key] = value
+				value.setKey(key)
+				value.setParent(smap)
+			} else {
+				delete(smap.data, key)
+			}
+			smap.updatedKeys.restore(key, updated)
+			smap.removedKeys.restore(key, removed)
+		}
+	})
+}
+
+func (smap *stringObjectMap) saveAll() {
+	tx := TransactionOf(smap)
+	if tx == nil {
+		return
+	}
+	tx.save(smap, func() func() {
+		data := make(map[string]StringObjectMapValueModel, len(smap.data))
+		for k, v := range smap.data {
+			data[k] = v
+		}
+		updatedKeys := smap.updatedKeys.Clone()
+		removedKeys := smap.removedKeys.Clone()
+		lazy := smap.lazy
+		return func() {
+			for k, v := range data {
+				v.setKey(k)
+				v.setParent(smap)
+			}
+			smap.data = data
+			smap.updatedKeys = updatedKeys
+			smap.removedKeys = removedKeys
+			smap.lazy = lazy
+		}
+	})
+}
+
 func (smap *stringObjectMap) Clear() {
+	smap.load()
+	smap.saveAll()
 	smap.updatedKeys.Clear()
 	removedKeys := smap.removedKeys
 	data := smap.data
 	for k := range data {
 		removedKeys.Add(k)
 	}
-	for k := range data {
+	for k, v := range data {
 		delete(data, k)
+		EmitChange(smap, k, v, nil)
 	}
 }
 
 func (smap *stringObjectMap) Keys() []string {
+	smap.load()
 	data := smap.data
-	keys := make([]string, len(data))
+	keys := make([]string, 0, len(data))
 	for k := range data {
 		keys = append(keys, k)
 	}
@@ -402,6 +761,7 @@ func (smap *stringObjectMap) Keys() []string {
 }
 
 func (smap *stringObjectMap) Get(key string) StringObjectMapValueModel {
+	smap.load()
 	value, ok := smap.data[key]
 	if ok {
 		return value
@@ -410,6 +770,8 @@ func (smap *stringObjectMap) Get(key string) StringObjectMapValueModel {
 }
 
 func (smap *stringObjectMap) Put(key string, value StringObjectMapValueModel) StringObjectMapValueModel {
+	smap.load()
+	smap.saveKey(key)
 	data := smap.data
 	old, ok := data[key]
 	if ok {
@@ -420,6 +782,7 @@ func (smap *stringObjectMap) Put(key string, value StringObjectMapValueModel) St
 			value.setParent(smap)
 			value.SetFullyUpdate(true)
 			old.unbind()
+			EmitChange(smap, key, old, value)
 		}
 		return old
 	}
@@ -429,10 +792,13 @@ func (smap *stringObjectMap) Put(key string, value StringObjectMapValueModel) St
 	value.setKey(key)
 	value.setParent(smap)
 	value.SetFullyUpdate(true)
+	EmitChange(smap, key, nil, value)
 	return nil
 }
 
 func (smap *stringObjectMap) Remove(key string) bool {
+	smap.load()
+	smap.saveKey(key)
 	data := smap.data
 	old, ok := data[key]
 	if ok {
@@ -440,12 +806,14 @@ func (smap *stringObjectMap) Remove(key string) bool {
 		smap.updatedKeys.Remove(key)
 		smap.removedKeys.Add(key)
 		old.unbind()
+		EmitChange(smap, key, old, nil)
 		return true
 	}
 	return false
 }
 
 func (smap *stringObjectMap) SetUpdated(key string) {
+	smap.load()
 	smap.updatedKeys.Add(key)
 }
 
@@ -454,6 +822,7 @@ func (smap *stringObjectMap) ToBson() interface{} {
 }
 
 func (smap *stringObjectMap) ToData() interface{} {
+	smap.load()
 	data := make(map[string]interface{})
 	for key, value := range smap.data {
 		data[key] = value.ToData()
@@ -462,16 +831,22 @@ func (smap *stringObjectMap) ToData() interface{} {
 }
 
 func (smap *stringObjectMap) Reset() {
+	smap.saveAll()
 	data := smap.data
-	for _, k := range smap.updatedKeys.ToSlice() {
-		data[k.(string)].Reset()
+	for k := range smap.updatedKeys {
+		data[k].Reset()
 	}
 	smap.updatedKeys.Clear()
+	for key := range smap.removedKeys {
+		smap.keyPaths.remove(key)
+	}
 	smap.removedKeys.Clear()
 }
 
 func (smap *stringObjectMap) LoadJsoniter(any jsoniter.Any) error {
+	smap.checkReleased()
 	smap.Reset()
+	smap.lazy.clear()
 	data := smap.data
 	for k, v := range data {
 		v.unbind()
@@ -494,13 +869,45 @@ func (smap *stringObjectMap) LoadJsoniter(any jsoniter.Any) error {
 	return nil
 }
 
+func (smap *stringObjectMap) LoadJsonIterator(iter *jsoniter.Iterator) error {
+	smap.checkReleased()
+	smap.Reset()
+	smap.lazy.clear()
+	data := smap.data
+	for k, v := range data {
+		v.unbind()
+		delete(data, k)
+	}
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		return IterError(iter)
+	}
+	valueFactory := smap.valueFactory
+	var err error
+	iter.ReadMapCB(func(iter *jsoniter.Iterator, key string) bool {
+		value := valueFactory()
+		e := value.LoadJsonIterator(iter)
+		if e != nil {
+			err = e
+			return false
+		}
+		data[key] = value
+		value.setParent(smap)
+		value.setKey(key)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return IterError(iter)
+}
+
 func (smap *stringObjectMap) AppendUpdates(updates bson.M) bson.M {
 	data := smap.data
 	updatedKeys := smap.updatedKeys
-	if updatedKeys.Cardinality() > 0 {
+	if updatedKeys.Len() > 0 {
 		dset := FixedEmbedded(updates, "$set")
-		for _, uk := range updatedKeys.ToSlice() {
-			key := uk.(string)
+		for key := range updatedKeys {
 			value := data[key]
 			if value.FullyUpdate() {
 				dset[value.XPath().Value()] = value.ToBson()
@@ -510,18 +917,18 @@ func (smap *stringObjectMap) AppendUpdates(updates bson.M) bson.M {
 		}
 	}
 	removedKeys := smap.removedKeys
-	if removedKeys.Cardinality() > 0 {
+	if removedKeys.Len() > 0 {
 		unset := FixedEmbedded(updates, "$unset")
-		for _, uk := range removedKeys.ToSlice() {
-			key := uk.(string)
-			name := smap.XPath().Resolve(key)
-			unset[name.Value()] = ""
+		xpath := smap.XPath()
+		for key := range removedKeys {
+			unset[smap.keyPaths.get(xpath, key)] = ""
 		}
 	}
 	return updates
 }
 
 func (smap *stringObjectMap) ToDocument() bson.M {
+	smap.load()
 	doc := bson.M{}
 	for k, v := range smap.data {
 		value := v.ToBson()
@@ -531,7 +938,13 @@ func (smap *stringObjectMap) ToDocument() bson.M {
 }
 
 func (smap *stringObjectMap) LoadDocument(document bson.M) error {
+	smap.checkReleased()
 	smap.Reset()
+	smap.lazy.clear()
+	return smap.fillDocument(document)
+}
+
+func (smap *stringObjectMap) fillDocument(document bson.M) error {
 	data := smap.data
 	for k := range data {
 		delete(data, k)
@@ -555,13 +968,42 @@ func (smap *stringObjectMap) LoadDocument(document bson.M) error {
 	return nil
 }
 
+func (smap *stringObjectMap) LoadRaw(raw bson.Raw) error {
+	smap.checkReleased()
+	smap.Reset()
+	smap.lazy.clear()
+	return smap.fillRaw(raw)
+}
+
+func (smap *stringObjectMap) fillRaw(raw bson.Raw) error {
+	data := smap.data
+	for k := range data {
+		delete(data, k)
+	}
+	valueFactory := smap.valueFactory
+	return rangeRaw(raw, func(key string, value bson.RawValue) error {
+		if value.Type != bsontype.EmbeddedDocument {
+			// skip value that not be an embedded document
+			return nil
+		}
+		v := valueFactory()
+		err := v.LoadRaw(value.Document())
+		if err != nil {
+			return err
+		}
+		data[key] = v
+		v.setParent(smap)
+		v.setKey(key)
+		return nil
+	})
+}
+
 func (smap *stringObjectMap) ToSync() interface{} {
 	sync := make(map[string]interface{})
 	data := smap.data
 	updatedKeys := smap.updatedKeys
-	if updatedKeys.Cardinality() > 0 {
-		for _, uk := range updatedKeys.ToSlice() {
-			key := uk.(string)
+	if updatedKeys.Len() > 0 {
+		for key := range updatedKeys {
 			value := data[key]
 			sync[key] = value.ToSync()
 		}
@@ -572,9 +1014,8 @@ func (smap *stringObjectMap) ToSync() interface{} {
 func (smap *stringObjectMap) ToDelete() interface{} {
 	delete := make(map[string]int)
 	removedKeys := smap.removedKeys
-	if removedKeys.Cardinality() > 0 {
-		for _, uk := range removedKeys.ToSlice() {
-			key := uk.(string)
+	if removedKeys.Len() > 0 {
+		for key := range removedKeys {
 			delete[key] = 1
 		}
 	}
@@ -582,23 +1023,155 @@ func (smap *stringObjectMap) ToDelete() interface{} {
 }
 
 func (smap *stringObjectMap) ToDataJson() (string, error) {
-	return jsoniter.MarshalToString(smap.ToData())
+	return DataJsonOf(smap)
 }
 
 func (smap *stringObjectMap) ToSyncJson() (string, error) {
-	return jsoniter.MarshalToString(smap.ToSync())
+	return SyncJsonOf(smap)
+}
+
+func (smap *stringObjectMap) EncodeData(stream *jsoniter.Stream) {
+	smap.load()
+	object := BeginJsonObject(stream)
+	for key, value := range smap.data {
+		object.Field(key)
+		value.EncodeData(stream)
+	}
+	object.End()
+}
+
+func (smap *stringObjectMap) EncodeSync(stream *jsoniter.Stream) {
+	object := BeginJsonObject(stream)
+	data := smap.data
+	for key := range smap.updatedKeys {
+		object.Field(key)
+		data[key].EncodeSync(stream)
+	}
+	object.End()
+}
+
+func (smap *stringObjectMap) WriteDataJson(w io.Writer) error {
+	return WriteDataJson(w, smap)
+}
+
+func (smap *stringObjectMap) WriteSyncJson(w io.Writer) error {
+	return WriteSyncJson(w, smap)
 }
 
 func (smap *stringObjectMap) ToDeleteJson() (string, error) {
 	return jsoniter.MarshalToString(smap.ToDelete())
 }
 
+func (smap *stringObjectMap) ToMergePatch() interface{} {
+	return MergePatchOf(smap)
+}
+
+func (smap *stringObjectMap) ToJsonPatch() []JsonPatchOperation {
+	return JsonPatchOf(smap)
+}
+
+func (smap *stringObjectMap) Changes() []Change {
+	return ChangesOf(smap)
+}
+
+func (smap *stringObjectMap) GetField(name string) (interface{}, error) {
+	smap.load()
+	key := name
+	value, ok := smap.data[key]
+	if !ok {
+		return nil, NoSuchFieldError(name)
+	}
+	return value, nil
+}
+
+func (smap *stringObjectMap) SetField(name string, value interface{}) error {
+	key := name
+	if value == nil {
+		smap.Remove(key)
+		return nil
+	}
+	document, err := ParseEmbedded(value)
+	if err != nil {
+		return err
+	}
+	v := smap.valueFactory()
+	err = v.LoadDocument(document)
+	if err != nil {
+		return err
+	}
+	smap.Put(key, v)
+	return nil
+}
+
+func (smap *stringObjectMap) LoadDocumentLazily(document bson.M) {
+	smap.checkReleased()
+	smap.Reset()
+	data := smap.data
+	for k, v := range data {
+		v.unbind()
+		delete(data, k)
+	}
+	smap.lazy.setDocument(document)
+}
+
+func (smap *stringObjectMap) LoadRawLazily(raw bson.Raw) {
+	smap.checkReleased()
+	smap.Reset()
+	data := smap.data
+	for k, v := range data {
+		v.unbind()
+		delete(data, k)
+	}
+	smap.lazy.setRaw(raw)
+}
+
+func (smap *stringObjectMap) Loaded() bool {
+	return !smap.lazy.pending()
+}
+
+func (smap *stringObjectMap) Load() error {
+	smap.load()
+	return smap.lazy.err
+}
+
+func (smap *stringObjectMap) load() {
+	smap.checkReleased()
+	if smap.lazy.pending() {
+		smap.lazy.load(smap.fillDocument, smap.fillRaw)
+	}
+}
+
+func (smap *stringObjectMap) Discard() {
+	smap.Reset()
+	smap.lazy.clear()
+	smap.keyPaths.clear()
+	data := smap.data
+	for k, v := range data {
+		if Debug {
+			v.release()
+		}
+		v.unbind()
+		delete(data, k)
+	}
+	if Debug {
+		smap.released = true
+	}
+}
+
+func (smap *stringObjectMap) GetPath(xpath DotNotation) (interface{}, error) {
+	return GetPath(smap, xpath)
+}
+
+func (smap *stringObjectMap) SetPath(xpath DotNotation, value interface{}) error {
+	return SetPath(smap, xpath, value)
+}
+
 func NewStringObjectMapModel(parent BsonModel, name string, valueFactory StringObjectMapValueFactory) StringObjectMapModel {
 	mapModel := &stringObjectMap{}
 	mapModel.parent = parent
 	mapModel.name = name
-	mapModel.updatedKeys = mapset.NewThreadUnsafeSet()
-	mapModel.removedKeys = mapset.NewThreadUnsafeSet()
+	mapModel.updatedKeys = make(stringKeySet)
+	mapModel.removedKeys = make(stringKeySet)
 	mapModel.valueFactory = valueFactory
 	mapModel.data = make(map[string]StringObjectMapValueModel)
 	return mapModel
@@ -0,0 +1,183 @@
+package bsonmodel
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ChangeEvent is the event emitted when a field of a model or an entry of a
+// map has been changed.
+type ChangeEvent struct {
+	// Path is the BSON dot notation of the changed field or entry.
+	Path DotNotation
+	// OldValue is the value before changed, nil for a new entry.
+	OldValue interface{}
+	// NewValue is the value after changed, nil for a removed entry.
+	NewValue interface{}
+}
+
+// ChangeListener is the function receives change events.
+type ChangeListener func(event *ChangeEvent)
+
+type changeListenerEntry struct {
+	prefix   string
+	listener ChangeListener
+}
+
+func (entry *changeListenerEntry) accept(path string) bool {
+	prefix := entry.prefix
+	if prefix == "" || prefix == path {
+		return true
+	}
+	return strings.HasPrefix(path, prefix) && path[len(prefix)] == '.'
+}
+
+// ChangeObservers holds the change listeners of a root model, they are
+// removed when the root model is released.
+//
+// The zero value is ready to use.
+type ChangeObservers struct {
+	listeners    []*changeListenerEntry
+	batchDepth   int
+	pending      []*ChangeEvent
+	pendingPaths map[string]*ChangeEvent
+}
+
+// AddListener registers the listener which receives all change events on
+// the path or its descendants, and returns the function to remove it.
+func (observers *ChangeObservers) AddListener(prefix DotNotation, listener ChangeListener) (remove func()) {
+	entry := &changeListenerEntry{prefix: prefix.Value(), listener: listener}
+	observers.listeners = append(observers.listeners, entry)
+	return func() {
+		listeners := observers.listeners
+		for i, e := range listeners {
+			if e == entry {
+				observers.listeners = append(listeners[:i:i], listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Reset removes all listeners and drops the held change events.
+func (observers *ChangeObservers) Reset() {
+	*observers = ChangeObservers{}
+}
+
+// HasListeners returns whether any listener is registered.
+func (observers *ChangeObservers) HasListeners() bool {
+	return len(observers.listeners) > 0
+}
+
+// BeginBatch holds all change events until the matching EndBatch() is
+// called. The batches can be nested.
+func (observers *ChangeObservers) BeginBatch() {
+	observers.batchDepth++
+}
+
+// EndBatch ends the batch and notifies the held change events when the
+// outermost batch ends. Events on the same path are merged into one which
+// holds the first old value and the last new value.
+func (observers *ChangeObservers) EndBatch() {
+	if observers.batchDepth == 0 {
+		return
+	}
+	observers.batchDepth--
+	if observers.batchDepth > 0 {
+		return
+	}
+	pending := observers.pending
+	observers.pending = nil
+	observers.pendingPaths = nil
+	for _, event := range pending {
+		observers.notify(event)
+	}
+}
+
+// Emit notifies the change event to the listeners, or holds it when in a
+// batch.
+func (observers *ChangeObservers) Emit(event *ChangeEvent) {
+	if observers.batchDepth > 0 {
+		path := event.Path.Value()
+		if e, ok := observers.pendingPaths[path]; ok {
+			e.NewValue = event.NewValue
+			return
+		}
+		if observers.pendingPaths == nil {
+			observers.pendingPaths = make(map[string]*ChangeEvent)
+		}
+		observers.pendingPaths[path] = event
+		observers.pending = append(observers.pending, event)
+		return
+	}
+	observers.notify(event)
+}
+
+func (observers *ChangeObservers) notify(event *ChangeEvent) {
+	path := event.Path.Value()
+	for _, entry := range observers.listeners {
+		if entry.accept(path) {
+			entry.listener(event)
+		}
+	}
+}
+
+// ObserversOf returns the change observers of the root model which the
+// model belongs to, or nil if the model is not attached to any root model.
+func ObserversOf(model BsonModel) *ChangeObservers {
+	for m := model; m != nil; m = m.Parent() {
+		if root, ok := m.(RootModel); ok {
+			return root.Observers()
+		}
+	}
+	return nil
+}
+
+// observing returns the change observers of the root model which the model
+// belongs to, or nil if nothing is observed on it, so that no change event
+// is built for the models without listeners.
+func observing(model BsonModel) *ChangeObservers {
+	observers := ObserversOf(model)
+	if observers == nil || !observers.HasListeners() {
+		return nil
+	}
+	return observers
+}
+
+// EmitChange emits the change event of the field or the entry with the
+// specified name on the model.
+func EmitChange(model BsonModel, name string, oldValue interface{}, newValue interface{}) {
+	if observers := observing(model); observers != nil {
+		observers.Emit(&ChangeEvent{Path: model.XPath().Resolve(name), OldValue: oldValue, NewValue: newValue})
+	}
+}
+
+// emitIntKeyChange emits the change event of the entry with the int key on
+// the map, the key is only converted to string when anything is observed.
+func emitIntKeyChange(model BsonModel, key int, oldValue interface{}, newValue interface{}) {
+	if observers := observing(model); observers != nil {
+		observers.Emit(&ChangeEvent{Path: model.XPath().Resolve(strconv.Itoa(key)), OldValue: oldValue, NewValue: newValue})
+	}
+}
+
+// Observe registers the listener which receives all change events on the
+// model, and returns the function to remove it.
+func Observe(model BsonModel, listener ChangeListener) (remove func(), err error) {
+	observers := ObserversOf(model)
+	if observers == nil {
+		return nil, errors.New("The model is not attached to any root model")
+	}
+	return observers.AddListener(model.XPath(), listener), nil
+}
+
+// ObserveField registers the listener which receives all change events on
+// the field, with the BSON name, of the model, and returns the function to
+// remove it.
+func ObserveField(model BsonModel, name string, listener ChangeListener) (remove func(), err error) {
+	observers := ObserversOf(model)
+	if observers == nil {
+		return nil, errors.New("The model is not attached to any root model")
+	}
+	return observers.AddListener(model.XPath().Resolve(name), listener), nil
+}
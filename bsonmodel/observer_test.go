@@ -0,0 +1,70 @@
+package bsonmodel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChangeObservers(t *testing.T) {
+	observers := &ChangeObservers{}
+	var paths []string
+	remove := observers.AddListener(PathOfNames("a"), func(event *ChangeEvent) {
+		paths = append(paths, event.Path.Value())
+	})
+	observers.Emit(&ChangeEvent{Path: PathOfNames("a")})
+	observers.Emit(&ChangeEvent{Path: PathOfNames("a", "b")})
+	observers.Emit(&ChangeEvent{Path: PathOfNames("ab")})
+	observers.Emit(&ChangeEvent{Path: PathOfNames("b", "a")})
+	expected := []string{"a", "a.b"}
+	if !reflect.DeepEqual(expected, paths) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, paths)
+	}
+	remove()
+	if observers.HasListeners() {
+		t.Error("The value expected false but was true")
+	}
+	observers.Emit(&ChangeEvent{Path: PathOfNames("a")})
+	if !reflect.DeepEqual(expected, paths) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, paths)
+	}
+}
+
+func TestChangeObserversBatch(t *testing.T) {
+	observers := &ChangeObservers{}
+	var events []ChangeEvent
+	observers.AddListener(RootPath(), func(event *ChangeEvent) {
+		events = append(events, *event)
+	})
+	observers.BeginBatch()
+	observers.Emit(&ChangeEvent{Path: PathOfNames("a"), OldValue: 1, NewValue: 2})
+	observers.BeginBatch()
+	observers.Emit(&ChangeEvent{Path: PathOfNames("b"), OldValue: nil, NewValue: "x"})
+	observers.Emit(&ChangeEvent{Path: PathOfNames("a"), OldValue: 2, NewValue: 3})
+	observers.EndBatch()
+	if len(events) != 0 {
+		t.Errorf("The value expected <%v> but was <%v>", 0, len(events))
+	}
+	observers.EndBatch()
+	if len(events) != 2 {
+		t.Fatalf("The value expected <%v> but was <%v>", 2, len(events))
+	}
+	if events[0].Path.Value() != "a" || events[0].OldValue != 1 || events[0].NewValue != 3 {
+		t.Errorf("Unexpected event <%v>", events[0])
+	}
+	if events[1].Path.Value() != "b" || events[1].OldValue != nil || events[1].NewValue != "x" {
+		t.Errorf("Unexpected event <%v>", events[1])
+	}
+}
+
+func TestChangeObserversReset(t *testing.T) {
+	observers := &ChangeObservers{}
+	observers.AddListener(RootPath(), func(event *ChangeEvent) {})
+	remove := observers.AddListener(PathOfNames("a"), func(event *ChangeEvent) {})
+	observers.BeginBatch()
+	observers.Emit(&ChangeEvent{Path: PathOfNames("a"), OldValue: 1, NewValue: 2})
+	observers.Reset()
+	remove()
+	if observers.HasListeners() || len(observers.pending) != 0 || observers.pendingPaths != nil {
+		t.Error("Expected observers reset but not")
+	}
+}
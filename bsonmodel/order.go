@@ -0,0 +1,173 @@
+package bsonmodel
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// OrderDocument returns the ordered form of the document of the model.
+//
+// Fields of objects are ordered in schema declaration order, entries of maps
+// are ordered by key, and any other keys are ordered by name after them.
+// Embedded documents are ordered recursively.
+func OrderDocument(model BsonModel, document bson.M) bson.D {
+	keys := make([]string, 0, len(document))
+	for key := range document {
+		keys = append(keys, key)
+	}
+	sortNames(model, keys)
+	ordered := make(bson.D, 0, len(keys))
+	for _, key := range keys {
+		ordered = append(ordered, bson.E{Key: key, Value: orderValue(childModel(model, key), document[key])})
+	}
+	return ordered
+}
+
+// OrderUpdates returns the ordered form of the update document of the model.
+//
+// The operators are ordered as `$set`, `$unset` and then the others by name,
+// and the paths in each operator are ordered in the same way as
+// OrderDocument().
+func OrderUpdates(model BsonModel, updates bson.M) bson.D {
+	operators := make([]string, 0, len(updates))
+	for operator := range updates {
+		operators = append(operators, operator)
+	}
+	sort.Slice(operators, func(i, j int) bool {
+		ri, rj := operatorRank(operators[i]), operatorRank(operators[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return operators[i] < operators[j]
+	})
+	ordered := make(bson.D, 0, len(operators))
+	for _, operator := range operators {
+		body, ok := updates[operator].(bson.M)
+		if !ok {
+			ordered = append(ordered, bson.E{Key: operator, Value: updates[operator]})
+			continue
+		}
+		ordered = append(ordered, bson.E{Key: operator, Value: orderUpdateBody(model, body)})
+	}
+	return ordered
+}
+
+func operatorRank(operator string) int {
+	switch operator {
+	case "$set":
+		return 0
+	case "$unset":
+		return 1
+	default:
+		return 2
+	}
+}
+
+func orderUpdateBody(model BsonModel, body bson.M) bson.D {
+	type entry struct {
+		names []string
+		ranks [][]int
+		key   string
+		model BsonModel
+	}
+	entries := make([]entry, 0, len(body))
+	for key := range body {
+		names := strings.Split(key, ".")
+		ranks := make([][]int, len(names))
+		m := model
+		for i, name := range names {
+			ranks[i] = nameRank(m, name)
+			m = childModel(m, name)
+		}
+		entries = append(entries, entry{names, ranks, key, m})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		for k := 0; k < len(a.names) && k < len(b.names); k++ {
+			if c := compareNames(a.ranks[k], a.names[k], b.ranks[k], b.names[k]); c != 0 {
+				return c < 0
+			}
+		}
+		return len(a.names) < len(b.names)
+	})
+	ordered := make(bson.D, 0, len(entries))
+	for _, e := range entries {
+		ordered = append(ordered, bson.E{Key: e.key, Value: orderValue(e.model, body[e.key])})
+	}
+	return ordered
+}
+
+func orderValue(model BsonModel, value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		return OrderDocument(model, v)
+	case map[string]interface{}:
+		return OrderDocument(model, bson.M(v))
+	default:
+		return value
+	}
+}
+
+func childModel(model BsonModel, name string) BsonModel {
+	if model == nil {
+		return nil
+	}
+	value, err := model.GetField(name)
+	if err != nil {
+		return nil
+	}
+	child, _ := value.(BsonModel)
+	return child
+}
+
+func sortNames(model BsonModel, names []string) {
+	ranks := make(map[string][]int, len(names))
+	for _, name := range names {
+		ranks[name] = nameRank(model, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return compareNames(ranks[names[i]], names[i], ranks[names[j]], names[j]) < 0
+	})
+}
+
+// nameRank returns the rank of the name in the model: fields of objects are
+// ranked by their index in the schema, keys of int maps are ranked by their
+// numeric value, and all other names are left to be compared by string.
+func nameRank(model BsonModel, name string) []int {
+	switch m := model.(type) {
+	case ObjectModel:
+		for i, field := range m.Schema().Fields {
+			if !field.Virtual && field.Bname == name {
+				return []int{i}
+			}
+		}
+		return []int{len(m.Schema().Fields)}
+	case IntObjectMapModel, IntSimpleMapModel:
+		if key, err := strconv.Atoi(name); err == nil {
+			return []int{0, key}
+		}
+		return []int{1}
+	}
+	return nil
+}
+
+func compareNames(rankA []int, nameA string, rankB []int, nameB string) int {
+	for i := 0; i < len(rankA) && i < len(rankB); i++ {
+		if rankA[i] != rankB[i] {
+			if rankA[i] < rankB[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	if len(rankA) != len(rankB) {
+		if len(rankA) < len(rankB) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(nameA, nameB)
+}
@@ -0,0 +1,175 @@
+package bsonmodel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// UnloadedFieldError returns the error that the field with the name is not
+// loaded by the partial load.
+func UnloadedFieldError(name string) error {
+	return errors.New(fmt.Sprintf("The field `%s` is not loaded", name))
+}
+
+// PartialState records which fields of a root model are loaded by a partial
+// load.
+//
+// Accessing fields which are not loaded panics, and they are omitted from the
+// documents, data, JSON and updates of the model.
+//
+// The zero value means all fields are loaded.
+type PartialState struct {
+	loaded map[string]struct{}
+}
+
+// PartialStateOf returns the partial state of the model with the schema
+// loaded with the projection.
+//
+// Only top-level fields are supported in the projection. The `_id` field is
+// always loaded unless it is excluded explicitly, and a projection including
+// only `_id` loads nothing else.
+func PartialStateOf(schema *ModelSchema, projection bson.M) (PartialState, error) {
+	if len(projection) == 0 {
+		return PartialState{}, nil
+	}
+	include := false
+	idIncluded := false
+	excluded := make(map[string]struct{})
+	for name, value := range projection {
+		if strings.ContainsRune(name, '.') {
+			return PartialState{}, errors.New(fmt.Sprintf("Only top-level fields are supported in projection but was `%s`", name))
+		}
+		if schema.FieldByBname(name) == nil {
+			return PartialState{}, NoSuchFieldError(name)
+		}
+		if projected(value) {
+			if name != "_id" {
+				include = true
+			} else {
+				idIncluded = true
+			}
+		} else {
+			excluded[name] = struct{}{}
+		}
+	}
+	if idIncluded && len(excluded) == 0 {
+		include = true
+	}
+	if include && len(excluded) > 0 {
+		if _, ok := excluded["_id"]; !ok || len(excluded) > 1 {
+			return PartialState{}, errors.New("Cannot mix inclusion and exclusion in projection")
+		}
+	}
+	loaded := make(map[string]struct{})
+	for _, field := range schema.Fields {
+		if field.Virtual {
+			continue
+		}
+		name := field.Bname
+		if _, ok := excluded[name]; ok {
+			continue
+		}
+		if include {
+			if _, ok := projection[name]; !ok && name != "_id" {
+				continue
+			}
+		}
+		loaded[name] = struct{}{}
+	}
+	return PartialState{loaded: loaded}, nil
+}
+
+func projected(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case int:
+		return v != 0
+	case int32:
+		return v != 0
+	case int64:
+		return v != 0
+	case float64:
+		return v != 0
+	default:
+		return value != nil
+	}
+}
+
+// IsPartial returns whether the model is partially loaded.
+func (s *PartialState) IsPartial() bool {
+	return s.loaded != nil
+}
+
+// Loaded returns whether the field with the BSON name is loaded.
+func (s *PartialState) Loaded(bname string) bool {
+	if s.loaded == nil {
+		return true
+	}
+	_, ok := s.loaded[bname]
+	return ok
+}
+
+// Check panics if the field with the BSON name is not loaded.
+func (s *PartialState) Check(bname string) {
+	if !s.Loaded(bname) {
+		panic(UnloadedFieldError(bname))
+	}
+}
+
+// CheckField returns the error if the field with the BSON name exists in the
+// schema but is not loaded.
+func (s *PartialState) CheckField(schema *ModelSchema, bname string) error {
+	if s.loaded == nil || s.Loaded(bname) || schema.FieldByBname(bname) == nil {
+		return nil
+	}
+	return UnloadedFieldError(bname)
+}
+
+// FilterFields removes the fields which are not loaded from the fields keyed by
+// BSON names.
+func (s *PartialState) FilterFields(fields map[string]interface{}) {
+	if s.loaded == nil {
+		return
+	}
+	for bname := range fields {
+		if !s.Loaded(bname) {
+			delete(fields, bname)
+		}
+	}
+}
+
+// Clear marks all fields as loaded.
+func (s *PartialState) Clear() {
+	s.loaded = nil
+}
+
+// FilterUpdates removes the updates on the fields which are not loaded, and
+// returns the updates.
+func (s *PartialState) FilterUpdates(updates bson.M) bson.M {
+	if s.loaded == nil {
+		return updates
+	}
+	for operator, value := range updates {
+		body, ok := value.(bson.M)
+		if !ok {
+			continue
+		}
+		for name := range body {
+			bname := name
+			if i := strings.IndexByte(name, '.'); i >= 0 {
+				bname = name[:i]
+			}
+			if !s.Loaded(bname) {
+				delete(body, name)
+			}
+		}
+		if len(body) == 0 {
+			delete(updates, operator)
+		}
+	}
+	return updates
+}
@@ -0,0 +1,99 @@
+package bsonmodel
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var partialTestSchema = &ModelSchema{
+	Name: "Test",
+	Type: ModelTypeRoot,
+	Fields: []*FieldSchema{
+		{Name: "id", Bname: "_id", Type: FieldTypeInt},
+		{Name: "a", Bname: "a", Type: FieldTypeInt},
+		{Name: "b", Bname: "b", Type: FieldTypeSimpleMap, KeyType: "int", ValueType: "int"},
+		{Name: "c", Bname: "c", Type: FieldTypeString},
+	},
+}
+
+func TestPartialStateOf(t *testing.T) {
+	state, err := PartialStateOf(partialTestSchema, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if state.IsPartial() {
+		t.Error("The value expected false but was true")
+	}
+	state, err = PartialStateOf(partialTestSchema, bson.M{"a": 1})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if !state.Loaded("_id") || !state.Loaded("a") || state.Loaded("b") || state.Loaded("c") {
+		t.Errorf("The value expected <%v> but was <%v>", "[_id a]", state.loaded)
+	}
+	state, err = PartialStateOf(partialTestSchema, bson.M{"_id": 0, "b": false})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if state.Loaded("_id") || !state.Loaded("a") || state.Loaded("b") || !state.Loaded("c") {
+		t.Errorf("The value expected <%v> but was <%v>", "[a c]", state.loaded)
+	}
+	state, err = PartialStateOf(partialTestSchema, bson.M{"_id": 1})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if !state.Loaded("_id") || state.Loaded("a") || state.Loaded("b") || state.Loaded("c") {
+		t.Errorf("The value expected <%v> but was <%v>", "[_id]", state.loaded)
+	}
+	state, err = PartialStateOf(partialTestSchema, bson.M{"_id": 1, "b": 0})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if !state.Loaded("_id") || !state.Loaded("a") || state.Loaded("b") || !state.Loaded("c") {
+		t.Errorf("The value expected <%v> but was <%v>", "[_id a c]", state.loaded)
+	}
+	_, err = PartialStateOf(partialTestSchema, bson.M{"a": 1, "b": 0})
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+	_, err = PartialStateOf(partialTestSchema, bson.M{"x": 1})
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+}
+
+func TestFilterUpdates(t *testing.T) {
+	state, _ := PartialStateOf(partialTestSchema, bson.M{"a": 1})
+	updates := bson.M{"$set": bson.M{"a": 1, "c": "c"}, "$unset": bson.M{"b.1": ""}}
+	expected := bson.M{"$set": bson.M{"a": 1}}
+	state.FilterUpdates(updates)
+	if !reflect.DeepEqual(expected, updates) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, updates)
+	}
+	err := state.CheckField(partialTestSchema, "c")
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+	err = state.CheckField(partialTestSchema, "x")
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+}
+
+func TestFilterFields(t *testing.T) {
+	state, _ := PartialStateOf(partialTestSchema, bson.M{"a": 1})
+	fields := bson.M{"_id": 1, "a": 1, "b": bson.M{}, "c": ""}
+	state.FilterFields(fields)
+	expected := bson.M{"_id": 1, "a": 1}
+	if !reflect.DeepEqual(expected, fields) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, fields)
+	}
+	state.Clear()
+	fields = bson.M{"_id": 1, "b": bson.M{}}
+	state.FilterFields(fields)
+	if len(fields) != 2 {
+		t.Errorf("The value expected <%v> but was <%v>", 2, len(fields))
+	}
+}
@@ -0,0 +1,107 @@
+package bsonmodel
+
+import (
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JsonPatchOperation is an operation of JSON Patch (RFC 6902).
+type JsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MergePatchOf returns the changes of the model as a JSON Merge Patch
+// (RFC 7396) document, which applies to the document of the root model in
+// the same structure as ToData(). Removed values are set to null.
+//
+// Keys are BSON names, the same as ToData(), so the fields ignored in JSON,
+// such as the update version and times, are also included.
+func MergePatchOf(model BsonModel) map[string]interface{} {
+	patch := make(map[string]interface{})
+	if !model.AnyUpdated() {
+		return patch
+	}
+	updates := model.AppendUpdates(bson.M{})
+	if dset, ok := updates["$set"].(bson.M); ok {
+		for name, value := range dset {
+			putMergePatch(patch, strings.Split(name, "."), patchValue(value))
+		}
+	}
+	if unset, ok := updates["$unset"].(bson.M); ok {
+		for name := range unset {
+			putMergePatch(patch, strings.Split(name, "."), nil)
+		}
+	}
+	return patch
+}
+
+func putMergePatch(patch map[string]interface{}, names []string, value interface{}) {
+	last := len(names) - 1
+	for _, name := range names[:last] {
+		child, ok := patch[name].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			patch[name] = child
+		}
+		patch = child
+	}
+	patch[names[last]] = value
+}
+
+// JsonPatchOf returns the changes of the model as JSON Patch (RFC 6902)
+// operations, which apply to the document of the root model in the same
+// structure as ToData(). The operations are sorted by their paths.
+//
+// Paths are built from BSON names, the same as ToData(), so the fields
+// ignored in JSON, such as the update version and times, are also included.
+func JsonPatchOf(model BsonModel) []JsonPatchOperation {
+	operations := make([]JsonPatchOperation, 0)
+	if !model.AnyUpdated() {
+		return operations
+	}
+	updates := model.AppendUpdates(bson.M{})
+	if dset, ok := updates["$set"].(bson.M); ok {
+		for name, value := range dset {
+			pointer := JsonPointerOf(&path{name})
+			operations = append(operations, JsonPatchOperation{Op: "add", Path: pointer, Value: patchValue(value)})
+		}
+	}
+	if unset, ok := updates["$unset"].(bson.M); ok {
+		for name := range unset {
+			pointer := JsonPointerOf(&path{name})
+			operations = append(operations, JsonPatchOperation{Op: "remove", Path: pointer})
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].Path < operations[j].Path
+	})
+	return operations
+}
+
+// patchValue converts the BSON value to the same form as ToData().
+func patchValue(value interface{}) interface{} {
+	switch value.(type) {
+	case primitive.DateTime:
+		return value.(primitive.DateTime).Time().UnixMilli()
+	case bson.M:
+		data := make(map[string]interface{})
+		for k, v := range value.(bson.M) {
+			data[k] = patchValue(v)
+		}
+		return data
+	case bson.A:
+		a := value.(bson.A)
+		data := make([]interface{}, 0, len(a))
+		for _, v := range a {
+			data = append(data, patchValue(v))
+		}
+		return data
+	default:
+		return value
+	}
+}
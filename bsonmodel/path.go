@@ -0,0 +1,67 @@
+package bsonmodel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NoSuchFieldError returns the error that the field or the map entry with
+// the name does not exist.
+func NoSuchFieldError(name string) error {
+	return errors.New(fmt.Sprintf("No such field `%s`", name))
+}
+
+// UnsettableFieldError returns the error that the field with the name can
+// not be set as a whole.
+func UnsettableFieldError(name string) error {
+	return errors.New(fmt.Sprintf("The field `%s` can not be set", name))
+}
+
+// GetPath returns the value on the path, which is relative to the model.
+//
+// Objects and maps on the path are returned as models, other values are
+// returned as they are stored in the model.
+func GetPath(model BsonModel, xpath DotNotation) (interface{}, error) {
+	if xpath.IsRoot() {
+		return model, nil
+	}
+	var value interface{} = model
+	for _, name := range strings.Split(xpath.Value(), ".") {
+		m, ok := value.(BsonModel)
+		if !ok {
+			return nil, NoSuchFieldError(name)
+		}
+		v, err := m.GetField(name)
+		if err != nil {
+			return nil, err
+		}
+		value = v
+	}
+	return value, nil
+}
+
+// SetPath sets the value on the path, which is relative to the model.
+//
+// The value is converted with the same rules as loading from BSON documents,
+// and the target is marked as updated.
+func SetPath(model BsonModel, xpath DotNotation, value interface{}) error {
+	if xpath.IsRoot() {
+		return errors.New("The root path can not be set")
+	}
+	p := xpath.Value()
+	parent := model
+	if i := strings.LastIndexByte(p, '.'); i >= 0 {
+		v, err := GetPath(model, &path{p[:i]})
+		if err != nil {
+			return err
+		}
+		m, ok := v.(BsonModel)
+		if !ok {
+			return NoSuchFieldError(p[i+1:])
+		}
+		parent = m
+		p = p[i+1:]
+	}
+	return parent.SetField(p, value)
+}
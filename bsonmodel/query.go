@@ -0,0 +1,225 @@
+package bsonmodel
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// IntMapPath is the path builder of a map with int keys.
+type IntMapPath struct {
+	base DotNotation
+}
+
+// NewIntMapPath returns the path builder of the int map on the path.
+func NewIntMapPath(base DotNotation) IntMapPath {
+	return IntMapPath{base}
+}
+
+// Path returns the path of the map itself.
+func (p IntMapPath) Path() DotNotation {
+	return p.base
+}
+
+// Key returns the path of the entry with the key.
+func (p IntMapPath) Key(key int) DotNotation {
+	return p.base.ResolveIndex(key)
+}
+
+// StringMapPath is the path builder of a map with string keys.
+type StringMapPath struct {
+	base DotNotation
+}
+
+// NewStringMapPath returns the path builder of the string map on the path.
+func NewStringMapPath(base DotNotation) StringMapPath {
+	return StringMapPath{base}
+}
+
+// Path returns the path of the map itself.
+func (p StringMapPath) Path() DotNotation {
+	return p.base
+}
+
+// Key returns the path of the entry with the key.
+func (p StringMapPath) Key(key string) DotNotation {
+	return p.base.Resolve(key)
+}
+
+// Eq returns the filter element that the value on the path equals to the
+// value.
+func Eq(xpath DotNotation, value interface{}) bson.E {
+	return bson.E{Key: xpath.Value(), Value: value}
+}
+
+// Ne returns the filter element that the value on the path does not equal to
+// the value.
+func Ne(xpath DotNotation, value interface{}) bson.E {
+	return operatorFilter(xpath, "$ne", value)
+}
+
+// Gt returns the filter element that the value on the path is greater than
+// the value.
+func Gt(xpath DotNotation, value interface{}) bson.E {
+	return operatorFilter(xpath, "$gt", value)
+}
+
+// Gte returns the filter element that the value on the path is greater than
+// or equal to the value.
+func Gte(xpath DotNotation, value interface{}) bson.E {
+	return operatorFilter(xpath, "$gte", value)
+}
+
+// Lt returns the filter element that the value on the path is less than the
+// value.
+func Lt(xpath DotNotation, value interface{}) bson.E {
+	return operatorFilter(xpath, "$lt", value)
+}
+
+// Lte returns the filter element that the value on the path is less than or
+// equal to the value.
+func Lte(xpath DotNotation, value interface{}) bson.E {
+	return operatorFilter(xpath, "$lte", value)
+}
+
+// In returns the filter element that the value on the path is one of the
+// values.
+func In(xpath DotNotation, values ...interface{}) bson.E {
+	return operatorFilter(xpath, "$in", bson.A(values))
+}
+
+// Nin returns the filter element that the value on the path is none of the
+// values.
+func Nin(xpath DotNotation, values ...interface{}) bson.E {
+	return operatorFilter(xpath, "$nin", bson.A(values))
+}
+
+// Exists returns the filter element that the path exists, or not.
+func Exists(xpath DotNotation, exists bool) bson.E {
+	return operatorFilter(xpath, "$exists", exists)
+}
+
+func operatorFilter(xpath DotNotation, operator string, value interface{}) bson.E {
+	return bson.E{Key: xpath.Value(), Value: bson.D{{Key: operator, Value: value}}}
+}
+
+// And returns the filter element that all the filters match.
+func And(filters ...bson.D) bson.E {
+	return logicalFilter("$and", filters)
+}
+
+// Or returns the filter element that any of the filters matches.
+func Or(filters ...bson.D) bson.E {
+	return logicalFilter("$or", filters)
+}
+
+func logicalFilter(operator string, filters []bson.D) bson.E {
+	a := make(bson.A, 0, len(filters))
+	for _, filter := range filters {
+		a = append(a, filter)
+	}
+	return bson.E{Key: operator, Value: a}
+}
+
+// Filter returns the filter document of the elements in order.
+//
+// Elements on the same path are merged into one element, an equality is
+// folded into `$eq`, so that conditions like Gte() and Lt() on the same path
+// are both kept. Repeated And() elements are concatenated, and any other
+// repeated element which cannot be merged, like two Or() elements or the same
+// operator twice on a path, is moved into the `$and` element.
+func Filter(elements ...bson.E) bson.D {
+	filter := make(bson.D, 0, len(elements))
+	indexes := make(map[string]int, len(elements))
+	for _, e := range elements {
+		i, ok := indexes[e.Key]
+		if !ok {
+			indexes[e.Key] = len(filter)
+			filter = append(filter, e)
+			continue
+		}
+		if e.Key == "$and" {
+			if a, ok := e.Value.(bson.A); ok {
+				if former, ok := filter[i].Value.(bson.A); ok {
+					filter[i].Value = append(append(make(bson.A, 0, len(former)+len(a)), former...), a...)
+					continue
+				}
+			}
+		} else if !strings.HasPrefix(e.Key, "$") {
+			if merged, ok := mergeOperators(filter[i].Value, e.Value); ok {
+				filter[i].Value = merged
+				continue
+			}
+		}
+		filter = appendAnd(filter, indexes, bson.D{e})
+	}
+	return filter
+}
+
+// FilterM returns the filter document of the elements as bson.M, elements are
+// merged in the same way as Filter().
+func FilterM(elements ...bson.E) bson.M {
+	filter := make(bson.M, len(elements))
+	for _, e := range Filter(elements...) {
+		if operators, ok := e.Value.(bson.D); ok && isOperators(operators) {
+			filter[e.Key] = operators.Map()
+		} else {
+			filter[e.Key] = e.Value
+		}
+	}
+	return filter
+}
+
+func appendAnd(filter bson.D, indexes map[string]int, value bson.D) bson.D {
+	if i, ok := indexes["$and"]; ok {
+		if a, ok := filter[i].Value.(bson.A); ok {
+			filter[i].Value = append(append(make(bson.A, 0, len(a)+1), a...), value)
+			return filter
+		}
+	}
+	indexes["$and"] = len(filter)
+	return append(filter, bson.E{Key: "$and", Value: bson.A{value}})
+}
+
+func mergeOperators(a interface{}, b interface{}) (bson.D, bool) {
+	da, db := operatorsOf(a), operatorsOf(b)
+	for _, e := range db {
+		for _, former := range da {
+			if e.Key == former.Key {
+				return nil, false
+			}
+		}
+	}
+	return append(append(make(bson.D, 0, len(da)+len(db)), da...), db...), true
+}
+
+func operatorsOf(value interface{}) bson.D {
+	if d, ok := value.(bson.D); ok && isOperators(d) {
+		return d
+	}
+	return bson.D{{Key: "$eq", Value: value}}
+}
+
+func isOperators(d bson.D) bool {
+	for _, e := range d {
+		if !strings.HasPrefix(e.Key, "$") {
+			return false
+		}
+	}
+	return len(d) > 0
+}
+
+// Asc returns the sort element that sorts by the path in ascending order.
+func Asc(xpath DotNotation) bson.E {
+	return bson.E{Key: xpath.Value(), Value: 1}
+}
+
+// Desc returns the sort element that sorts by the path in descending order.
+func Desc(xpath DotNotation) bson.E {
+	return bson.E{Key: xpath.Value(), Value: -1}
+}
+
+// Sort returns the sort document of the elements in order.
+func Sort(elements ...bson.E) bson.D {
+	return append(make(bson.D, 0, len(elements)), elements...)
+}
@@ -0,0 +1,68 @@
+package bsonmodel
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFilterM(t *testing.T) {
+	xpath := PathOfNames("a", "b")
+	filter := FilterM(
+		Eq(PathOfNames("_id"), 1),
+		Gt(xpath, 1),
+		Lte(xpath, 9),
+		In(PathOfNames("c"), 1, 2, 3),
+		Or(Filter(Ne(PathOfNames("d"), "x")), Filter(Nin(PathOfNames("e"), "y"))),
+	)
+	expected := bson.M{
+		"_id": 1,
+		"a.b": bson.M{"$gt": 1, "$lte": 9},
+		"c":   bson.M{"$in": bson.A{1, 2, 3}},
+		"$or": bson.A{
+			bson.D{{Key: "d", Value: bson.D{{Key: "$ne", Value: "x"}}}},
+			bson.D{{Key: "e", Value: bson.D{{Key: "$nin", Value: bson.A{"y"}}}}},
+		},
+	}
+	if !reflect.DeepEqual(expected, filter) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, filter)
+	}
+	filter = FilterM(Eq(xpath, 1), Gt(xpath, 0))
+	expected = bson.M{"a.b": bson.M{"$eq": 1, "$gt": 0}}
+	if !reflect.DeepEqual(expected, filter) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, filter)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	xpath := PathOfNames("a", "b")
+	filter := Filter(Gte(xpath, 1), Eq(xpath, 5), Lt(xpath, 9))
+	expected := bson.D{{Key: "a.b", Value: bson.D{{Key: "$gte", Value: 1}, {Key: "$eq", Value: 5}, {Key: "$lt", Value: 9}}}}
+	if !reflect.DeepEqual(expected, filter) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, filter)
+	}
+	c, d := Filter(Eq(PathOfNames("c"), 1)), Filter(Eq(PathOfNames("d"), 1))
+	e, f := Filter(Eq(PathOfNames("e"), 1)), Filter(Eq(PathOfNames("f"), 1))
+	filter = Filter(Or(c, d), Or(e, f))
+	expected = bson.D{
+		{Key: "$or", Value: bson.A{c, d}},
+		{Key: "$and", Value: bson.A{bson.D{{Key: "$or", Value: bson.A{e, f}}}}},
+	}
+	if !reflect.DeepEqual(expected, filter) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, filter)
+	}
+	filter = Filter(And(c), Gt(xpath, 0), Gt(xpath, 1), And(d, e))
+	expected = bson.D{
+		{Key: "$and", Value: bson.A{c, bson.D{{Key: "a.b", Value: bson.D{{Key: "$gt", Value: 1}}}}, d, e}},
+		{Key: "a.b", Value: bson.D{{Key: "$gt", Value: 0}}},
+	}
+	if !reflect.DeepEqual(expected, filter) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, filter)
+	}
+	filterM := FilterM(Eq(xpath, 1), Eq(xpath, 2))
+	expectedM := bson.M{"a.b": 1, "$and": bson.A{bson.D{{Key: "a.b", Value: 2}}}}
+	if !reflect.DeepEqual(expectedM, filterM) {
+		t.Errorf("The value expected <%v> but was <%v>", expectedM, filterM)
+	}
+}
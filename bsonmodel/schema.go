@@ -0,0 +1,88 @@
+package bsonmodel
+
+// ModelType is the type of a model.
+type ModelType string
+
+const (
+	ModelTypeRoot     ModelType = "root"
+	ModelTypeObject   ModelType = "object"
+	ModelTypeMapValue ModelType = "map-value"
+)
+
+// FieldType is the type of a field, same as the type in the model
+// configuration.
+type FieldType string
+
+const (
+	FieldTypeInt        FieldType = "int"
+	FieldTypeString     FieldType = "string"
+	FieldTypeFloat64    FieldType = "float64"
+	FieldTypeDateTime   FieldType = "datetime"
+	FieldTypeDate       FieldType = "date"
+	FieldTypeObject     FieldType = "object"
+	FieldTypeMap        FieldType = "map"
+	FieldTypeSimpleMap  FieldType = "simple-map"
+	FieldTypeSimpleList FieldType = "simple-list"
+)
+
+// ModelSchema describes a generated model.
+type ModelSchema struct {
+	// Name is the name of the model.
+	Name string
+	// Type is the type of the model.
+	Type ModelType
+	// Fields are the fields of the model, in the declared order.
+	Fields []*FieldSchema
+}
+
+// Field returns the field with the name, or nil if not found.
+func (schema *ModelSchema) Field(name string) *FieldSchema {
+	for _, field := range schema.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// FieldByBname returns the non-virtual field with the BSON name, or nil if
+// not found.
+func (schema *ModelSchema) FieldByBname(bname string) *FieldSchema {
+	for _, field := range schema.Fields {
+		if !field.Virtual && field.Bname == bname {
+			return field
+		}
+	}
+	return nil
+}
+
+// FieldSchema describes a field of a generated model.
+type FieldSchema struct {
+	// Name is the name of the field, used in JSON and sync data.
+	Name string
+	// Bname is the BSON name of the field, empty for virtual fields.
+	Bname string
+	// Type is the type of the field.
+	Type FieldType
+	// KeyType is the key type of map fields.
+	KeyType string
+	// ValueType is the value type of map, simple-map and simple-list fields.
+	ValueType string
+	// Model is the schema of the object model, or the value model of map
+	// fields.
+	Model *ModelSchema
+	// Virtual is whether the field is virtual.
+	Virtual bool
+	// Long is whether the int field is declared as `long`, which is a 64-bit
+	// integer on the clients.
+	Long bool
+	// Sources are the names of fields which the virtual field is computed
+	// from.
+	Sources []string
+	// JsonIgnore is whether the field is ignored in JSON and sync data.
+	JsonIgnore bool
+	// Required is whether the field is required.
+	Required bool
+	// Lazy is whether the map field is decoded lazily on first access.
+	Lazy bool
+}
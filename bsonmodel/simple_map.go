@@ -4,20 +4,23 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"strconv"
 	"time"
 	"unsafe"
 
-	mapset "github.com/deckarep/golang-set"
 	jsoniter "github.com/json-iterator/go"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type SimpleValueType interface {
 	Parse(value interface{}) (interface{}, error)
 	ParseJsoniter(value jsoniter.Any) (interface{}, error)
+	ParseJsonIterator(iter *jsoniter.Iterator) (interface{}, error)
+	ParseRaw(value bson.RawValue) (interface{}, error)
 	ToBson(value interface{}) interface{}
 	ToData(value interface{}) interface{}
 }
@@ -66,6 +69,19 @@ func (valueType *intValeType) ParseJsoniter(value jsoniter.Any) (interface{}, er
 	return nil, e
 }
 
+func (valueType *intValeType) ParseJsonIterator(iter *jsoniter.Iterator) (interface{}, error) {
+	if iter.WhatIsNext() == jsoniter.NumberValue {
+		return iter.ReadInt(), IterError(iter)
+	}
+	e := errors.New(fmt.Sprintf("The value is not a NUMBER (%s)", valueTypeName(iter.WhatIsNext())))
+	iter.Skip()
+	return nil, e
+}
+
+func (valueType *intValeType) ParseRaw(value bson.RawValue) (interface{}, error) {
+	return ParseRawInt(value)
+}
+
 type stringValeType struct {
 	identityValueType
 }
@@ -88,6 +104,19 @@ func (valueType *stringValeType) ParseJsoniter(value jsoniter.Any) (interface{},
 	return nil, e
 }
 
+func (valueType *stringValeType) ParseJsonIterator(iter *jsoniter.Iterator) (interface{}, error) {
+	if iter.WhatIsNext() == jsoniter.StringValue {
+		return iter.ReadString(), IterError(iter)
+	}
+	e := errors.New(fmt.Sprintf("The value is not a STRING (%s)", valueTypeName(iter.WhatIsNext())))
+	iter.Skip()
+	return nil, e
+}
+
+func (valueType *stringValeType) ParseRaw(value bson.RawValue) (interface{}, error) {
+	return ParseRawString(value)
+}
+
 type float64ValeType struct {
 	identityValueType
 }
@@ -118,6 +147,19 @@ func (valueType *float64ValeType) ParseJsoniter(value jsoniter.Any) (interface{}
 	return nil, e
 }
 
+func (valueType *float64ValeType) ParseJsonIterator(iter *jsoniter.Iterator) (interface{}, error) {
+	if iter.WhatIsNext() == jsoniter.NumberValue {
+		return iter.ReadFloat64(), IterError(iter)
+	}
+	e := errors.New(fmt.Sprintf("The value is not a NUMBER (%s)", valueTypeName(iter.WhatIsNext())))
+	iter.Skip()
+	return nil, e
+}
+
+func (valueType *float64ValeType) ParseRaw(value bson.RawValue) (interface{}, error) {
+	return ParseRawFloat64(value)
+}
+
 type boolValeType struct {
 	identityValueType
 }
@@ -140,6 +182,23 @@ func (valueType *boolValeType) ParseJsoniter(value jsoniter.Any) (interface{}, e
 	return nil, e
 }
 
+func (valueType *boolValeType) ParseJsonIterator(iter *jsoniter.Iterator) (interface{}, error) {
+	if iter.WhatIsNext() == jsoniter.BoolValue {
+		return iter.ReadBool(), IterError(iter)
+	}
+	e := errors.New(fmt.Sprintf("The value is not a BOOLEAN (%s)", valueTypeName(iter.WhatIsNext())))
+	iter.Skip()
+	return nil, e
+}
+
+func (valueType *boolValeType) ParseRaw(value bson.RawValue) (interface{}, error) {
+	if value.Type == bsontype.Boolean {
+		return value.Boolean(), nil
+	}
+	e := errors.New(fmt.Sprintf("Type %v can not be cast to type bool", value.Type))
+	return nil, e
+}
+
 type datetimeValueType struct {
 }
 
@@ -172,6 +231,26 @@ func (valueType *datetimeValueType) ParseJsoniter(value jsoniter.Any) (interface
 	return nil, e
 }
 
+func (valueType *datetimeValueType) ParseJsonIterator(iter *jsoniter.Iterator) (interface{}, error) {
+	switch iter.WhatIsNext() {
+	case jsoniter.NilValue:
+		iter.Skip()
+		return nil, IterError(iter)
+	case jsoniter.NumberValue:
+		return time.UnixMilli(iter.ReadInt64()), IterError(iter)
+	}
+	e := errors.New(fmt.Sprintf("The value is not a NUMBER (%s)", valueTypeName(iter.WhatIsNext())))
+	iter.Skip()
+	return nil, e
+}
+
+func (valueType *datetimeValueType) ParseRaw(value bson.RawValue) (interface{}, error) {
+	if isRawNil(value) {
+		return nil, nil
+	}
+	return ParseRawDateTime(value)
+}
+
 func (valueType *datetimeValueType) ToBson(value interface{}) interface{} {
 	return primitive.NewDateTimeFromTime(value.(time.Time))
 }
@@ -213,6 +292,26 @@ func (valueType *dateValueType) ParseJsoniter(value jsoniter.Any) (interface{},
 	return nil, e
 }
 
+func (valueType *dateValueType) ParseJsonIterator(iter *jsoniter.Iterator) (interface{}, error) {
+	switch iter.WhatIsNext() {
+	case jsoniter.NilValue:
+		iter.Skip()
+		return nil, IterError(iter)
+	case jsoniter.NumberValue:
+		return NumberToDate(iter.ReadInt()), IterError(iter)
+	}
+	e := errors.New(fmt.Sprintf("The value is not a NUMBER (%s)", valueTypeName(iter.WhatIsNext())))
+	iter.Skip()
+	return nil, e
+}
+
+func (valueType *dateValueType) ParseRaw(value bson.RawValue) (interface{}, error) {
+	if isRawNil(value) {
+		return nil, nil
+	}
+	return ParseRawDate(value)
+}
+
 func (valueType *dateValueType) ToBson(value interface{}) interface{} {
 	return DateToNumber(value.(time.Time))
 }
@@ -258,36 +357,87 @@ type IntSimpleMapModel interface {
 	Get(key int) interface{}
 	Put(key int, value interface{}) interface{}
 	Remove(key int) bool
+	CopyFrom(other IntSimpleMapModel)
 }
 
 type intSimpleMap struct {
 	baseSimpleMap
+	intMapKeys
 	data map[int]interface{}
 }
 
 func (imap *intSimpleMap) MarshalJSON() ([]byte, error) {
+	imap.load()
 	return json.Marshal(imap.data)
 }
 
 func (imap *intSimpleMap) Size() int {
+	imap.load()
 	return len(imap.data)
 }
 
+func (imap *intSimpleMap) saveKey(key int) {
+	tx := TransactionOf(imap)
+	if tx == nil {
+		return
+	}
+	tx.save(mapEntry{imap, key}, func() func() {
+		value, existed := imap.data[key]
+		updated := imap.updatedKeys.Contains(key)
+		removed := imap.removedKeys.Contains(key)
+		return func() {
+			if existed {
+				imap.data[key] = value
+			} else {
+				delete(imap.data, key)
+			}
+			imap.updatedKeys.restore(key, updated)
+			imap.removedKeys.restore(key, removed)
+		}
+	})
+}
+
+func (imap *intSimpleMap) saveAll() {
+	tx := TransactionOf(imap)
+	if tx == nil {
+		return
+	}
+	tx.save(imap, func() func() {
+		data := make(map[int]interface{}, len(imap.data))
+		for k, v := range imap.data {
+			data[k] = v
+		}
+		updatedKeys := imap.updatedKeys.Clone()
+		removedKeys := imap.removedKeys.Clone()
+		lazy := imap.lazy
+		return func() {
+			imap.data = data
+			imap.updatedKeys = updatedKeys
+			imap.removedKeys = removedKeys
+			imap.lazy = lazy
+		}
+	})
+}
+
 func (imap *intSimpleMap) Clear() {
+	imap.load()
+	imap.saveAll()
 	imap.updatedKeys.Clear()
 	removedKeys := imap.removedKeys
 	data := imap.data
 	for k := range data {
 		removedKeys.Add(k)
 	}
-	for k := range data {
+	for k, v := range data {
 		delete(data, k)
+		emitIntKeyChange(imap, k, v, nil)
 	}
 }
 
 func (imap *intSimpleMap) Keys() []int {
+	imap.load()
 	data := imap.data
-	keys := make([]int, len(data))
+	keys := make([]int, 0, len(data))
 	for k := range data {
 		keys = append(keys, k)
 	}
@@ -295,6 +445,7 @@ func (imap *intSimpleMap) Keys() []int {
 }
 
 func (imap *intSimpleMap) Get(key int) interface{} {
+	imap.load()
 	value, ok := imap.data[key]
 	if ok {
 		return value
@@ -303,38 +454,59 @@ func (imap *intSimpleMap) Get(key int) interface{} {
 }
 
 func (imap *intSimpleMap) Put(key int, value interface{}) interface{} {
+	imap.load()
+	imap.saveKey(key)
 	data := imap.data
 	old, ok := data[key]
 	if ok {
 		if old != value {
 			data[key] = value
 			imap.updatedKeys.Add(key)
+			emitIntKeyChange(imap, key, old, value)
 		}
 		return old
 	}
 	data[key] = value
 	imap.updatedKeys.Add(key)
 	imap.removedKeys.Remove(key)
+	emitIntKeyChange(imap, key, nil, value)
 	return nil
 }
 
 func (imap *intSimpleMap) Remove(key int) bool {
+	imap.load()
+	imap.saveKey(key)
 	data := imap.data
-	_, ok := data[key]
+	old, ok := data[key]
 	if ok {
 		delete(data, key)
 		imap.updatedKeys.Remove(key)
 		imap.removedKeys.Add(key)
+		emitIntKeyChange(imap, key, old, nil)
 		return true
 	}
 	return false
 }
 
+// CopyFrom copies all entries from the other map, only the differing
+// entries will be marked as updated or removed.
+func (imap *intSimpleMap) CopyFrom(other IntSimpleMapModel) {
+	for _, key := range imap.Keys() {
+		if other.Get(key) == nil {
+			imap.Remove(key)
+		}
+	}
+	for _, key := range other.Keys() {
+		imap.Put(key, other.Get(key))
+	}
+}
+
 func (imap *intSimpleMap) ToBson() interface{} {
 	return imap.ToDocument()
 }
 
 func (imap *intSimpleMap) ToData() interface{} {
+	imap.load()
 	data := make(map[int]interface{})
 	valueType := imap.valueType
 	for key, value := range imap.data {
@@ -344,12 +516,18 @@ func (imap *intSimpleMap) ToData() interface{} {
 }
 
 func (imap *intSimpleMap) Reset() {
+	imap.saveAll()
 	imap.updatedKeys.Clear()
+	for key := range imap.removedKeys {
+		imap.keyPaths.remove(key)
+	}
 	imap.removedKeys.Clear()
 }
 
 func (imap *intSimpleMap) LoadJsoniter(any jsoniter.Any) error {
+	imap.checkReleased()
 	imap.Reset()
+	imap.lazy.clear()
 	data := imap.data
 	for k := range data {
 		delete(data, k)
@@ -373,35 +551,66 @@ func (imap *intSimpleMap) LoadJsoniter(any jsoniter.Any) error {
 	return nil
 }
 
+func (imap *intSimpleMap) LoadJsonIterator(iter *jsoniter.Iterator) error {
+	imap.checkReleased()
+	imap.Reset()
+	imap.lazy.clear()
+	data := imap.data
+	for k := range data {
+		delete(data, k)
+	}
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		return IterError(iter)
+	}
+	valueType := imap.valueType
+	var err error
+	iter.ReadMapCB(func(iter *jsoniter.Iterator, key string) bool {
+		k, e := strconv.Atoi(key)
+		if e != nil {
+			// skip key that not be an int
+			iter.Skip()
+			return true
+		}
+		v, e := valueType.ParseJsonIterator(iter)
+		if e != nil {
+			err = e
+			return false
+		}
+		data[k] = v
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return IterError(iter)
+}
+
 func (imap *intSimpleMap) AppendUpdates(updates bson.M) bson.M {
 	data := imap.data
 	updatedKeys := imap.updatedKeys
-	if updatedKeys.Cardinality() > 0 {
+	if updatedKeys.Len() > 0 {
 		dset := FixedEmbedded(updates, "$set")
 		valueType := imap.valueType
-		for _, uk := range updatedKeys.ToSlice() {
-			key := uk.(int)
+		xpath := imap.XPath()
+		for key := range updatedKeys {
 			value := data[key]
-			k := strconv.Itoa(key)
-			name := imap.XPath().Resolve(k)
-			v := valueType.ToBson(value)
-			dset[name.Value()] = v
+			dset[imap.keyPaths.get(xpath, key)] = valueType.ToBson(value)
 		}
 	}
 	removedKeys := imap.removedKeys
-	if removedKeys.Cardinality() > 0 {
+	if removedKeys.Len() > 0 {
 		unset := FixedEmbedded(updates, "$unset")
-		for _, uk := range removedKeys.ToSlice() {
-			key := uk.(int)
-			k := strconv.Itoa(key)
-			name := imap.XPath().Resolve(k)
-			unset[name.Value()] = ""
+		xpath := imap.XPath()
+		for key := range removedKeys {
+			unset[imap.keyPaths.get(xpath, key)] = ""
 		}
 	}
 	return updates
 }
 
 func (imap *intSimpleMap) ToDocument() bson.M {
+	imap.load()
 	doc := bson.M{}
 	valueType := imap.valueType
 	for k, v := range imap.data {
@@ -413,7 +622,13 @@ func (imap *intSimpleMap) ToDocument() bson.M {
 }
 
 func (imap *intSimpleMap) LoadDocument(document bson.M) error {
+	imap.checkReleased()
 	imap.Reset()
+	imap.lazy.clear()
+	return imap.fillDocument(document)
+}
+
+func (imap *intSimpleMap) fillDocument(document bson.M) error {
 	data := imap.data
 	for k := range data {
 		delete(data, k)
@@ -434,14 +649,41 @@ func (imap *intSimpleMap) LoadDocument(document bson.M) error {
 	return nil
 }
 
+func (imap *intSimpleMap) LoadRaw(raw bson.Raw) error {
+	imap.checkReleased()
+	imap.Reset()
+	imap.lazy.clear()
+	return imap.fillRaw(raw)
+}
+
+func (imap *intSimpleMap) fillRaw(raw bson.Raw) error {
+	data := imap.data
+	for k := range data {
+		delete(data, k)
+	}
+	valueType := imap.valueType
+	return rangeRaw(raw, func(key string, value bson.RawValue) error {
+		k, err := strconv.Atoi(key)
+		if err != nil {
+			// skip key that not be an int
+			return nil
+		}
+		v, err := valueType.ParseRaw(value)
+		if err != nil {
+			return err
+		}
+		data[k] = v
+		return nil
+	})
+}
+
 func (imap *intSimpleMap) ToSync() interface{} {
 	sync := make(map[int]interface{})
 	updatedKeys := imap.updatedKeys
 	data := imap.data
-	if updatedKeys.Cardinality() > 0 {
+	if updatedKeys.Len() > 0 {
 		valueType := imap.valueType
-		for _, uk := range updatedKeys.ToSlice() {
-			key := uk.(int)
+		for key := range updatedKeys {
 			value := data[key]
 			sync[key] = valueType.ToData(value)
 		}
@@ -452,9 +694,8 @@ func (imap *intSimpleMap) ToSync() interface{} {
 func (imap *intSimpleMap) ToDelete() interface{} {
 	delete := make(map[int]int)
 	removedKeys := imap.removedKeys
-	if removedKeys.Cardinality() > 0 {
-		for _, uk := range removedKeys.ToSlice() {
-			key := uk.(int)
+	if removedKeys.Len() > 0 {
+		for key := range removedKeys {
 			delete[key] = 1
 		}
 	}
@@ -462,23 +703,152 @@ func (imap *intSimpleMap) ToDelete() interface{} {
 }
 
 func (imap *intSimpleMap) ToDataJson() (string, error) {
-	return jsoniter.MarshalToString(imap.ToData())
+	return DataJsonOf(imap)
 }
 
 func (imap *intSimpleMap) ToSyncJson() (string, error) {
-	return jsoniter.MarshalToString(imap.ToSync())
+	return SyncJsonOf(imap)
+}
+
+func (imap *intSimpleMap) EncodeData(stream *jsoniter.Stream) {
+	imap.load()
+	object := BeginJsonObject(stream)
+	valueType := imap.valueType
+	for key, value := range imap.data {
+		object.Field(strconv.Itoa(key))
+		stream.WriteVal(valueType.ToData(value))
+	}
+	object.End()
+}
+
+func (imap *intSimpleMap) EncodeSync(stream *jsoniter.Stream) {
+	object := BeginJsonObject(stream)
+	data := imap.data
+	valueType := imap.valueType
+	for key := range imap.updatedKeys {
+		object.Field(strconv.Itoa(key))
+		stream.WriteVal(valueType.ToData(data[key]))
+	}
+	object.End()
+}
+
+func (imap *intSimpleMap) WriteDataJson(w io.Writer) error {
+	return WriteDataJson(w, imap)
+}
+
+func (imap *intSimpleMap) WriteSyncJson(w io.Writer) error {
+	return WriteSyncJson(w, imap)
 }
 
 func (imap *intSimpleMap) ToDeleteJson() (string, error) {
 	return jsoniter.MarshalToString(imap.ToDelete())
 }
 
+func (imap *intSimpleMap) ToMergePatch() interface{} {
+	return MergePatchOf(imap)
+}
+
+func (imap *intSimpleMap) ToJsonPatch() []JsonPatchOperation {
+	return JsonPatchOf(imap)
+}
+
+func (imap *intSimpleMap) Changes() []Change {
+	return ChangesOf(imap)
+}
+
+func (imap *intSimpleMap) GetField(name string) (interface{}, error) {
+	imap.load()
+	key, err := strconv.Atoi(name)
+	if err != nil {
+		return nil, NoSuchFieldError(name)
+	}
+	value, ok := imap.data[key]
+	if !ok {
+		return nil, NoSuchFieldError(name)
+	}
+	return value, nil
+}
+
+func (imap *intSimpleMap) SetField(name string, value interface{}) error {
+	key, err := strconv.Atoi(name)
+	if err != nil {
+		return NoSuchFieldError(name)
+	}
+	if value == nil {
+		imap.Remove(key)
+		return nil
+	}
+	v, err := imap.valueType.Parse(value)
+	if err != nil {
+		return err
+	}
+	imap.Put(key, v)
+	return nil
+}
+
+func (imap *intSimpleMap) LoadDocumentLazily(document bson.M) {
+	imap.checkReleased()
+	imap.Reset()
+	data := imap.data
+	for k := range data {
+		delete(data, k)
+	}
+	imap.lazy.setDocument(document)
+}
+
+func (imap *intSimpleMap) LoadRawLazily(raw bson.Raw) {
+	imap.checkReleased()
+	imap.Reset()
+	data := imap.data
+	for k := range data {
+		delete(data, k)
+	}
+	imap.lazy.setRaw(raw)
+}
+
+func (imap *intSimpleMap) Loaded() bool {
+	return !imap.lazy.pending()
+}
+
+func (imap *intSimpleMap) Load() error {
+	imap.load()
+	return imap.lazy.err
+}
+
+func (imap *intSimpleMap) load() {
+	imap.checkReleased()
+	if imap.lazy.pending() {
+		imap.lazy.load(imap.fillDocument, imap.fillRaw)
+	}
+}
+
+func (imap *intSimpleMap) Discard() {
+	imap.Reset()
+	imap.lazy.clear()
+	imap.keyPaths.clear()
+	data := imap.data
+	for k := range data {
+		delete(data, k)
+	}
+	if Debug {
+		imap.released = true
+	}
+}
+
+func (imap *intSimpleMap) GetPath(xpath DotNotation) (interface{}, error) {
+	return GetPath(imap, xpath)
+}
+
+func (imap *intSimpleMap) SetPath(xpath DotNotation, value interface{}) error {
+	return SetPath(imap, xpath, value)
+}
+
 func NewIntSimpleMapModel(parent BsonModel, name string, valueType SimpleValueType) IntSimpleMapModel {
 	mapModel := &intSimpleMap{}
 	mapModel.parent = parent
 	mapModel.name = name
-	mapModel.updatedKeys = mapset.NewThreadUnsafeSet()
-	mapModel.removedKeys = mapset.NewThreadUnsafeSet()
+	mapModel.updatedKeys = make(intKeySet)
+	mapModel.removedKeys = make(intKeySet)
 	mapModel.valueType = valueType
 	mapModel.data = make(map[int]interface{})
 	return mapModel
@@ -490,36 +860,87 @@ type StringSimpleMapModel interface {
 	Get(key string) interface{}
 	Put(key string, value interface{}) interface{}
 	Remove(key string) bool
+	CopyFrom(other StringSimpleMapModel)
 }
 
 type stringSimpleMap struct {
 	baseSimpleMap
+	stringMapKeys
 	data map[string]interface{}
 }
 
 func (smap *stringSimpleMap) MarshalJSON() ([]byte, error) {
+	smap.load()
 	return json.Marshal(smap.data)
 }
 
 func (smap *stringSimpleMap) Size() int {
+	smap.load()
 	return len(smap.data)
 }
 
+func (smap *stringSimpleMap) saveKey(key string) {
+	tx := TransactionOf(smap)
+	if tx == nil {
+		return
+	}
+	tx.save(mapEntry{smap, key}, func() func() {
+		value, existed := smap.data[key]
+		updated := smap.updatedKeys.Contains(key)
+		removed := smap.removedKeys.Contains(key)
+		return func() {
+			if existed {
+				smap.data[key] = value
+			} else {
+				delete(smap.data, key)
+			}
+			smap.updatedKeys.restore(key, updated)
+			smap.removedKeys.restore(key, removed)
+		}
+	})
+}
+
+func (smap *stringSimpleMap) saveAll() {
+	tx := TransactionOf(smap)
+	if tx == nil {
+		return
+	}
+	tx.save(smap, func() func() {
+		data := make(map[string]interface{}, len(smap.data))
+		for k, v := range smap.data {
+			data[k] = v
+		}
+		updatedKeys := smap.updatedKeys.Clone()
+		removedKeys := smap.removedKeys.Clone()
+		lazy := smap.lazy
+		return func() {
+			smap.data = data
+			smap.updatedKeys = updatedKeys
+			smap.removedKeys = removedKeys
+			smap.lazy = lazy
+		}
+	})
+}
+
 func (smap *stringSimpleMap) Clear() {
+	smap.load()
+	smap.saveAll()
 	smap.updatedKeys.Clear()
 	removedKeys := smap.removedKeys
 	data := smap.data
 	for k := range data {
 		removedKeys.Add(k)
 	}
-	for k := range data {
+	for k, v := range data {
 		delete(data, k)
+		EmitChange(smap, k, v, nil)
 	}
 }
 
 func (smap *stringSimpleMap) Keys() []string {
+	smap.load()
 	data := smap.data
-	keys := make([]string, len(data))
+	keys := make([]string, 0, len(data))
 	for k := range data {
 		keys = append(keys, k)
 	}
@@ -527,6 +948,7 @@ func (smap *stringSimpleMap) Keys() []string {
 }
 
 func (smap *stringSimpleMap) Get(key string) interface{} {
+	smap.load()
 	value, ok := smap.data[key]
 	if ok {
 		return value
@@ -535,38 +957,59 @@ func (smap *stringSimpleMap) Get(key string) interface{} {
 }
 
 func (smap *stringSimpleMap) Put(key string, value interface{}) interface{} {
+	smap.load()
+	smap.saveKey(key)
 	data := smap.data
 	old, ok := data[key]
 	if ok {
 		if old != value {
 			data[key] = value
 			smap.updatedKeys.Add(key)
+			EmitChange(smap, key, old, value)
 		}
 		return old
 	}
 	data[key] = value
 	smap.updatedKeys.Add(key)
 	smap.removedKeys.Remove(key)
+	EmitChange(smap, key, nil, value)
 	return nil
 }
 
 func (smap *stringSimpleMap) Remove(key string) bool {
+	smap.load()
+	smap.saveKey(key)
 	data := smap.data
-	_, ok := data[key]
+	old, ok := data[key]
 	if ok {
 		delete(data, key)
 		smap.updatedKeys.Remove(key)
 		smap.removedKeys.Add(key)
+		EmitChange(smap, key, old, nil)
 		return true
 	}
 	return false
 }
 
+// CopyFrom copies all entries from the other map, only the differing
+// entries will be marked as updated or removed.
+func (smap *stringSimpleMap) CopyFrom(other StringSimpleMapModel) {
+	for _, key := range smap.Keys() {
+		if other.Get(key) == nil {
+			smap.Remove(key)
+		}
+	}
+	for _, key := range other.Keys() {
+		smap.Put(key, other.Get(key))
+	}
+}
+
 func (smap *stringSimpleMap) ToBson() interface{} {
 	return smap.ToDocument()
 }
 
 func (smap *stringSimpleMap) ToData() interface{} {
+	smap.load()
 	data := make(map[string]interface{})
 	valueType := smap.valueType
 	for key, value := range smap.data {
@@ -576,12 +1019,18 @@ func (smap *stringSimpleMap) ToData() interface{} {
 }
 
 func (smap *stringSimpleMap) Reset() {
+	smap.saveAll()
 	smap.updatedKeys.Clear()
+	for key := range smap.removedKeys {
+		smap.keyPaths.remove(key)
+	}
 	smap.removedKeys.Clear()
 }
 
 func (smap *stringSimpleMap) LoadJsoniter(any jsoniter.Any) error {
+	smap.checkReleased()
 	smap.Reset()
+	smap.lazy.clear()
 	data := smap.data
 	for k := range data {
 		delete(data, k)
@@ -600,33 +1049,60 @@ func (smap *stringSimpleMap) LoadJsoniter(any jsoniter.Any) error {
 	return nil
 }
 
+func (smap *stringSimpleMap) LoadJsonIterator(iter *jsoniter.Iterator) error {
+	smap.checkReleased()
+	smap.Reset()
+	smap.lazy.clear()
+	data := smap.data
+	for k := range data {
+		delete(data, k)
+	}
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		return IterError(iter)
+	}
+	valueType := smap.valueType
+	var err error
+	iter.ReadMapCB(func(iter *jsoniter.Iterator, key string) bool {
+		v, e := valueType.ParseJsonIterator(iter)
+		if e != nil {
+			err = e
+			return false
+		}
+		data[key] = v
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return IterError(iter)
+}
+
 func (smap *stringSimpleMap) AppendUpdates(updates bson.M) bson.M {
 	data := smap.data
 	updatedKeys := smap.updatedKeys
-	if updatedKeys.Cardinality() > 0 {
+	if updatedKeys.Len() > 0 {
 		dset := FixedEmbedded(updates, "$set")
 		valueType := smap.valueType
-		for _, uk := range updatedKeys.ToSlice() {
-			key := uk.(string)
-			name := smap.XPath().Resolve(key)
+		xpath := smap.XPath()
+		for key := range updatedKeys {
 			value := data[key]
-			v := valueType.ToBson(value)
-			dset[name.Value()] = v
+			dset[smap.keyPaths.get(xpath, key)] = valueType.ToBson(value)
 		}
 	}
 	removedKeys := smap.removedKeys
-	if removedKeys.Cardinality() > 0 {
+	if removedKeys.Len() > 0 {
 		unset := FixedEmbedded(updates, "$unset")
-		for _, uk := range removedKeys.ToSlice() {
-			key := uk.(string)
-			name := smap.XPath().Resolve(key)
-			unset[name.Value()] = ""
+		xpath := smap.XPath()
+		for key := range removedKeys {
+			unset[smap.keyPaths.get(xpath, key)] = ""
 		}
 	}
 	return updates
 }
 
 func (smap *stringSimpleMap) ToDocument() bson.M {
+	smap.load()
 	doc := bson.M{}
 	valueType := smap.valueType
 	for key, v := range smap.data {
@@ -637,7 +1113,13 @@ func (smap *stringSimpleMap) ToDocument() bson.M {
 }
 
 func (smap *stringSimpleMap) LoadDocument(document bson.M) error {
+	smap.checkReleased()
 	smap.Reset()
+	smap.lazy.clear()
+	return smap.fillDocument(document)
+}
+
+func (smap *stringSimpleMap) fillDocument(document bson.M) error {
 	data := smap.data
 	for k := range data {
 		delete(data, k)
@@ -653,14 +1135,36 @@ func (smap *stringSimpleMap) LoadDocument(document bson.M) error {
 	return nil
 }
 
+func (smap *stringSimpleMap) LoadRaw(raw bson.Raw) error {
+	smap.checkReleased()
+	smap.Reset()
+	smap.lazy.clear()
+	return smap.fillRaw(raw)
+}
+
+func (smap *stringSimpleMap) fillRaw(raw bson.Raw) error {
+	data := smap.data
+	for k := range data {
+		delete(data, k)
+	}
+	valueType := smap.valueType
+	return rangeRaw(raw, func(key string, value bson.RawValue) error {
+		v, err := valueType.ParseRaw(value)
+		if err != nil {
+			return err
+		}
+		data[key] = v
+		return nil
+	})
+}
+
 func (smap *stringSimpleMap) ToSync() interface{} {
 	sync := make(map[string]interface{})
 	updatedKeys := smap.updatedKeys
 	data := smap.data
-	if updatedKeys.Cardinality() > 0 {
+	if updatedKeys.Len() > 0 {
 		valueType := smap.valueType
-		for _, uk := range updatedKeys.ToSlice() {
-			key := uk.(string)
+		for key := range updatedKeys {
 			value := data[key]
 			sync[key] = valueType.ToData(value)
 		}
@@ -671,9 +1175,8 @@ func (smap *stringSimpleMap) ToSync() interface{} {
 func (smap *stringSimpleMap) ToDelete() interface{} {
 	delete := make(map[string]int)
 	removedKeys := smap.removedKeys
-	if removedKeys.Cardinality() > 0 {
-		for _, uk := range removedKeys.ToSlice() {
-			key := uk.(string)
+	if removedKeys.Len() > 0 {
+		for key := range removedKeys {
 			delete[key] = 1
 		}
 	}
@@ -681,23 +1184,146 @@ func (smap *stringSimpleMap) ToDelete() interface{} {
 }
 
 func (smap *stringSimpleMap) ToDataJson() (string, error) {
-	return jsoniter.MarshalToString(smap.ToData())
+	return DataJsonOf(smap)
 }
 
 func (smap *stringSimpleMap) ToSyncJson() (string, error) {
-	return jsoniter.MarshalToString(smap.ToSync())
+	return SyncJsonOf(smap)
+}
+
+func (smap *stringSimpleMap) EncodeData(stream *jsoniter.Stream) {
+	smap.load()
+	object := BeginJsonObject(stream)
+	valueType := smap.valueType
+	for key, value := range smap.data {
+		object.Field(key)
+		stream.WriteVal(valueType.ToData(value))
+	}
+	object.End()
+}
+
+func (smap *stringSimpleMap) EncodeSync(stream *jsoniter.Stream) {
+	object := BeginJsonObject(stream)
+	data := smap.data
+	valueType := smap.valueType
+	for key := range smap.updatedKeys {
+		object.Field(key)
+		stream.WriteVal(valueType.ToData(data[key]))
+	}
+	object.End()
+}
+
+func (smap *stringSimpleMap) WriteDataJson(w io.Writer) error {
+	return WriteDataJson(w, smap)
+}
+
+func (smap *stringSimpleMap) WriteSyncJson(w io.Writer) error {
+	return WriteSyncJson(w, smap)
 }
 
 func (smap *stringSimpleMap) ToDeleteJson() (string, error) {
 	return jsoniter.MarshalToString(smap.ToDelete())
 }
 
+func (smap *stringSimpleMap) ToMergePatch() interface{} {
+	return MergePatchOf(smap)
+}
+
+func (smap *stringSimpleMap) ToJsonPatch() []JsonPatchOperation {
+	return JsonPatchOf(smap)
+}
+
+func (smap *stringSimpleMap) Changes() []Change {
+	return ChangesOf(smap)
+}
+
+func (smap *stringSimpleMap) GetField(name string) (interface{}, error) {
+	smap.load()
+	key := name
+	value, ok := smap.data[key]
+	if !ok {
+		return nil, NoSuchFieldError(name)
+	}
+	return value, nil
+}
+
+func (smap *stringSimpleMap) SetField(name string, value interface{}) error {
+	key := name
+	if value == nil {
+		smap.Remove(key)
+		return nil
+	}
+	v, err := smap.valueType.Parse(value)
+	if err != nil {
+		return err
+	}
+	smap.Put(key, v)
+	return nil
+}
+
+func (smap *stringSimpleMap) LoadDocumentLazily(document bson.M) {
+	smap.checkReleased()
+	smap.Reset()
+	data := smap.data
+	for k := range data {
+		delete(data, k)
+	}
+	smap.lazy.setDocument(document)
+}
+
+func (smap *stringSimpleMap) LoadRawLazily(raw bson.Raw) {
+	smap.checkReleased()
+	smap.Reset()
+	data := smap.data
+	for k := range data {
+		delete(data, k)
+	}
+	smap.lazy.setRaw(raw)
+}
+
+func (smap *stringSimpleMap) Loaded() bool {
+	return !smap.lazy.pending()
+}
+
+func (smap *stringSimpleMap) Load() error {
+	smap.load()
+	return smap.lazy.err
+}
+
+func (smap *stringSimpleMap) load() {
+	smap.checkReleased()
+	if smap.lazy.pending() {
+		smap.lazy.load(smap.fillDocument, smap.fillRaw)
+	}
+}
+
+func (smap *stringSimpleMap) Discard() {
+	smap.Reset()
+	smap.lazy.clear()
+	smap.keyPaths.clear()
+	data := smap.data
+	for k := range data {
+		delete(data, k)
+	}
+	if Debug {
+		smap.released = true
+	}
+}
+
+func (smap *stringSimpleMap) GetPath(xpath DotNotation) (interface{}, error) {
+	return GetPath(smap, xpath)
+}
+
+func (smap *stringSimpleMap) SetPath(xpath DotNotation, value interface{}) error {
+	return SetPath(smap, xpath, value)
+}
+
 func NewStringSimpleMapModel(parent BsonModel, name string, valueType SimpleValueType) StringSimpleMapModel {
 	mapModel := &stringSimpleMap{}
 	mapModel.parent = parent
 	mapModel.name = name
-	mapModel.updatedKeys = mapset.NewThreadUnsafeSet()
-	mapModel.removedKeys = mapset.NewThreadUnsafeSet()
+	mapModel.updatedKeys = make(stringKeySet)
+	mapModel.removedKeys = make(stringKeySet)
 	mapModel.valueType = valueType
 	mapModel.data = make(map[string]interface{})
 	return mapModel
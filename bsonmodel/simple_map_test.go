@@ -0,0 +1,84 @@
+package bsonmodel
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSimpleMapKeys(t *testing.T) {
+	imap := NewIntSimpleMapModel(nil, "i", intSimpleValueType)
+	imap.Put(3, 1)
+	imap.Put(1, 2)
+	keys := imap.Keys()
+	sort.Ints(keys)
+	expected := []int{1, 3}
+	if !reflect.DeepEqual(expected, keys) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, keys)
+	}
+	smap := NewStringSimpleMapModel(nil, "s", stringSimpleValueType)
+	smap.Put("b", "1")
+	smap.Put("a", "2")
+	skeys := smap.Keys()
+	sort.Strings(skeys)
+	expectedStrings := []string{"a", "b"}
+	if !reflect.DeepEqual(expectedStrings, skeys) {
+		t.Errorf("The value expected <%v> but was <%v>", expectedStrings, skeys)
+	}
+}
+
+// stubRoot is the root which only provides the path for the maps under test.
+type stubRoot struct {
+	BsonModel
+}
+
+func (stubRoot) Parent() BsonModel {
+	return nil
+}
+
+func (stubRoot) XPath() DotNotation {
+	return RootPath()
+}
+
+func TestSimpleMapKeyPaths(t *testing.T) {
+	imap := NewIntSimpleMapModel(stubRoot{}, "i", intSimpleValueType).(*intSimpleMap)
+	imap.Put(1, 1)
+	imap.Put(2, 2)
+	imap.Reset()
+	imap.Remove(1)
+	imap.Put(2, 3)
+	expected := bson.M{"$set": bson.M{"i.2": 3}, "$unset": bson.M{"i.1": ""}}
+	update := imap.AppendUpdates(bson.M{})
+	if !reflect.DeepEqual(expected, update) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, update)
+	}
+	if imap.keyPaths.paths[1] != "i.1" {
+		t.Errorf("The value expected <%v> but was <%v>", "i.1", imap.keyPaths.paths[1])
+	}
+	imap.Reset()
+	if _, ok := imap.keyPaths.paths[1]; ok {
+		t.Error("The value expected false but was true")
+	}
+	if imap.keyPaths.paths[2] != "i.2" {
+		t.Errorf("The value expected <%v> but was <%v>", "i.2", imap.keyPaths.paths[2])
+	}
+
+	smap := NewStringSimpleMapModel(stubRoot{}, "s", stringSimpleValueType).(*stringSimpleMap)
+	smap.Put("a", "1")
+	smap.Reset()
+	smap.Clear()
+	expected = bson.M{"$unset": bson.M{"s.a": ""}}
+	update = smap.AppendUpdates(bson.M{})
+	if !reflect.DeepEqual(expected, update) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, update)
+	}
+	if smap.keyPaths.paths["a"] != "s.a" {
+		t.Errorf("The value expected <%v> but was <%v>", "s.a", smap.keyPaths.paths["a"])
+	}
+	smap.Reset()
+	if len(smap.keyPaths.paths) != 0 {
+		t.Errorf("The value expected <%v> but was <%v>", 0, len(smap.keyPaths.paths))
+	}
+}
@@ -0,0 +1,137 @@
+package bsonmodel
+
+import (
+	jsoniter "github.com/json-iterator/go"
+)
+
+// SyncReplaceKey is the key marks a fully replaced object in sync payloads.
+const SyncReplaceKey = "$replace"
+
+// FullySync returns the sync value of the fully updated model, which is
+// marked with {"$replace": true} when the sync replace marker is enabled on
+// the root model which the model belongs to.
+//
+// The marker is set by RootModel.SetSyncReplaceMarker(), so that clients can
+// replace the fully updated objects, such as new values put into object maps,
+// instead of merging into the stale ones. It is disabled by default.
+func FullySync(model ObjectModel) interface{} {
+	for m := BsonModel(model); m != nil; m = m.Parent() {
+		if root, ok := m.(RootModel); ok {
+			if root.SyncReplaceMarker() {
+				return &replacedModel{model: model}
+			}
+			break
+		}
+	}
+	return model
+}
+
+type replacedModel struct {
+	model ObjectModel
+}
+
+func (m *replacedModel) MarshalJSON() ([]byte, error) {
+	b, err := jsoniter.Marshal(m.model)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 2 || b[0] != '{' {
+		return b, nil
+	}
+	marker := `{"` + SyncReplaceKey + `":true`
+	if len(b) == 2 {
+		return []byte(marker + "}"), nil
+	}
+	return append([]byte(marker+","), b[1:]...), nil
+}
+
+// ApplySync applies the sync payload, in the same structure as ToSync(), onto
+// the client side state, which is in the same structure as
+// MarshalToJsonString(). Objects marked with SyncReplaceKey replace the
+// current ones instead of being merged into them.
+func ApplySync(state map[string]interface{}, sync map[string]interface{}) {
+	for key, value := range sync {
+		if v, ok := value.(map[string]interface{}); ok {
+			if v[SyncReplaceKey] == true {
+				replaced := make(map[string]interface{}, len(v))
+				for k, fv := range v {
+					if k != SyncReplaceKey {
+						replaced[k] = fv
+					}
+				}
+				state[key] = replaced
+				continue
+			}
+			if s, ok := state[key].(map[string]interface{}); ok {
+				ApplySync(s, v)
+				continue
+			}
+		}
+		state[key] = value
+	}
+}
+
+// ApplyDelete applies the delete payload, in the same structure as ToDelete(),
+// onto the client side state of the model with the schema, which is in the
+// same structure as MarshalToJsonString().
+//
+// Removed lists are set to null and removed map entries are deleted, which is
+// decided by the schema rather than the current state, so an absent or null
+// list is also handled.
+func ApplyDelete(schema *ModelSchema, state map[string]interface{}, del map[string]interface{}) {
+	for key, value := range del {
+		field := schema.Field(key)
+		if field == nil {
+			continue
+		}
+		switch field.Type {
+		case FieldTypeObject:
+			v, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if s, ok := state[key].(map[string]interface{}); ok {
+				ApplyDelete(field.Model, s, v)
+			}
+		case FieldTypeMap, FieldTypeSimpleMap:
+			v, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if s, ok := state[key].(map[string]interface{}); ok {
+				for k := range v {
+					delete(s, k)
+				}
+			}
+		case FieldTypeSimpleList:
+			// removed list is encoded as null
+			state[key] = nil
+		default:
+			delete(state, key)
+		}
+	}
+}
+
+// ApplySyncJson applies the sync JSON, as returned by ToSyncJson(), onto the
+// client side state.
+func ApplySyncJson(state map[string]interface{}, json string) error {
+	sync := make(map[string]interface{})
+	err := jsoniter.UnmarshalFromString(json, &sync)
+	if err != nil {
+		return err
+	}
+	ApplySync(state, sync)
+	return nil
+}
+
+// ApplyDeleteJson applies the delete JSON, as returned by ToDeleteJson(), onto
+// the client side state of the model with the schema.
+func ApplyDeleteJson(schema *ModelSchema, state map[string]interface{}, json string) error {
+	del := make(map[string]interface{})
+	err := jsoniter.UnmarshalFromString(json, &del)
+	if err != nil {
+		return err
+	}
+	ApplyDelete(schema, state, del)
+	return nil
+}
@@ -0,0 +1,104 @@
+package bsonmodel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplySync(t *testing.T) {
+	state := map[string]interface{}{
+		"a": 1.0,
+		"b": map[string]interface{}{"x": 1.0, "y": 2.0},
+		"c": []interface{}{1.0},
+	}
+	sync := map[string]interface{}{
+		"a": 2.0,
+		"b": map[string]interface{}{"y": 3.0, "z": map[string]interface{}{"k": "v"}},
+		"c": []interface{}{2.0, 3.0},
+	}
+	ApplySync(state, sync)
+	expected := map[string]interface{}{
+		"a": 2.0,
+		"b": map[string]interface{}{"x": 1.0, "y": 3.0, "z": map[string]interface{}{"k": "v"}},
+		"c": []interface{}{2.0, 3.0},
+	}
+	if !reflect.DeepEqual(expected, state) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, state)
+	}
+}
+
+var syncTestSchema = &ModelSchema{
+	Name: "Test",
+	Type: ModelTypeRoot,
+	Fields: []*FieldSchema{
+		{Name: "a", Bname: "a", Type: FieldTypeSimpleMap, KeyType: "int", ValueType: "int"},
+		{Name: "b", Bname: "b", Type: FieldTypeObject, Model: &ModelSchema{
+			Name: "Nested",
+			Type: ModelTypeObject,
+			Fields: []*FieldSchema{
+				{Name: "m", Bname: "m", Type: FieldTypeSimpleMap, KeyType: "string", ValueType: "int"},
+				{Name: "l", Bname: "l", Type: FieldTypeSimpleList, ValueType: "int"},
+			},
+		}},
+		{Name: "c", Bname: "c", Type: FieldTypeSimpleList, ValueType: "int"},
+	},
+}
+
+func TestApplyDelete(t *testing.T) {
+	state := map[string]interface{}{
+		"a": map[string]interface{}{"1": 1.0, "2": 2.0},
+		"b": map[string]interface{}{"m": map[string]interface{}{"x": 1.0}, "l": []interface{}{1.0}},
+		"c": nil,
+	}
+	del := map[string]interface{}{
+		"a": map[string]interface{}{"1": 1.0, "no": 1.0},
+		"b": map[string]interface{}{"m": map[string]interface{}{"x": 1.0}, "l": 1.0},
+		"c": 1.0,
+		"d": map[string]interface{}{"x": 1.0},
+	}
+	ApplyDelete(syncTestSchema, state, del)
+	expected := map[string]interface{}{
+		"a": map[string]interface{}{"2": 2.0},
+		"b": map[string]interface{}{"m": map[string]interface{}{}, "l": nil},
+		"c": nil,
+	}
+	if !reflect.DeepEqual(expected, state) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, state)
+	}
+}
+
+func TestApplySyncJson(t *testing.T) {
+	state := map[string]interface{}{}
+	err := ApplySyncJson(state, `{"a":{"1":2}}`)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	err = ApplyDeleteJson(syncTestSchema, state, `{"a":{"1":1}}`)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	expected := map[string]interface{}{"a": map[string]interface{}{}}
+	if !reflect.DeepEqual(expected, state) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, state)
+	}
+	err = ApplySyncJson(state, `[]`)
+	if err == nil {
+		t.Error("The error expected not be nil")
+	}
+}
+
+func TestApplySyncReplace(t *testing.T) {
+	state := map[string]interface{}{
+		"b": map[string]interface{}{"x": 1.0, "y": 2.0},
+	}
+	sync := map[string]interface{}{
+		"b": map[string]interface{}{SyncReplaceKey: true, "y": 3.0},
+	}
+	ApplySync(state, sync)
+	expected := map[string]interface{}{
+		"b": map[string]interface{}{"y": 3.0},
+	}
+	if !reflect.DeepEqual(expected, state) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, state)
+	}
+}
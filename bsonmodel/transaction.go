@@ -0,0 +1,101 @@
+package bsonmodel
+
+import "errors"
+
+// Transaction records the states of the models changed after Begin(), so
+// that all changes can be rolled back.
+//
+// The states are saved lazily before the first change of each model, or
+// each key of a map, so the cost scales with what was modified rather than
+// the size of the document.
+//
+// The zero value is ready to use.
+type Transaction struct {
+	active   bool
+	saved    map[interface{}]struct{}
+	restores []func()
+}
+
+// Active returns whether the transaction is active.
+func (tx *Transaction) Active() bool {
+	return tx.active
+}
+
+// Begin begins the transaction.
+func (tx *Transaction) Begin() error {
+	if tx.active {
+		return errors.New("The transaction is already active")
+	}
+	tx.active = true
+	tx.saved = make(map[interface{}]struct{})
+	return nil
+}
+
+// Commit commits the transaction and keeps all changes.
+func (tx *Transaction) Commit() error {
+	if !tx.active {
+		return errors.New("The transaction is not active")
+	}
+	tx.end()
+	return nil
+}
+
+// Rollback restores all models to the states when the transaction began,
+// including the updated fields and the updated/removed keys of maps.
+//
+// No change events will be emitted when restoring.
+func (tx *Transaction) Rollback() error {
+	if !tx.active {
+		return errors.New("The transaction is not active")
+	}
+	restores := tx.restores
+	tx.end()
+	for i := len(restores) - 1; i >= 0; i-- {
+		restores[i]()
+	}
+	return nil
+}
+
+func (tx *Transaction) end() {
+	tx.active = false
+	tx.saved = nil
+	tx.restores = nil
+}
+
+func (tx *Transaction) save(key interface{}, snapshot func() func()) {
+	if _, ok := tx.saved[key]; ok {
+		return
+	}
+	tx.saved[key] = struct{}{}
+	tx.restores = append(tx.restores, snapshot())
+}
+
+// TransactionOf returns the active transaction of the root model which the
+// model belongs to, or nil if there is no active transaction.
+func TransactionOf(model BsonModel) *Transaction {
+	for m := model; m != nil; m = m.Parent() {
+		if root, ok := m.(RootModel); ok {
+			tx := root.Transaction()
+			if tx.active {
+				return tx
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// SaveState saves the state of the model into the active transaction, if
+// any, before the model is changed.
+func SaveState(model ObjectModel) {
+	tx := TransactionOf(model)
+	if tx == nil {
+		return
+	}
+	tx.save(model, model.Snapshot)
+}
+
+type mapEntry struct {
+	model BsonModel
+	key   interface{}
+}
@@ -0,0 +1,44 @@
+package bsonmodel
+
+import (
+	"testing"
+)
+
+func TestTransaction(t *testing.T) {
+	tx := &Transaction{}
+	if tx.Commit() == nil {
+		t.Error("The error expected but was nil")
+	}
+	if tx.Rollback() == nil {
+		t.Error("The error expected but was nil")
+	}
+	err := tx.Begin()
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if !tx.Active() {
+		t.Error("The value expected true but was false")
+	}
+	var values []int
+	value := 0
+	for i := 1; i <= 3; i++ {
+		tx.save("value", func() func() {
+			saved := value
+			return func() {
+				values = append(values, saved)
+				value = saved
+			}
+		})
+		value = i
+	}
+	err = tx.Rollback()
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if tx.Active() {
+		t.Error("The value expected false but was true")
+	}
+	if value != 0 || len(values) != 1 {
+		t.Errorf("The value expected <%v> but was <%v>", 0, value)
+	}
+}
@@ -3,6 +3,7 @@ package bsonmodel
 import (
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"strconv"
 	"strings"
@@ -10,7 +11,9 @@ import (
 
 	jsoniter "github.com/json-iterator/go"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 )
 
 // DotNotation defines BSON dot notation.
@@ -45,6 +48,21 @@ func (xpath *path) ResolveIndex(index int) DotNotation {
 	return xpath.Resolve(name)
 }
 
+// JsonPointerOf returns the JSON Pointer (RFC 6901) form of the path.
+func JsonPointerOf(xpath DotNotation) string {
+	if xpath.IsRoot() {
+		return ""
+	}
+	var builder strings.Builder
+	for _, name := range strings.Split(xpath.Value(), ".") {
+		builder.WriteString("/")
+		builder.WriteString(jsonPointerEscaper.Replace(name))
+	}
+	return builder.String()
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
 func (xpath *path) String() string {
 	return xpath.value
 }
@@ -72,6 +90,41 @@ func PathOf(base DotNotation, names ...string) DotNotation {
 	return PathOfNames(base.Value(), names...)
 }
 
+// PathCache caches the path of a model resolved from its parent path, so
+// that the full path is only rebuilt when the parent path changes.
+//
+// The parent path is compared by identity, which is stable as long as the
+// parent itself returns a static or cached path.
+type PathCache struct {
+	parentPath DotNotation
+	xpath      DotNotation
+}
+
+// Resolve returns the path of the name under the parent path.
+func (c *PathCache) Resolve(parentPath DotNotation, name string) DotNotation {
+	if c.xpath == nil || c.parentPath != parentPath {
+		c.parentPath = parentPath
+		c.xpath = parentPath.Resolve(name)
+	}
+	return c.xpath
+}
+
+// ResolveIndex returns the path of the index under the parent path, the
+// index is only converted to string when the cache is rebuilt.
+func (c *PathCache) ResolveIndex(parentPath DotNotation, index int) DotNotation {
+	if c.xpath == nil || c.parentPath != parentPath {
+		c.parentPath = parentPath
+		c.xpath = parentPath.ResolveIndex(index)
+	}
+	return c.xpath
+}
+
+// Invalidate drops the cached path.
+func (c *PathCache) Invalidate() {
+	c.parentPath = nil
+	c.xpath = nil
+}
+
 func valueTypeName(valueType jsoniter.ValueType) string {
 	switch valueType {
 	case jsoniter.InvalidValue:
@@ -105,6 +158,50 @@ func DateToNumber(date time.Time) int {
 	return year*10000 + int(month)*100 + day
 }
 
+// IntSliceEquals returns whether the two slices have the same elements, a nil
+// slice is not equal to an empty one.
+func IntSliceEquals(a []int, b []int) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if v != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// StringSliceEquals returns whether the two slices have the same elements, a
+// nil slice is not equal to an empty one.
+func StringSliceEquals(a []string, b []string) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if v != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// CopyIntSlice returns a copy of the slice, or nil if the slice is nil.
+func CopyIntSlice(s []int) []int {
+	if s == nil {
+		return nil
+	}
+	return append(make([]int, 0, len(s)), s...)
+}
+
+// CopyStringSlice returns a copy of the slice, or nil if the slice is nil.
+func CopyStringSlice(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(s)), s...)
+}
+
 func FixedEmbedded(m bson.M, name string) bson.M {
 	doc, ok := m[name].(bson.M)
 	if !ok {
@@ -119,6 +216,15 @@ func IntValue(m bson.M, name string, def int) (int, error) {
 	if v == nil {
 		return def, nil
 	}
+	i, err := ParseInt(v)
+	if err != nil {
+		return def, err
+	}
+	return i, nil
+}
+
+// ParseInt converts the BSON value to int.
+func ParseInt(v interface{}) (int, error) {
 	switch v.(type) {
 	case int32:
 		return int(v.(int32)), nil
@@ -129,7 +235,7 @@ func IntValue(m bson.M, name string, def int) (int, error) {
 	case int:
 		return v.(int), nil
 	default:
-		return def, errors.New(fmt.Sprintf("Type %v can not be cast to type int", reflect.TypeOf(v)))
+		return 0, errors.New(fmt.Sprintf("Type %v can not be cast to type int", reflect.TypeOf(v)))
 	}
 }
 
@@ -138,6 +244,15 @@ func Float64Value(m bson.M, name string, def float64) (float64, error) {
 	if v == nil {
 		return def, nil
 	}
+	f, err := ParseFloat64(v)
+	if err != nil {
+		return def, err
+	}
+	return f, nil
+}
+
+// ParseFloat64 converts the BSON value to float64.
+func ParseFloat64(v interface{}) (float64, error) {
 	switch v.(type) {
 	case int32:
 		return float64(v.(int32)), nil
@@ -148,7 +263,7 @@ func Float64Value(m bson.M, name string, def float64) (float64, error) {
 	case int:
 		return float64(v.(int)), nil
 	default:
-		return def, errors.New(fmt.Sprintf("Type %v can not be cast to type int", reflect.TypeOf(v)))
+		return 0, errors.New(fmt.Sprintf("Type %v can not be cast to type float64", reflect.TypeOf(v)))
 	}
 }
 
@@ -157,11 +272,20 @@ func StringValue(m bson.M, name string, def string) (string, error) {
 	if v == nil {
 		return def, nil
 	}
+	s, err := ParseString(v)
+	if err != nil {
+		return def, err
+	}
+	return s, nil
+}
+
+// ParseString converts the BSON value to string.
+func ParseString(v interface{}) (string, error) {
 	switch v.(type) {
 	case string:
 		return v.(string), nil
 	default:
-		return def, errors.New(fmt.Sprintf("Type %v can not be cast to type string", reflect.TypeOf(v)))
+		return "", errors.New(fmt.Sprintf("Type %v can not be cast to type string", reflect.TypeOf(v)))
 	}
 }
 
@@ -170,11 +294,18 @@ func DateTimeValue(m bson.M, name string) (t time.Time, err error) {
 	if v == nil {
 		return
 	}
+	return ParseDateTime(v)
+}
+
+// ParseDateTime converts the BSON value, or a time.Time, to time.Time.
+func ParseDateTime(v interface{}) (t time.Time, err error) {
 	switch v.(type) {
 	case primitive.DateTime:
 		t = v.(primitive.DateTime).Time()
 	case primitive.Timestamp:
 		t = time.Unix(int64(v.(primitive.Timestamp).T), 0)
+	case time.Time:
+		t = v.(time.Time)
 	default:
 		err = errors.New(fmt.Sprintf("Type %v can not be cast to type time.Time", reflect.TypeOf(v)))
 	}
@@ -186,6 +317,12 @@ func DateValue(m bson.M, name string) (t time.Time, err error) {
 	if v == nil {
 		return
 	}
+	return ParseDate(v)
+}
+
+// ParseDate converts the BSON value, which is a number like 20210101, or a
+// time.Time, to time.Time.
+func ParseDate(v interface{}) (t time.Time, err error) {
 	switch v.(type) {
 	case int32:
 		t = NumberToDate(int(v.(int32)))
@@ -195,6 +332,9 @@ func DateValue(m bson.M, name string) (t time.Time, err error) {
 		t = NumberToDate(int(v.(float64)))
 	case int:
 		t = NumberToDate(v.(int))
+	case time.Time:
+		year, month, day := v.(time.Time).Date()
+		t = time.Date(year, month, day, 0, 0, 0, 0, time.Local)
 	default:
 		err = errors.New(fmt.Sprintf("Type %v can not be cast to type int", reflect.TypeOf(v)))
 	}
@@ -202,7 +342,11 @@ func DateValue(m bson.M, name string) (t time.Time, err error) {
 }
 
 func EmbeddedValue(m bson.M, name string) (bson.M, error) {
-	v := m[name]
+	return ParseEmbedded(m[name])
+}
+
+// ParseEmbedded converts the BSON value to an embedded document.
+func ParseEmbedded(v interface{}) (bson.M, error) {
 	if v == nil {
 		return nil, nil
 	}
@@ -215,11 +359,19 @@ func EmbeddedValue(m bson.M, name string) (bson.M, error) {
 }
 
 func IntArrayValue(m bson.M, name string) ([]int, error) {
-	v := m[name]
+	return ParseIntArray(m[name])
+}
+
+// ParseIntArray converts the BSON array, or an []int, to []int.
+func ParseIntArray(v interface{}) ([]int, error) {
 	if v == nil {
 		return nil, nil
 	}
 	switch v.(type) {
+	case []int:
+		return v.([]int), nil
+	case []interface{}:
+		return ParseIntArray(bson.A(v.([]interface{})))
 	case bson.A:
 		a := v.(bson.A)
 		arr := make([]int, 0, len(a))
@@ -245,11 +397,19 @@ func IntArrayValue(m bson.M, name string) ([]int, error) {
 }
 
 func StringArrayValue(m bson.M, name string) ([]string, error) {
-	v := m[name]
+	return ParseStringArray(m[name])
+}
+
+// ParseStringArray converts the BSON array, or a []string, to []string.
+func ParseStringArray(v interface{}) ([]string, error) {
 	if v == nil {
 		return nil, nil
 	}
 	switch v.(type) {
+	case []string:
+		return v.([]string), nil
+	case []interface{}:
+		return ParseStringArray(bson.A(v.([]interface{})))
 	case bson.A:
 		a := v.(bson.A)
 		arr := make([]string, 0, len(a))
@@ -378,3 +538,363 @@ func AnyStringArrayValue(any jsoniter.Any) ([]string, error) {
 		return nil, errors.New(fmt.Sprintf("The value is not an ARRAY (%s)", valueTypeName(any.ValueType())))
 	}
 }
+
+// IterError returns the error of the iterator, or nil if there is no error
+// or the input reaches the end.
+func IterError(iter *jsoniter.Iterator) error {
+	if iter.Error == nil || iter.Error == io.EOF {
+		return nil
+	}
+	return iter.Error
+}
+
+func IterIntValue(iter *jsoniter.Iterator, def int) (int, error) {
+	switch valueType := iter.WhatIsNext(); valueType {
+	case jsoniter.NilValue:
+		iter.Skip()
+		return def, IterError(iter)
+	case jsoniter.NumberValue:
+		i := iter.ReadInt()
+		return i, IterError(iter)
+	default:
+		iter.Skip()
+		return def, errors.New(fmt.Sprintf("The value is not a NUMBER (%s)", valueTypeName(valueType)))
+	}
+}
+
+func IterFloat64Value(iter *jsoniter.Iterator, def float64) (float64, error) {
+	switch valueType := iter.WhatIsNext(); valueType {
+	case jsoniter.NilValue:
+		iter.Skip()
+		return def, IterError(iter)
+	case jsoniter.NumberValue:
+		f := iter.ReadFloat64()
+		return f, IterError(iter)
+	default:
+		iter.Skip()
+		return def, errors.New(fmt.Sprintf("The value is not a NUMBER (%s)", valueTypeName(valueType)))
+	}
+}
+
+func IterStringValue(iter *jsoniter.Iterator, def string) (string, error) {
+	switch valueType := iter.WhatIsNext(); valueType {
+	case jsoniter.NilValue:
+		iter.Skip()
+		return def, IterError(iter)
+	case jsoniter.StringValue:
+		s := iter.ReadString()
+		return s, IterError(iter)
+	default:
+		iter.Skip()
+		return def, errors.New(fmt.Sprintf("The value is not a STRING (%s)", valueTypeName(valueType)))
+	}
+}
+
+func IterDateTimeValue(iter *jsoniter.Iterator) (t time.Time, err error) {
+	switch valueType := iter.WhatIsNext(); valueType {
+	case jsoniter.NilValue:
+		iter.Skip()
+		err = IterError(iter)
+	case jsoniter.NumberValue:
+		t = time.UnixMilli(iter.ReadInt64())
+		err = IterError(iter)
+	default:
+		iter.Skip()
+		err = errors.New(fmt.Sprintf("The value is not a NUMBER (%s)", valueTypeName(valueType)))
+	}
+	return
+}
+
+// IterUnixValue reads the time encoded in seconds, as the datetime fields
+// are written by MarshalJSON().
+func IterUnixValue(iter *jsoniter.Iterator) (t time.Time, err error) {
+	switch valueType := iter.WhatIsNext(); valueType {
+	case jsoniter.NilValue:
+		iter.Skip()
+		err = IterError(iter)
+	case jsoniter.NumberValue:
+		t = time.Unix(iter.ReadInt64(), 0)
+		err = IterError(iter)
+	default:
+		iter.Skip()
+		err = errors.New(fmt.Sprintf("The value is not a NUMBER (%s)", valueTypeName(valueType)))
+	}
+	return
+}
+
+func IterDateValue(iter *jsoniter.Iterator) (t time.Time, err error) {
+	switch valueType := iter.WhatIsNext(); valueType {
+	case jsoniter.NilValue:
+		iter.Skip()
+		err = IterError(iter)
+	case jsoniter.NumberValue:
+		t = NumberToDate(iter.ReadInt())
+		err = IterError(iter)
+	default:
+		iter.Skip()
+		err = errors.New(fmt.Sprintf("The value is not a NUMBER (%s)", valueTypeName(valueType)))
+	}
+	return
+}
+
+func IterIntArrayValue(iter *jsoniter.Iterator) ([]int, error) {
+	switch valueType := iter.WhatIsNext(); valueType {
+	case jsoniter.NilValue:
+		iter.Skip()
+		return nil, IterError(iter)
+	case jsoniter.ArrayValue:
+		array := make([]int, 0)
+		var err error
+		iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+			var val int
+			val, err = IterIntValue(iter, 0)
+			array = append(array, val)
+			return err == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return array, IterError(iter)
+	default:
+		iter.Skip()
+		return nil, errors.New(fmt.Sprintf("The value is not an ARRAY (%s)", valueTypeName(valueType)))
+	}
+}
+
+func IterStringArrayValue(iter *jsoniter.Iterator) ([]string, error) {
+	switch valueType := iter.WhatIsNext(); valueType {
+	case jsoniter.NilValue:
+		iter.Skip()
+		return nil, IterError(iter)
+	case jsoniter.ArrayValue:
+		array := make([]string, 0)
+		var err error
+		iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+			var val string
+			val, err = IterStringValue(iter, "")
+			array = append(array, val)
+			return err == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return array, IterError(iter)
+	default:
+		iter.Skip()
+		return nil, errors.New(fmt.Sprintf("The value is not an ARRAY (%s)", valueTypeName(valueType)))
+	}
+}
+
+func lookupRaw(raw bson.Raw, name string) (bson.RawValue, error) {
+	value, err := raw.LookupErr(name)
+	if err == bsoncore.ErrElementNotFound {
+		return bson.RawValue{}, nil
+	}
+	return value, err
+}
+
+func isRawNil(value bson.RawValue) bool {
+	return value.Type == 0 || value.Type == bsontype.Null || value.Type == bsontype.Undefined
+}
+
+// rangeRaw calls the function for each element of the raw document, or the
+// raw array, without decoding it.
+func rangeRaw(raw bson.Raw, fn func(key string, value bson.RawValue) error) error {
+	length, rem, ok := bsoncore.ReadLength(raw)
+	if !ok || length < 5 || int(length) > len(raw) {
+		return bsoncore.NewInsufficientBytesError(raw, rem)
+	}
+	rem = raw[4 : length-1]
+	for len(rem) > 0 {
+		element, next, ok := bsoncore.ReadElement(rem)
+		if !ok {
+			return bsoncore.NewInsufficientBytesError(raw, rem)
+		}
+		value, err := element.ValueErr()
+		if err != nil {
+			return err
+		}
+		err = fn(element.Key(), bson.RawValue{Type: value.Type, Value: value.Data})
+		if err != nil {
+			return err
+		}
+		rem = next
+	}
+	return nil
+}
+
+func RawIntValue(raw bson.Raw, name string, def int) (int, error) {
+	v, err := lookupRaw(raw, name)
+	if err != nil || isRawNil(v) {
+		return def, err
+	}
+	i, err := ParseRawInt(v)
+	if err != nil {
+		return def, err
+	}
+	return i, nil
+}
+
+// ParseRawInt converts the raw BSON value to int.
+func ParseRawInt(v bson.RawValue) (int, error) {
+	switch v.Type {
+	case bsontype.Int32:
+		return int(v.Int32()), nil
+	case bsontype.Int64:
+		return int(v.Int64()), nil
+	case bsontype.Double:
+		return int(v.Double()), nil
+	default:
+		return 0, errors.New(fmt.Sprintf("Type %v can not be cast to type int", v.Type))
+	}
+}
+
+func RawFloat64Value(raw bson.Raw, name string, def float64) (float64, error) {
+	v, err := lookupRaw(raw, name)
+	if err != nil || isRawNil(v) {
+		return def, err
+	}
+	f, err := ParseRawFloat64(v)
+	if err != nil {
+		return def, err
+	}
+	return f, nil
+}
+
+// ParseRawFloat64 converts the raw BSON value to float64.
+func ParseRawFloat64(v bson.RawValue) (float64, error) {
+	switch v.Type {
+	case bsontype.Int32:
+		return float64(v.Int32()), nil
+	case bsontype.Int64:
+		return float64(v.Int64()), nil
+	case bsontype.Double:
+		return v.Double(), nil
+	default:
+		return 0, errors.New(fmt.Sprintf("Type %v can not be cast to type float64", v.Type))
+	}
+}
+
+func RawStringValue(raw bson.Raw, name string, def string) (string, error) {
+	v, err := lookupRaw(raw, name)
+	if err != nil || isRawNil(v) {
+		return def, err
+	}
+	s, err := ParseRawString(v)
+	if err != nil {
+		return def, err
+	}
+	return s, nil
+}
+
+// ParseRawString converts the raw BSON value to string.
+func ParseRawString(v bson.RawValue) (string, error) {
+	switch v.Type {
+	case bsontype.String:
+		return v.StringValue(), nil
+	default:
+		return "", errors.New(fmt.Sprintf("Type %v can not be cast to type string", v.Type))
+	}
+}
+
+func RawDateTimeValue(raw bson.Raw, name string) (t time.Time, err error) {
+	v, err := lookupRaw(raw, name)
+	if err != nil || isRawNil(v) {
+		return
+	}
+	return ParseRawDateTime(v)
+}
+
+// ParseRawDateTime converts the raw BSON value to time.Time.
+func ParseRawDateTime(v bson.RawValue) (t time.Time, err error) {
+	switch v.Type {
+	case bsontype.DateTime:
+		t = primitive.DateTime(v.DateTime()).Time()
+	case bsontype.Timestamp:
+		sec, _ := v.Timestamp()
+		t = time.Unix(int64(sec), 0)
+	default:
+		err = errors.New(fmt.Sprintf("Type %v can not be cast to type time.Time", v.Type))
+	}
+	return
+}
+
+func RawDateValue(raw bson.Raw, name string) (t time.Time, err error) {
+	v, err := lookupRaw(raw, name)
+	if err != nil || isRawNil(v) {
+		return
+	}
+	return ParseRawDate(v)
+}
+
+// ParseRawDate converts the raw BSON value, which is a number like 20210101,
+// to time.Time.
+func ParseRawDate(v bson.RawValue) (t time.Time, err error) {
+	switch v.Type {
+	case bsontype.Int32, bsontype.Int64, bsontype.Double:
+		num, _ := ParseRawInt(v)
+		t = NumberToDate(num)
+	default:
+		err = errors.New(fmt.Sprintf("Type %v can not be cast to type int", v.Type))
+	}
+	return
+}
+
+func RawEmbeddedValue(raw bson.Raw, name string) (bson.Raw, error) {
+	v, err := lookupRaw(raw, name)
+	if err != nil || isRawNil(v) {
+		return nil, err
+	}
+	switch v.Type {
+	case bsontype.EmbeddedDocument:
+		return v.Document(), nil
+	default:
+		return nil, errors.New(fmt.Sprintf("Type %v can not be cast to type bson.Raw", v.Type))
+	}
+}
+
+func RawIntArrayValue(raw bson.Raw, name string) ([]int, error) {
+	v, err := lookupRaw(raw, name)
+	if err != nil || isRawNil(v) {
+		return nil, err
+	}
+	if v.Type != bsontype.Array {
+		return nil, errors.New(fmt.Sprintf("Type %v can not be cast to type bson.A", v.Type))
+	}
+	array := make([]int, 0)
+	err = rangeRaw(v.Array(), func(_ string, value bson.RawValue) error {
+		i, err := ParseRawInt(value)
+		if err != nil {
+			return err
+		}
+		array = append(array, i)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return array, nil
+}
+
+func RawStringArrayValue(raw bson.Raw, name string) ([]string, error) {
+	v, err := lookupRaw(raw, name)
+	if err != nil || isRawNil(v) {
+		return nil, err
+	}
+	if v.Type != bsontype.Array {
+		return nil, errors.New(fmt.Sprintf("Type %v can not be cast to type bson.A", v.Type))
+	}
+	array := make([]string, 0)
+	err = rangeRaw(v.Array(), func(_ string, value bson.RawValue) error {
+		s, err := ParseRawString(value)
+		if err != nil {
+			return err
+		}
+		array = append(array, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return array, nil
+}
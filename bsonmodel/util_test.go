@@ -55,6 +55,17 @@ func TestResolveIndex(t *testing.T) {
 	}
 }
 
+func TestJsonPointerOf(t *testing.T) {
+	xpath := RootPath()
+	if JsonPointerOf(xpath) != "" {
+		t.Errorf("The value expected \"\" but was \"%s\"", JsonPointerOf(xpath))
+	}
+	xpath = &path{"a.b/c.d~e"}
+	if JsonPointerOf(xpath) != "/a/b~1c/d~0e" {
+		t.Errorf("The value expected \"/a/b~1c/d~0e\" but was \"%s\"", JsonPointerOf(xpath))
+	}
+}
+
 func TestRootPath(t *testing.T) {
 	xpath := RootPath()
 	if xpath != root {
@@ -85,6 +96,28 @@ func TestPathOf(t *testing.T) {
 	}
 }
 
+func TestPathCache(t *testing.T) {
+	cache := &PathCache{}
+	var parent DotNotation = &path{"a"}
+	xpath := cache.Resolve(parent, "b")
+	if xpath.Value() != "a.b" {
+		t.Errorf("The value expected \"a.b\" but was \"%s\"", xpath.Value())
+	}
+	if cache.Resolve(parent, "b") != xpath {
+		t.Error("The value expected cached path but not")
+	}
+	parent = &path{"c"}
+	xpath = cache.Resolve(parent, "b")
+	if xpath.Value() != "c.b" {
+		t.Errorf("The value expected \"c.b\" but was \"%s\"", xpath.Value())
+	}
+	cache.Invalidate()
+	xpath = cache.ResolveIndex(parent, 1)
+	if xpath.Value() != "c.1" {
+		t.Errorf("The value expected \"c.1\" but was \"%s\"", xpath.Value())
+	}
+}
+
 func TestValueTypeName(t *testing.T) {
 	if valueTypeName(jsoniter.InvalidValue) != "INVALID" {
 		t.Errorf("The value expected \"INVALID\" nut was \"%s\"", valueTypeName(jsoniter.InvalidValue))
@@ -277,6 +310,44 @@ func TestDateValue(t *testing.T) {
 	}
 }
 
+func TestParseDate(t *testing.T) {
+	a, err := ParseDate(time.Date(2021, time.September, 18, 12, 30, 0, 0, time.Local))
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if DateToNumber(a) != 20210918 {
+		t.Errorf("The value expected %v but was %v", 20210918, DateToNumber(a))
+	}
+	if a.Hour() != 0 || a.Minute() != 0 {
+		t.Errorf("The value expected %v but was %v", "00:00", a.Format("15:04"))
+	}
+	_, err = ParseDate(nil)
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+}
+
+func TestParseIntArray(t *testing.T) {
+	a, err := ParseIntArray([]interface{}{int32(1), int64(2), 3})
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if !IntSliceEquals([]int{1, 2, 3}, a) {
+		t.Errorf("The value expected %v but was %v", []int{1, 2, 3}, a)
+	}
+	b, err := ParseIntArray([]int{4})
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if !IntSliceEquals([]int{4}, b) {
+		t.Errorf("The value expected %v but was %v", []int{4}, b)
+	}
+	_, err = ParseIntArray("str")
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+}
+
 func TestEmbeddedValue(t *testing.T) {
 	m := bson.M{"a": bson.M{}, "b": "str"}
 	a, err := EmbeddedValue(m, "a")
@@ -355,3 +426,154 @@ func TestStringArrayValue(t *testing.T) {
 		t.Errorf("The value expected %v but was %v", nil, d)
 	}
 }
+
+func TestSliceEquals(t *testing.T) {
+	if !IntSliceEquals([]int{1, 2}, []int{1, 2}) {
+		t.Error("The value expected true but was false")
+	}
+	if IntSliceEquals([]int{1, 2}, []int{2, 1}) {
+		t.Error("The value expected false but was true")
+	}
+	if IntSliceEquals(nil, []int{}) {
+		t.Error("The value expected false but was true")
+	}
+	if !StringSliceEquals(nil, nil) {
+		t.Error("The value expected true but was false")
+	}
+	if StringSliceEquals([]string{"a"}, []string{"a", "b"}) {
+		t.Error("The value expected false but was true")
+	}
+}
+
+func TestCopySlice(t *testing.T) {
+	if CopyIntSlice(nil) != nil {
+		t.Error("The value expected nil")
+	}
+	s := []string{"a", "b"}
+	c := CopyStringSlice(s)
+	c[0] = "c"
+	if s[0] != "a" {
+		t.Errorf("The value expected \"a\" but was \"%s\"", s[0])
+	}
+}
+
+func TestRawValue(t *testing.T) {
+	now := time.Now().Truncate(time.Millisecond)
+	raw, err := bson.Marshal(bson.M{"i": int64(123), "f": int32(2), "s": "str", "dt": now, "d": 20210918, "n": nil,
+		"e": bson.M{"a": 1}, "ia": bson.A{1, int64(2), 3.0}, "sa": bson.A{"a", "b"}})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	i, err := RawIntValue(raw, "i", 0)
+	if err != nil || i != 123 {
+		t.Errorf("The value expected %v but was %v (%v)", 123, i, err)
+	}
+	n, err := RawIntValue(raw, "n", 5)
+	if err != nil || n != 5 {
+		t.Errorf("The value expected %v but was %v (%v)", 5, n, err)
+	}
+	m, err := RawIntValue(raw, "m", 6)
+	if err != nil || m != 6 {
+		t.Errorf("The value expected %v but was %v (%v)", 6, m, err)
+	}
+	_, err = RawIntValue(raw, "s", 0)
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+	f, err := RawFloat64Value(raw, "f", 0)
+	if err != nil || f != 2.0 {
+		t.Errorf("The value expected %v but was %v (%v)", 2.0, f, err)
+	}
+	s, err := RawStringValue(raw, "s", "")
+	if err != nil || s != "str" {
+		t.Errorf("The value expected %v but was %v (%v)", "str", s, err)
+	}
+	dt, err := RawDateTimeValue(raw, "dt")
+	if err != nil || !dt.Equal(now) {
+		t.Errorf("The value expected %v but was %v (%v)", now, dt, err)
+	}
+	d, err := RawDateValue(raw, "d")
+	if err != nil || DateToNumber(d) != 20210918 {
+		t.Errorf("The value expected %v but was %v (%v)", 20210918, DateToNumber(d), err)
+	}
+	e, err := RawEmbeddedValue(raw, "e")
+	if err != nil || e.Lookup("a").Int32() != 1 {
+		t.Errorf("The value expected %v but was %v (%v)", bson.M{"a": 1}, e, err)
+	}
+	_, err = RawEmbeddedValue(raw, "s")
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+	ia, err := RawIntArrayValue(raw, "ia")
+	if err != nil || !IntSliceEquals([]int{1, 2, 3}, ia) {
+		t.Errorf("The value expected %v but was %v (%v)", []int{1, 2, 3}, ia, err)
+	}
+	sa, err := RawStringArrayValue(raw, "sa")
+	if err != nil || !StringSliceEquals([]string{"a", "b"}, sa) {
+		t.Errorf("The value expected %v but was %v (%v)", []string{"a", "b"}, sa, err)
+	}
+	_, err = RawStringArrayValue(raw, "ia")
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+	na, err := RawIntArrayValue(raw, "n")
+	if err != nil || na != nil {
+		t.Errorf("The value expected %v but was %v (%v)", nil, na, err)
+	}
+}
+
+func TestIterValue(t *testing.T) {
+	iter := jsoniter.ParseString(jsoniter.ConfigDefault, `[1, null, "str", 1.5, 1632050400000, 20210918, [1, 2], ["a"], {}]`)
+	values := make([]interface{}, 0)
+	var errs []error
+	iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+		var v interface{}
+		var err error
+		switch len(values) {
+		case 0:
+			v, err = IterIntValue(iter, 0)
+		case 1:
+			v, err = IterIntValue(iter, 5)
+		case 2:
+			v, err = IterStringValue(iter, "")
+		case 3:
+			v, err = IterFloat64Value(iter, 0)
+		case 4:
+			v, err = IterDateTimeValue(iter)
+		case 5:
+			v, err = IterDateValue(iter)
+		case 6:
+			v, err = IterIntArrayValue(iter)
+		case 7:
+			v, err = IterStringArrayValue(iter)
+		default:
+			v, err = IterIntValue(iter, 0)
+		}
+		values = append(values, v)
+		errs = append(errs, err)
+		return true
+	})
+	if len(values) != 9 {
+		t.Fatalf("The value expected %v but was %v", 9, len(values))
+	}
+	for i, err := range errs[:8] {
+		if err != nil {
+			t.Errorf("Unexpected error occurs at %d: %e", i, err)
+		}
+	}
+	if errs[8] == nil {
+		t.Error("Expected error but not")
+	}
+	if values[0] != 1 || values[1] != 5 || values[2] != "str" || values[3] != 1.5 {
+		t.Errorf("The value expected %v but was %v", []interface{}{1, 5, "str", 1.5}, values[:4])
+	}
+	if values[4].(time.Time).UnixMilli() != 1632050400000 {
+		t.Errorf("The value expected %v but was %v", 1632050400000, values[4].(time.Time).UnixMilli())
+	}
+	if DateToNumber(values[5].(time.Time)) != 20210918 {
+		t.Errorf("The value expected %v but was %v", 20210918, DateToNumber(values[5].(time.Time)))
+	}
+	if !IntSliceEquals([]int{1, 2}, values[6].([]int)) || !StringSliceEquals([]string{"a"}, values[7].([]string)) {
+		t.Errorf("The value expected %v but was %v", []interface{}{[]int{1, 2}, []string{"a"}}, values[6:8])
+	}
+}
@@ -0,0 +1,110 @@
+package bsonmodel
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// SkipChildren is used as a return value from Visitor to indicate that the
+// children of the visited value are to be skipped. It is not returned as an
+// error by Walk.
+var SkipChildren = errors.New("Skip children")
+
+// Visitor is the function called by Walk for each value in the model tree.
+//
+// The value is the model itself for objects and maps, or the value as it is
+// stored in the model for other fields, map entries and list elements.
+type Visitor func(xpath DotNotation, value interface{}) error
+
+// Walk traverses the model tree in pre-order, calling the visitor for the
+// model and each of its descendants.
+//
+// Fields of objects are visited in schema order, entries of maps are visited
+// in key order and elements of lists are visited in index order. Virtual
+// fields and fields not loaded by partial loads are not visited. Walk stops
+// at the first error returned by the visitor.
+func Walk(model BsonModel, visitor Visitor) error {
+	return walk(model.XPath(), model, visitor)
+}
+
+func walk(xpath DotNotation, value interface{}, visitor Visitor) error {
+	err := visitor(xpath, value)
+	if err != nil {
+		if err == SkipChildren {
+			return nil
+		}
+		return err
+	}
+	switch v := value.(type) {
+	case ObjectModel:
+		root, _ := v.(RootModel)
+		for _, field := range v.Schema().Fields {
+			if field.Virtual {
+				continue
+			}
+			if root != nil && !root.FieldLoaded(field.Bname) {
+				continue
+			}
+			fieldValue, err := v.GetField(field.Bname)
+			if err != nil {
+				return err
+			}
+			err = walk(xpath.Resolve(field.Bname), fieldValue, visitor)
+			if err != nil {
+				return err
+			}
+		}
+	case IntObjectMapModel:
+		keys := v.Keys()
+		sort.Ints(keys)
+		for _, key := range keys {
+			err = walk(xpath.Resolve(strconv.Itoa(key)), v.Get(key), visitor)
+			if err != nil {
+				return err
+			}
+		}
+	case StringObjectMapModel:
+		keys := v.Keys()
+		sort.Strings(keys)
+		for _, key := range keys {
+			err = walk(xpath.Resolve(key), v.Get(key), visitor)
+			if err != nil {
+				return err
+			}
+		}
+	case IntSimpleMapModel:
+		keys := v.Keys()
+		sort.Ints(keys)
+		for _, key := range keys {
+			err = walk(xpath.Resolve(strconv.Itoa(key)), v.Get(key), visitor)
+			if err != nil {
+				return err
+			}
+		}
+	case StringSimpleMapModel:
+		keys := v.Keys()
+		sort.Strings(keys)
+		for _, key := range keys {
+			err = walk(xpath.Resolve(key), v.Get(key), visitor)
+			if err != nil {
+				return err
+			}
+		}
+	case []int:
+		for i, e := range v {
+			err = walk(xpath.ResolveIndex(i), e, visitor)
+			if err != nil {
+				return err
+			}
+		}
+	case []string:
+		for i, e := range v {
+			err = walk(xpath.ResolveIndex(i), e, visitor)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
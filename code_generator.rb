@@ -138,7 +138,11 @@ def fill_imports(code, cfg)
   stds = Set.new
   others = Set.new
   aliases = {'github.com/json-iterator/go' => 'jsoniter'}
+  stds << 'io'
   stds << 'unsafe'
+  if cfg['type'] == 'root'
+    stds << 'sync'
+  end
   others << 'github.com/bits-and-blooms/bitset'
 	others << 'github.com/fmjsjx/bson-model-go/bsonmodel'
 	others << 'github.com/json-iterator/go'
@@ -150,6 +154,10 @@ def fill_imports(code, cfg)
   if cfg['fields'].any? { |field| %w(date).include? field['type'] }
     stds << 'time'
   end
+  if cfg['type'] == 'root' && cfg['indexes']
+    others << 'go.mongodb.org/mongo-driver/mongo'
+    others << 'go.mongodb.org/mongo-driver/mongo/options'
+  end
   code << "import (\n"
   stds.sort.each do |v|
     if aliases.include? v
@@ -187,27 +195,32 @@ def fill_interface(code, super_interface, cfg)
         if field['add'] == true
           code << tabs(1, "Add#{camel}(#{name} int) int")
         end
+        code << tabs(1, change_listener_signature(field))
       end
     when 'string'
       code << tabs(1, "#{camel}() string")
       unless field['virtual'] == true
         code << tabs(1, "Set#{camel}(#{name} string)")
+        code << tabs(1, change_listener_signature(field))
       end
     when 'float64'
       code << tabs(1, "#{camel}() float64")
       unless field['virtual'] == true
         code << tabs(1, "Set#{camel}(#{name} float64)")
+        code << tabs(1, change_listener_signature(field))
       end
     when 'datetime'
       code << tabs(1, "#{camel}() time.Time")
       unless field['virtual'] == true
         code << tabs(1, "Set#{camel}(#{name} time.Time)")
+        code << tabs(1, change_listener_signature(field))
       end
     when 'date'
       code << tabs(1, "#{camel}() time.Time")
       unless field['virtual'] == true
         code << tabs(1, "Set#{camel}(#{name} time.Time)")
         code << tabs(1, "Set#{camel}Number(#{name} int)")
+        code << tabs(1, change_listener_signature(field))
       end
     when 'object'
       code << tabs(1, "#{camel}() #{field['model']}")
@@ -231,11 +244,14 @@ def fill_interface(code, super_interface, cfg)
       code << tabs(1, "#{camel}() []#{value_type}")
       unless field['virtual'] == true
         code << tabs(1, "Set#{camel}(#{name} []#{value_type})")
+        code << tabs(1, change_listener_signature(field))
       end
     else
       raise "unsupported field type `#{field['type']}` on #{cfg['name']}.#{field['name']}"
     end
   end
+  code << tabs(1, "Clone() #{cfg['name']}")
+  code << tabs(1, "CopyFrom(other #{cfg['name']})")
   code << "}\n\n"
 end
 
@@ -260,6 +276,67 @@ def fill_const(code, cfg)
   end
 end
 
+def schema_field_type(type)
+  case type
+  when 'int' then 'FieldTypeInt'
+  when 'string' then 'FieldTypeString'
+  when 'float64' then 'FieldTypeFloat64'
+  when 'datetime' then 'FieldTypeDateTime'
+  when 'date' then 'FieldTypeDate'
+  when 'object' then 'FieldTypeObject'
+  when 'map' then 'FieldTypeMap'
+  when 'simple-map' then 'FieldTypeSimpleMap'
+  when 'simple-list' then 'FieldTypeSimpleList'
+  else
+    raise "unsupported field type `#{type}`"
+  end
+end
+
+def fill_schema(code, cfg)
+  small_camel = to_small_camel(cfg['name'])
+  model_type = case cfg['type']
+               when 'root' then 'ModelTypeRoot'
+               when 'object' then 'ModelTypeObject'
+               else 'ModelTypeMapValue'
+               end
+  code << "var #{small_camel}Schema = &bsonmodel.ModelSchema{\n"
+  code << tabs(1, "Name: \"#{cfg['name']}\",")
+  code << tabs(1, "Type: bsonmodel.#{model_type},")
+  code << tabs(1, "Fields: []*bsonmodel.FieldSchema{")
+  cfg['fields'].each do |field|
+    attrs = ["Name: \"#{field['name']}\""]
+    attrs << "Bname: \"#{field['bname']}\"" unless field['virtual'] == true
+    attrs << "Type: bsonmodel.#{schema_field_type(field['type'])}"
+    case field['type']
+    when 'object'
+      attrs << "Model: #{to_small_camel(field['model'])}Schema"
+    when 'map'
+      attrs << "KeyType: \"#{field['key']}\""
+      attrs << "ValueType: \"#{field['value']}\""
+      attrs << "Model: #{to_small_camel(field['value'])}Schema"
+    when 'simple-map'
+      attrs << "KeyType: \"#{field['key']}\""
+      attrs << "ValueType: \"#{field['value']}\""
+    when 'simple-list'
+      attrs << "ValueType: \"#{field['value']}\""
+    end
+    if field['virtual'] == true
+      attrs << "Virtual: true"
+      attrs << "Sources: []string{#{field['sources'].map { |source| "\"#{source}\"" }.join(', ')}}"
+    end
+    attrs << "Long: true" if field['long'] == true
+    attrs << "JsonIgnore: true" if field['json-ignore'] == true
+    attrs << "Required: true" if field['required'] == true
+    attrs << "Lazy: true" if field['lazy'] == true
+    code << tabs(2, "{#{attrs.join(', ')}},")
+  end
+  code << tabs(1, "},")
+  code << "}\n\n"
+  code << "func #{cfg['name']}Schema() *bsonmodel.ModelSchema {\n"
+  code << tabs(1, "return #{small_camel}Schema")
+  code << "}\n\n"
+end
+
 def all_object(cfg)
   parent = cfg['parent']
   if parent.nil?
@@ -287,9 +364,20 @@ def fill_struct(code, cfg, super_struct=nil)
     code << tabs(1, super_struct)
   end
   code << tabs(1, "#{fix_space('updatedFields', max_len)} *bitset.BitSet")
+  if cfg['type'] == 'root'
+    code << tabs(1, "#{fix_space('observers', max_len)} bsonmodel.ChangeObservers")
+    code << tabs(1, "#{fix_space('transaction', max_len)} bsonmodel.Transaction")
+    code << tabs(1, "#{fix_space('released', max_len)} bool")
+    code << tabs(1, "#{fix_space('partial', max_len)} bsonmodel.PartialState")
+    code << tabs(1, "#{fix_space('replaceMarker', max_len)} bool")
+  end
   if cfg['type'] == 'object'
     parent = cfg['parent']
+    code << tabs(1, "#{fix_space('released', max_len)} bool")
     code << tabs(1, "#{fix_space('parent', max_len)} #{parent['name']}")
+    unless all_object(cfg)
+      code << tabs(1, "#{fix_space('xpath', max_len)} bsonmodel.PathCache")
+    end
   end
   fields.each do |field|
     next if field['virtual'] == true
@@ -322,14 +410,26 @@ def fill_struct(code, cfg, super_struct=nil)
   code << "}\n\n"
 end
 
+# Starts the exported method of the model, which checks the model has not
+# been released first, and then runs the other checks.
+def fill_method(code, cfg, signature, checks = '')
+  code << "func (self *default#{cfg['name']}) #{signature} {\n"
+  if cfg['type'] == 'map-value'
+    code << tabs(1, "bsonmodel.CheckValueReleased(self, \"#{cfg['name']}\")")
+  else
+    code << tabs(1, "bsonmodel.CheckReleased(self.released, \"#{cfg['name']}\")")
+  end
+  code << checks
+end
+
 def fill_to_bson(code, cfg)
-  code << "func (self *default#{cfg['name']}) ToBson() interface{} {\n"
+  fill_method(code, cfg, "ToBson() interface{}")
   code << tabs(1, "return self.ToDocument()")
   code << "}\n\n"
 end
 
 def fill_to_data(code, cfg)
-  code << "func (self *default#{cfg['name']}) ToData() interface{} {\n"
+  fill_method(code, cfg, "ToData() interface{}")
   code << tabs(1, "data := make(map[string]interface{})")
   cfg['fields'].each do |field|
     next if field['virtual'] == true
@@ -350,12 +450,17 @@ def fill_to_data(code, cfg)
       end
     end
   end
+  code << tabs(1, "self.partial.FilterFields(data)") if cfg['type'] == 'root'
   code << tabs(1, "return data")
   code << "}\n\n"
 end
 
 def fill_load_jsoniter(code, cfg, is_root = false)
-  code << "func (self *default#{cfg['name']}) LoadJsoniter(any jsoniter.Any) error {\n"
+  fill_method(code, cfg, "LoadJsoniter(any jsoniter.Any) error")
+  code << tabs(1, "bsonmodel.SaveState(self)")
+  if is_root
+    code << tabs(1, "self.partial.Clear()")
+  end
   code << tabs(1, "if any.ValueType() != jsoniter.ObjectValue {")
   if is_root
     code << tabs(2, "self.Reset()")
@@ -450,8 +555,110 @@ def fill_load_jsoniter(code, cfg, is_root = false)
   code << "}\n\n"
 end
 
+def fill_load_json_iterator(code, cfg, is_root = false)
+  fill_method(code, cfg, "LoadJsonIterator(iter *jsoniter.Iterator) error")
+  code << tabs(1, "bsonmodel.SaveState(self)")
+  if is_root
+    code << tabs(1, "self.partial.Clear()")
+  end
+  code << tabs(1, "if iter.WhatIsNext() != jsoniter.ObjectValue {")
+  code << tabs(2, "iter.Skip()")
+  if is_root
+    code << tabs(2, "self.Reset()")
+  end
+  code << tabs(2, "return bsonmodel.IterError(iter)")
+  code << tabs(1, "}")
+  fields = cfg['fields'].reject { |field| field['virtual'] == true }
+  maps = []
+  fields.each do |field|
+    name = field['name']
+    case field['type']
+    when 'int'
+      code << tabs(1, "self.#{name} = #{field.has_key?('default') ? field['default'].to_i : 0}")
+    when 'string'
+      code << tabs(1, "self.#{name} = \"#{field.has_key?('default') ? field['default'].to_s : ''}\"")
+    when 'float64'
+      code << tabs(1, "self.#{name} = #{field.has_key?('default') ? field['default'] : '0'}")
+    when 'datetime', 'date'
+      code << tabs(1, "self.#{name} = time.Time{}")
+    when 'simple-list'
+      code << tabs(1, "self.#{name} = nil")
+    when 'map', 'simple-map'
+      maps << field
+    end
+  end
+  maps.each do |field|
+    code << tabs(1, "#{field['name']}Loaded := false")
+  end
+  code << tabs(1, "var err error")
+  code << tabs(1, "iter.ReadObjectCB(func(iter *jsoniter.Iterator, field string) bool {")
+  code << tabs(2, "switch field {")
+  # also reads the JSON keyed by field names, as written by MarshalJSON()
+  bnames = fields.map { |field| field['bname'] }
+  fields.each do |field|
+    name = field['name']
+    named = field['json-ignore'] != true && name != field['bname']
+    if named && bnames.include?(name)
+      raise "field name `#{name}` conflicts with the BSON name of another field on #{cfg['name']}"
+    end
+    if named && field['type'] != 'datetime'
+      code << tabs(2, "case Bname#{cfg['name']}#{to_camel(name)}, \"#{name}\":")
+    else
+      code << tabs(2, "case Bname#{cfg['name']}#{to_camel(name)}:")
+    end
+    case field['type']
+    when 'int'
+      code << tabs(3, "self.#{name}, err = bsonmodel.IterIntValue(iter, #{field.has_key?('default') ? field['default'].to_i : 0})")
+    when 'string'
+      code << tabs(3, "self.#{name}, err = bsonmodel.IterStringValue(iter, \"#{field.has_key?('default') ? field['default'].to_s : ''}\")")
+    when 'float64'
+      code << tabs(3, "self.#{name}, err = bsonmodel.IterFloat64Value(iter, #{field.has_key?('default') ? field['default'] : '0'})")
+    when 'datetime'
+      code << tabs(3, "self.#{name}, err = bsonmodel.IterDateTimeValue(iter)")
+    when 'date'
+      code << tabs(3, "self.#{name}, err = bsonmodel.IterDateValue(iter)")
+    when 'object'
+      code << tabs(3, "err = self.#{name}.LoadJsonIterator(iter)")
+    when 'map', 'simple-map'
+      code << tabs(3, "#{name}Loaded = true")
+      code << tabs(3, "err = self.#{name}.LoadJsonIterator(iter)")
+    when 'simple-list'
+      case field['value']
+      when 'int'
+        code << tabs(3, "self.#{name}, err = bsonmodel.IterIntArrayValue(iter)")
+      when 'string'
+        code << tabs(3, "self.#{name}, err = bsonmodel.IterStringArrayValue(iter)")
+      end
+    end
+    if named && field['type'] == 'datetime'
+      # encoded in seconds by MarshalJSON() but in milliseconds by ToData()
+      code << tabs(2, "case \"#{name}\":")
+      code << tabs(3, "self.#{name}, err = bsonmodel.IterUnixValue(iter)")
+    end
+  end
+  code << tabs(2, "default:")
+  code << tabs(3, "iter.Skip()")
+  code << tabs(2, "}")
+  code << tabs(2, "return err == nil")
+  code << tabs(1, "})")
+  code << tabs(1, "if err != nil {")
+  code << tabs(2, "return err")
+  code << tabs(1, "}")
+  maps.each do |field|
+    code << tabs(1, "if !#{field['name']}Loaded {")
+    code << tabs(2, "self.#{field['name']}.Clear()")
+    code << tabs(1, "}")
+  end
+  if is_root
+    code << tabs(1, "self.Reset()")
+  end
+  code << tabs(1, "return bsonmodel.IterError(iter)")
+  code << "}\n\n"
+end
+
 def fill_reset(code, cfg)
-  code << "func (self *default#{cfg['name']}) Reset() {\n"
+  fill_method(code, cfg, "Reset()")
+  code << tabs(1, "bsonmodel.SaveState(self)")
   cfg['fields'].each do |field|
     next if field['virtual'] == true
     if %w(object map simple-map).include? field['type']
@@ -462,8 +669,210 @@ def fill_reset(code, cfg)
   code << "}\n\n"
 end
 
+def fill_clear(code, cfg)
+  code << "func (self *default#{cfg['name']}) clear() {\n"
+  cfg['fields'].each do |field|
+    next if field['virtual'] == true
+    name = field['name']
+    case field['type']
+    when 'int'
+      code << tabs(1, "self.#{name} = #{field.has_key?('default') ? field['default'].to_i : 0}")
+    when 'string'
+      code << tabs(1, "self.#{name} = \"#{field.has_key?('default') ? field['default'].to_s : ''}\"")
+    when 'float64'
+      code << tabs(1, "self.#{name} = #{field.has_key?('default') ? field['default'] : '0'}")
+    when 'datetime', 'date'
+      code << tabs(1, "self.#{name} = time.Time{}")
+    when 'simple-list'
+      code << tabs(1, "self.#{name} = nil")
+    when 'object'
+      code << tabs(1, "self.#{name}.(*default#{field['model']}).clear()")
+    when 'map', 'simple-map'
+      code << tabs(1, "self.#{name}.Discard()")
+    end
+  end
+  if cfg['type'] == 'object'
+    code << tabs(1, "if bsonmodel.Debug {")
+    code << tabs(2, "self.released = true")
+    code << tabs(1, "}")
+  end
+  code << "}\n\n"
+end
+
+def fill_projection(code, cfg)
+  name = cfg['name']
+  builder = "#{name}ProjectionBuilder"
+  code << "type #{builder} struct {\n"
+  code << tabs(1, "projection bson.M")
+  code << "}\n\n"
+  code << "func #{name}Projection() *#{builder} {\n"
+  code << tabs(1, "return &#{builder}{projection: bson.M{}}")
+  code << "}\n\n"
+  cfg['fields'].each do |field|
+    next if field['virtual'] == true
+    camel = to_camel(field['name'])
+    code << "func (builder *#{builder}) #{camel}() *#{builder} {\n"
+    code << tabs(1, "builder.projection[Bname#{name}#{camel}] = 1")
+    code << tabs(1, "return builder")
+    code << "}\n\n"
+  end
+  code << "func (builder *#{builder}) Build() bson.M {\n"
+  code << tabs(1, "projection := make(bson.M, len(builder.projection))")
+  code << tabs(1, "for k, v := range builder.projection {")
+  code << tabs(2, "projection[k] = v")
+  code << tabs(1, "}")
+  code << tabs(1, "return projection")
+  code << "}\n\n"
+end
+
+def fill_paths(code, cfg, is_root = false)
+  name = cfg['name']
+  paths = "#{name}Paths"
+  code << "type #{paths} struct {\n"
+  code << tabs(1, "base bsonmodel.DotNotation")
+  code << "}\n\n"
+  if is_root
+    code << "var #{name}Path = #{paths}{bsonmodel.RootPath()}\n\n"
+  end
+  code << "func (paths #{paths}) Path() bsonmodel.DotNotation {\n"
+  code << tabs(1, "return paths.base")
+  code << "}\n\n"
+  cfg['fields'].each do |field|
+    next if field['virtual'] == true
+    camel = to_camel(field['name'])
+    xpath = "paths.base.Resolve(Bname#{name}#{camel})"
+    case field['type']
+    when 'object'
+      code << "func (paths #{paths}) #{camel}() #{field['model']}Paths {\n"
+      code << tabs(1, "return #{field['model']}Paths{#{xpath}}")
+    when 'map'
+      code << "func (paths #{paths}) #{camel}() #{field['value']}MapPaths {\n"
+      code << tabs(1, "return #{field['value']}MapPaths{#{xpath}}")
+    when 'simple-map'
+      map_path = field['key'] == 'int' ? 'IntMapPath' : 'StringMapPath'
+      code << "func (paths #{paths}) #{camel}() bsonmodel.#{map_path} {\n"
+      code << tabs(1, "return bsonmodel.New#{map_path}(#{xpath})")
+    else
+      code << "func (paths #{paths}) #{camel}() bsonmodel.DotNotation {\n"
+      code << tabs(1, "return #{xpath}")
+    end
+    code << "}\n\n"
+  end
+  if cfg['type'] == 'map-value'
+    map_paths = "#{name}MapPaths"
+    code << "type #{map_paths} struct {\n"
+    code << tabs(1, "base bsonmodel.DotNotation")
+    code << "}\n\n"
+    code << "func (paths #{map_paths}) Path() bsonmodel.DotNotation {\n"
+    code << tabs(1, "return paths.base")
+    code << "}\n\n"
+    if cfg['key'] == 'int'
+      code << "func (paths #{map_paths}) Key(key int) #{paths} {\n"
+      code << tabs(1, "return #{paths}{paths.base.ResolveIndex(key)}")
+    else
+      code << "func (paths #{map_paths}) Key(key string) #{paths} {\n"
+      code << tabs(1, "return #{paths}{paths.base.Resolve(key)}")
+    end
+    code << "}\n\n"
+  end
+end
+
+def index_path(cfg, path)
+  model = cfg
+  bnames = []
+  field = nil
+  path.to_s.split('.').each do |name|
+    if model.nil?
+      raise "can not index on `#{path}` of model #{cfg['name']}"
+    end
+    field = model['fields'].find { |f| f['name'] == name && f['virtual'] != true }
+    if field.nil?
+      raise "no such field `#{name}` in model #{model['name']}"
+    end
+    bnames << field['bname']
+    model = field['type'] == 'object' ? cfg['models'][field['model']] : nil
+  end
+  [bnames.join('.'), field]
+end
+
+def bson_literal(cfg, value, top = false)
+  case value
+  when Hash
+    elements = value.map do |k, v|
+      key = top && !k.to_s.start_with?('$') ? index_path(cfg, k)[0] : k.to_s
+      "{Key: \"#{key}\", Value: #{bson_literal(cfg, v)}}"
+    end
+    "bson.D{#{elements.join(', ')}}"
+  when Array
+    "bson.A{#{value.map { |v| bson_literal(cfg, v) }.join(', ')}}"
+  when String
+    "\"#{value}\""
+  when nil
+    'nil'
+  else
+    value.to_s
+  end
+end
+
+def fill_indexes(code, cfg)
+  indexes = cfg['indexes']
+  return if indexes.nil?
+  code << "func #{cfg['name']}Indexes() []mongo.IndexModel {\n"
+  code << tabs(1, "return []mongo.IndexModel{")
+  indexes.each do |index|
+    keys = index['keys'].map do |key|
+      key.is_a?(Hash) ? key.first : [key, 1]
+    end.map do |path, order|
+      bname, field = index_path(cfg, path)
+      [bname, field, order]
+    end
+    options = 'options.Index()'
+    options += ".SetName(\"#{index['name']}\")" if index['name']
+    options += '.SetUnique(true)' if index['unique']
+    if index.has_key? 'ttl'
+      unless keys.size == 1 && keys[0][1]['type'] == 'datetime'
+        raise "TTL index must be on a single datetime field in model #{cfg['name']}"
+      end
+      options += ".SetExpireAfterSeconds(#{index['ttl']})"
+    end
+    if index['partial']
+      options += ".SetPartialFilterExpression(#{bson_literal(cfg, index['partial'], true)})"
+    end
+    elements = keys.map { |bname, field, order| "{Key: \"#{bname}\", Value: #{bson_literal(cfg, order)}}" }
+    code << tabs(2, "{")
+    code << tabs(3, "Keys:    bson.D{#{elements.join(', ')}},")
+    code << tabs(3, "Options: #{options},")
+    code << tabs(2, "},")
+  end
+  code << tabs(1, "}")
+  code << "}\n\n"
+end
+
+def fill_release(code, cfg)
+  name = cfg['name']
+  small_camel = to_small_camel(name)
+  fill_method(code, cfg, "Release()")
+  code << tabs(1, "self.observers.Reset()")
+  code << tabs(1, "self.transaction = bsonmodel.Transaction{}")
+  code << tabs(1, "self.partial.Clear()")
+  code << tabs(1, "self.replaceMarker = false")
+  code << tabs(1, "self.Reset()")
+  code << tabs(1, "self.clear()")
+  code << tabs(1, "self.released = true")
+  code << tabs(1, "if !bsonmodel.Debug {")
+  code << tabs(2, "#{small_camel}Pool.Put(self)")
+  code << tabs(1, "}")
+  code << "}\n\n"
+  code << "var #{small_camel}Pool = sync.Pool{New: func() interface{} { return New#{name}() }}\n\n"
+  code << "func Acquire#{name}() #{name} {\n"
+  code << tabs(1, "self := #{small_camel}Pool.Get().(*default#{name})")
+  code << tabs(1, "self.released = false")
+  code << tabs(1, "return self")
+  code << "}\n\n"
+end
+
 def fill_any_updated(code, cfg)
-  code << "func (self *default#{cfg['name']}) AnyUpdated() bool {\n"
+  fill_method(code, cfg, "AnyUpdated() bool")
   any_updateds = []
   cfg['fields'].each do |field|
     next if field['virtual'] == true
@@ -480,13 +889,13 @@ def fill_any_updated(code, cfg)
 end
 
 def fill_any_deleted(code, cfg)
-  code << "func (self *default#{cfg['name']}) AnyDeleted() bool {\n"
+  fill_method(code, cfg, "AnyDeleted() bool")
   code << tabs(1, "return self.DeletedSize() > 0")
   code << "}\n\n"
 end
 
 def fill_append_updates(code, cfg)
-  code << "func (self *default#{cfg['name']}) AppendUpdates(updates bson.M) bson.M {\n"
+  fill_method(code, cfg, "AppendUpdates(updates bson.M) bson.M")
   code << tabs(1, "dset := bsonmodel.FixedEmbedded(updates, \"$set\")")
   if cfg['type'] == 'root'
     code << tabs(1, "updatedFields := self.updatedFields")
@@ -563,7 +972,7 @@ def fill_append_updates(code, cfg)
 end
 
 def fill_to_document(code, cfg)
-  code << "func (self *default#{cfg['name']}) ToDocument() bson.M {\n"
+  fill_method(code, cfg, "ToDocument() bson.M")
   code << tabs(1, "doc := bson.M{}")
   cfg['fields'].each do |field|
     next if field['virtual'] == true
@@ -590,12 +999,17 @@ def fill_to_document(code, cfg)
       end
     end
   end
+  code << tabs(1, "self.partial.FilterFields(doc)") if cfg['type'] == 'root'
   code << tabs(1, "return doc")
   code << "}\n\n"
 end
 
 def fill_load_document(code, cfg, is_root = false)
-  code << "func (self *default#{cfg['name']}) LoadDocument(document bson.M) error {\n"
+  fill_method(code, cfg, "LoadDocument(document bson.M) error")
+  code << tabs(1, "bsonmodel.SaveState(self)")
+  if is_root
+    code << tabs(1, "self.partial.Clear()")
+  end
   cfg['fields'].each do |field|
     next if field['virtual'] == true
     name = field['name']
@@ -651,10 +1065,14 @@ def fill_load_document(code, cfg, is_root = false)
       code << tabs(2, "return err")
       code << tabs(1, "}")
       code << tabs(1, "if #{name} != nil {")
-      code << tabs(2, "err = self.#{name}.LoadDocument(#{name})")
-      code << tabs(2, "if err != nil {")
-      code << tabs(3, "return err")
-      code << tabs(2, "}")
+      if field['lazy'] == true
+        code << tabs(2, "self.#{name}.LoadDocumentLazily(#{name})")
+      else
+        code << tabs(2, "err = self.#{name}.LoadDocument(#{name})")
+        code << tabs(2, "if err != nil {")
+        code << tabs(3, "return err")
+        code << tabs(2, "}")
+      end
       code << tabs(1, "} else {")
       code << tabs(2, "self.#{name}.Clear()")
       code << tabs(1, "}")
@@ -664,10 +1082,14 @@ def fill_load_document(code, cfg, is_root = false)
       code << tabs(2, "return err")
       code << tabs(1, "}")
       code << tabs(1, "if #{name} != nil {")
-      code << tabs(2, "err = self.#{name}.LoadDocument(#{name})")
-      code << tabs(2, "if err != nil {")
-      code << tabs(3, "return err")
-      code << tabs(2, "}")
+      if field['lazy'] == true
+        code << tabs(2, "self.#{name}.LoadDocumentLazily(#{name})")
+      else
+        code << tabs(2, "err = self.#{name}.LoadDocument(#{name})")
+        code << tabs(2, "if err != nil {")
+        code << tabs(3, "return err")
+        code << tabs(2, "}")
+      end
       code << tabs(1, "} else {")
       code << tabs(2, "self.#{name}.Clear()")
       code << tabs(1, "}")
@@ -695,27 +1117,163 @@ def fill_load_document(code, cfg, is_root = false)
   code << "}\n\n"
 end
 
-def fill_deleted_size(code, cfg)
-  code << "func (self *default#{cfg['name']}) DeletedSize() int {\n"
-  if cfg['fields'].none? { |field| %w(object map simple-map simple-list).include? field['type'] }
-    code << tabs(1, "return 0")
-  else
-    code << tabs(1, "n := 0")
-    cfg['fields'].each_with_index do |field, index|
-      next unless %w(object map simple-map simple-list).include? field['type']
-      name = field['name']
-      if field['type'] == 'simple-list'
-        code << tabs(1, "if self.updatedFields.Test(#{index + 1}) && self.#{name} == nil {")
+def fill_load_raw(code, cfg, is_root = false)
+  fill_method(code, cfg, "LoadRaw(raw bson.Raw) error")
+  code << tabs(1, "bsonmodel.SaveState(self)")
+  if is_root
+    code << tabs(1, "self.partial.Clear()")
+  end
+  cfg['fields'].each do |field|
+    next if field['virtual'] == true
+    name = field['name']
+    bname = field['bname']
+    case field['type']
+    when 'int'
+      default = field.has_key?('default') ? field['default'].to_i : 0
+      code << tabs(1, "#{name}, err := bsonmodel.RawIntValue(raw, \"#{bname}\", #{default})")
+      code << tabs(1, "if err != nil {")
+      code << tabs(2, "return err")
+      code << tabs(1, "}")
+      code << tabs(1, "self.#{name} = #{name}")
+    when 'string'
+      default = field.has_key?('default') ? field['default'].to_s : ''
+      code << tabs(1, "#{name}, err := bsonmodel.RawStringValue(raw, \"#{bname}\", \"#{default}\")")
+      code << tabs(1, "if err != nil {")
+      code << tabs(2, "return err")
+      code << tabs(1, "}")
+      code << tabs(1, "self.#{name} = #{name}")
+    when 'float64'
+      default = field.has_key?('default') ? field['default'] : '0'
+      code << tabs(1, "#{name}, err := bsonmodel.RawFloat64Value(raw, \"#{bname}\", #{default})")
+      code << tabs(1, "if err != nil {")
+      code << tabs(2, "return err")
+      code << tabs(1, "}")
+      code << tabs(1, "self.#{name} = #{name}")
+    when 'datetime'
+      code << tabs(1, "#{name}, err := bsonmodel.RawDateTimeValue(raw, \"#{bname}\")")
+      code << tabs(1, "if err != nil {")
+      code << tabs(2, "return err")
+      code << tabs(1, "}")
+      code << tabs(1, "self.#{name} = #{name}")
+    when 'date'
+      code << tabs(1, "#{name}, err := bsonmodel.RawDateValue(raw, \"#{bname}\")")
+      code << tabs(1, "if err != nil {")
+      code << tabs(2, "return err")
+      code << tabs(1, "}")
+      code << tabs(1, "self.#{name} = #{name}")
+    when 'object'
+      code << tabs(1, "#{name}, err := bsonmodel.RawEmbeddedValue(raw, \"#{bname}\")")
+      code << tabs(1, "if err != nil {")
+      code << tabs(2, "return err")
+      code << tabs(1, "}")
+      code << tabs(1, "if #{name} != nil {")
+      code << tabs(2, "err = self.#{name}.LoadRaw(#{name})")
+      code << tabs(2, "if err != nil {")
+      code << tabs(3, "return err")
+      code << tabs(2, "}")
+      code << tabs(1, "}")
+    when 'map'
+      code << tabs(1, "#{name}, err := bsonmodel.RawEmbeddedValue(raw, \"#{bname}\")")
+      code << tabs(1, "if err != nil {")
+      code << tabs(2, "return err")
+      code << tabs(1, "}")
+      code << tabs(1, "if #{name} != nil {")
+      if field['lazy'] == true
+        code << tabs(2, "self.#{name}.LoadRawLazily(#{name})")
       else
-        code << tabs(1, "if self.#{name}.AnyDeleted() {")
+        code << tabs(2, "err = self.#{name}.LoadRaw(#{name})")
+        code << tabs(2, "if err != nil {")
+        code << tabs(3, "return err")
+        code << tabs(2, "}")
       end
-      code << tabs(2, "n += 1")
+      code << tabs(1, "} else {")
+      code << tabs(2, "self.#{name}.Clear()")
+      code << tabs(1, "}")
+    when 'simple-map'
+      code << tabs(1, "#{name}, err := bsonmodel.RawEmbeddedValue(raw, \"#{bname}\")")
+      code << tabs(1, "if err != nil {")
+      code << tabs(2, "return err")
+      code << tabs(1, "}")
+      code << tabs(1, "if #{name} != nil {")
+      if field['lazy'] == true
+        code << tabs(2, "self.#{name}.LoadRawLazily(#{name})")
+      else
+        code << tabs(2, "err = self.#{name}.LoadRaw(#{name})")
+        code << tabs(2, "if err != nil {")
+        code << tabs(3, "return err")
+        code << tabs(2, "}")
+      end
+      code << tabs(1, "} else {")
+      code << tabs(2, "self.#{name}.Clear()")
       code << tabs(1, "}")
+    when 'simple-list'
+      case field['value']
+      when 'int'
+        code << tabs(1, "#{name}, err := bsonmodel.RawIntArrayValue(raw, \"#{bname}\")")
+        code << tabs(1, "if err != nil {")
+        code << tabs(2, "return err")
+        code << tabs(1, "}")
+        code << tabs(1, "self.#{name} = #{name}")
+      when 'string'
+        code << tabs(1, "#{name}, err := bsonmodel.RawStringArrayValue(raw, \"#{bname}\")")
+        code << tabs(1, "if err != nil {")
+        code << tabs(2, "return err")
+        code << tabs(1, "}")
+        code << tabs(1, "self.#{name} = #{name}")
+      end
     end
-    code << tabs(1, "return n")
   end
-  code << "}\n\n"
-end
+  if is_root
+    code << tabs(1, "self.Reset()")
+  end
+  code << tabs(1, "return nil")
+  code << "}\n\n"
+end
+
+def fill_load_document_tracked(code, cfg)
+  fill_method(code, cfg, "LoadDocumentTracked(document bson.M) error")
+  if cfg['type'] == 'object'
+    code << tabs(1, "loaded := New#{cfg['name']}(nil)")
+  else
+    code << tabs(1, "loaded := New#{cfg['name']}()")
+  end
+  code << tabs(1, "err := loaded.LoadDocument(document)")
+  code << tabs(1, "if err != nil {")
+  code << tabs(2, "return err")
+  code << tabs(1, "}")
+  cfg['fields'].each do |field|
+    next unless field['lazy'] == true
+    code << tabs(1, "err = loaded.#{to_camel(field['name'])}().Load()")
+    code << tabs(1, "if err != nil {")
+    code << tabs(2, "return err")
+    code << tabs(1, "}")
+  end
+  code << tabs(1, "self.CopyFrom(loaded)")
+  code << tabs(1, "return nil")
+  code << "}\n\n"
+end
+
+def fill_deleted_size(code, cfg)
+  fill_method(code, cfg, "DeletedSize() int")
+  if cfg['fields'].none? { |field| %w(object map simple-map simple-list).include? field['type'] }
+    code << tabs(1, "return 0")
+  else
+    code << tabs(1, "n := 0")
+    cfg['fields'].each_with_index do |field, index|
+      next unless %w(object map simple-map simple-list).include? field['type']
+      name = field['name']
+      if field['type'] == 'simple-list'
+        code << tabs(1, "if self.updatedFields.Test(#{index + 1}) && self.#{name} == nil {")
+      else
+        code << tabs(1, "if self.#{name}.AnyDeleted() {")
+      end
+      code << tabs(2, "n += 1")
+      code << tabs(1, "}")
+    end
+    code << tabs(1, "return n")
+  end
+  code << "}\n\n"
+end
 
 def fill_fully_update(code, cfg, is_root = false)
   code << "func (self *default#{cfg['name']}) FullyUpdate() bool {\n"
@@ -729,6 +1287,7 @@ def fill_fully_update(code, cfg, is_root = false)
   if is_root
     code << tabs(1, "// no effect")
   else
+    code << tabs(1, "bsonmodel.SaveState(self)")
     code << tabs(1, "if fullyUpdate {")
     code << tabs(2, "self.updatedFields.Set(0)")
     code << tabs(1, "} else {")
@@ -738,11 +1297,29 @@ def fill_fully_update(code, cfg, is_root = false)
   code << "}\n\n"
 end
 
+def fill_snapshot(code, cfg)
+  fill_method(code, cfg, "Snapshot() func()")
+  fields = cfg['fields'].select do |field|
+    field['virtual'] != true && %w(int string float64 datetime date simple-list).include?(field['type'])
+  end
+  code << tabs(1, "updatedFields := self.updatedFields.Clone()")
+  fields.each do |field|
+    code << tabs(1, "#{field['name']} := self.#{field['name']}")
+  end
+  code << tabs(1, "return func() {")
+  code << tabs(2, "self.updatedFields = updatedFields")
+  fields.each do |field|
+    code << tabs(2, "self.#{field['name']} = #{field['name']}")
+  end
+  code << tabs(1, "}")
+  code << "}\n\n"
+end
+
 def fill_to_sync(code, cfg, is_root = false)
-  code << "func (self *default#{cfg['name']}) ToSync() interface{} {\n"
+  fill_method(code, cfg, "ToSync() interface{}")
   unless is_root
     code << tabs(1, "if self.FullyUpdate() {")
-    code << tabs(2, "return self")
+    code << tabs(2, "return bsonmodel.FullySync(self)")
     code << tabs(1, "}")
   end
   code << tabs(1, "sync := make(map[string]interface{})")
@@ -791,7 +1368,7 @@ def fill_to_sync(code, cfg, is_root = false)
 end
 
 def fill_to_delete(code, cfg)
-  code << "func (self *default#{cfg['name']}) ToDelete() interface{} {\n"
+  fill_method(code, cfg, "ToDelete() interface{}")
   code << tabs(1, "delete := make(map[string]interface{})")
   cfg['fields'].each_with_index do |field, index|
     next if field['json-ignore'] == true
@@ -811,36 +1388,228 @@ def fill_to_delete(code, cfg)
 end
 
 def fill_to_x_json(code, cfg)
-  code << "func (self *default#{cfg['name']}) ToDataJson() (string, error) {\n"
-  code << tabs(1, "return jsoniter.MarshalToString(self.ToData())")
+  fill_method(code, cfg, "ToDataJson() (string, error)")
+  code << tabs(1, "return bsonmodel.DataJsonOf(self)")
   code << "}\n\n"
-  code << "func (self *default#{cfg['name']}) ToSyncJson() (string, error) {\n"
-  code << tabs(1, "return jsoniter.MarshalToString(self.ToSync())")
+  fill_method(code, cfg, "ToSyncJson() (string, error)")
+  code << tabs(1, "return bsonmodel.SyncJsonOf(self)")
   code << "}\n\n"
-  code << "func (self *default#{cfg['name']}) ToDeleteJson() (string, error) {\n"
+  fill_method(code, cfg, "ToDeleteJson() (string, error)")
   code << tabs(1, "return jsoniter.MarshalToString(self.ToDelete())")
   code << "}\n\n"
 end
 
+def stream_write(field, value)
+  case field['type']
+  when 'int'
+    "stream.WriteInt(#{value})"
+  when 'string'
+    "stream.WriteString(#{value})"
+  when 'float64'
+    "stream.WriteFloat64(#{value})"
+  when 'date'
+    "stream.WriteInt(bsonmodel.DateToNumber(#{value}))"
+  else
+    "stream.WriteVal(#{value})"
+  end
+end
+
+def fill_encode(code, cfg, is_root = false)
+  fill_method(code, cfg, "EncodeData(stream *jsoniter.Stream)")
+  code << tabs(1, "object := bsonmodel.BeginJsonObject(stream)")
+  cfg['fields'].each do |field|
+    next if field['virtual'] == true
+    name = field['name']
+    bname = field['bname']
+    n = 1
+    if is_root
+      code << tabs(1, "if #{partial_loaded(cfg, field, 'self')} {")
+      n = 2
+    end
+    case field['type']
+    when 'object', 'map', 'simple-map'
+      code << tabs(n, "object.Field(\"#{bname}\")")
+      code << tabs(n, "self.#{name}.EncodeData(stream)")
+    when 'datetime'
+      code << tabs(n, "object.Field(\"#{bname}\")")
+      code << tabs(n, "stream.WriteInt64(self.#{name}.UnixMilli())")
+    when 'simple-list'
+      code << tabs(n, "if self.#{name} != nil {")
+      code << tabs(n + 1, "object.Field(\"#{bname}\")")
+      code << tabs(n + 1, "stream.WriteVal(self.#{name})")
+      code << tabs(n, "}")
+    else
+      code << tabs(n, "object.Field(\"#{bname}\")")
+      code << tabs(n, stream_write(field, "self.#{name}"))
+    end
+    code << tabs(1, "}") if is_root
+  end
+  code << tabs(1, "object.End()")
+  code << "}\n\n"
+  fill_method(code, cfg, "EncodeSync(stream *jsoniter.Stream)")
+  unless is_root
+    code << tabs(1, "if self.FullyUpdate() {")
+    code << tabs(2, "stream.WriteVal(bsonmodel.FullySync(self))")
+    code << tabs(2, "return")
+    code << tabs(1, "}")
+  end
+  code << tabs(1, "object := bsonmodel.BeginJsonObject(stream)")
+  cfg['fields'].each_with_index do |field, index|
+    next if field['json-ignore'] == true
+    name = field['name']
+    value = field['virtual'] == true ? "self.#{to_camel(name)}()" : "self.#{name}"
+    case field['type']
+    when 'object', 'map', 'simple-map'
+      code << tabs(1, "if self.#{name}.AnyUpdated() {")
+      code << tabs(2, "object.Field(\"#{name}\")")
+      code << tabs(2, "self.#{name}.EncodeSync(stream)")
+    when 'datetime'
+      code << tabs(1, "if self.updatedFields.Test(#{index + 1}) {")
+      code << tabs(2, "object.Field(\"#{name}\")")
+      code << tabs(2, "stream.WriteInt64(#{value}.Unix())")
+    when 'simple-list'
+      code << tabs(1, "if self.updatedFields.Test(#{index + 1}) && self.#{name} != nil {")
+      code << tabs(2, "object.Field(\"#{name}\")")
+      code << tabs(2, "stream.WriteVal(self.#{name})")
+    else
+      code << tabs(1, "if self.updatedFields.Test(#{index + 1}) {")
+      code << tabs(2, "object.Field(\"#{name}\")")
+      code << tabs(2, stream_write(field, value))
+    end
+    code << tabs(1, "}")
+  end
+  code << tabs(1, "object.End()")
+  code << "}\n\n"
+  fill_method(code, cfg, "WriteDataJson(w io.Writer) error")
+  code << tabs(1, "return bsonmodel.WriteDataJson(w, self)")
+  code << "}\n\n"
+  fill_method(code, cfg, "WriteSyncJson(w io.Writer) error")
+  code << tabs(1, "return bsonmodel.WriteSyncJson(w, self)")
+  code << "}\n\n"
+end
+
+def fill_to_patch(code, cfg)
+  fill_method(code, cfg, "ToMergePatch() interface{}")
+  code << tabs(1, "return bsonmodel.MergePatchOf(self)")
+  code << "}\n\n"
+  fill_method(code, cfg, "ToJsonPatch() []bsonmodel.JsonPatchOperation")
+  code << tabs(1, "return bsonmodel.JsonPatchOf(self)")
+  code << "}\n\n"
+  fill_method(code, cfg, "Changes() []bsonmodel.Change")
+  code << tabs(1, "return bsonmodel.ChangesOf(self)")
+  code << "}\n\n"
+  code << "func (self *default#{cfg['name']}) Schema() *bsonmodel.ModelSchema {\n"
+  code << tabs(1, "return #{to_small_camel(cfg['name'])}Schema")
+  code << "}\n\n"
+end
+
+def fill_path_accessors(code, cfg)
+  fields = cfg['fields'].reject { |field| field['virtual'] == true }
+  fill_method(code, cfg, "GetField(name string) (interface{}, error)")
+  if cfg['type'] == 'root'
+    code << tabs(1, "err := self.partial.CheckField(#{to_small_camel(cfg['name'])}Schema, name)")
+    code << tabs(1, "if err != nil {")
+    code << tabs(2, "return nil, err")
+    code << tabs(1, "}")
+  end
+  code << tabs(1, "switch name {")
+  fields.each do |field|
+    code << tabs(1, "case Bname#{cfg['name']}#{to_camel(field['name'])}:")
+    code << tabs(2, "return self.#{field['name']}, nil")
+  end
+  code << tabs(1, "default:")
+  code << tabs(2, "return nil, bsonmodel.NoSuchFieldError(name)")
+  code << tabs(1, "}")
+  code << "}\n\n"
+  settable = []
+  unsettable = []
+  fields.each do |field|
+    case field['type']
+    when 'int'
+      settable << [field, 'bsonmodel.ParseInt(value)']
+    when 'string'
+      settable << [field, 'bsonmodel.ParseString(value)']
+    when 'float64'
+      settable << [field, 'bsonmodel.ParseFloat64(value)']
+    when 'datetime'
+      settable << [field, 'bsonmodel.ParseDateTime(value)']
+    when 'date'
+      settable << [field, 'bsonmodel.ParseDate(value)']
+    when 'simple-list'
+      case field['value']
+      when 'int'
+        settable << [field, 'bsonmodel.ParseIntArray(value)']
+      when 'string'
+        settable << [field, 'bsonmodel.ParseStringArray(value)']
+      end
+    else
+      unsettable << field
+    end
+  end
+  fill_method(code, cfg, "SetField(name string, value interface{}) error")
+  if cfg['type'] == 'root'
+    code << tabs(1, "err := self.partial.CheckField(#{to_small_camel(cfg['name'])}Schema, name)")
+    code << tabs(1, "if err != nil {")
+    code << tabs(2, "return err")
+    code << tabs(1, "}")
+  end
+  code << tabs(1, "switch name {")
+  settable.each do |field, parser|
+    code << tabs(1, "case Bname#{cfg['name']}#{to_camel(field['name'])}:")
+    code << tabs(2, "v, err := #{parser}")
+    code << tabs(2, "if err != nil {")
+    code << tabs(3, "return err")
+    code << tabs(2, "}")
+    code << tabs(2, "self.Set#{to_camel(field['name'])}(v)")
+  end
+  unless unsettable.empty?
+    code << tabs(1, "case #{unsettable.map { |field| "Bname#{cfg['name']}#{to_camel(field['name'])}" }.join(', ')}:")
+    code << tabs(2, "return bsonmodel.UnsettableFieldError(name)")
+  end
+  code << tabs(1, "default:")
+  code << tabs(2, "return bsonmodel.NoSuchFieldError(name)")
+  code << tabs(1, "}")
+  code << tabs(1, "return nil") unless settable.empty?
+  code << "}\n\n"
+  fill_method(code, cfg, "GetPath(xpath bsonmodel.DotNotation) (interface{}, error)")
+  code << tabs(1, "return bsonmodel.GetPath(self, xpath)")
+  code << "}\n\n"
+  fill_method(code, cfg, "SetPath(xpath bsonmodel.DotNotation, value interface{}) error")
+  code << tabs(1, "return bsonmodel.SetPath(self, xpath, value)")
+  code << "}\n\n"
+end
+
+# Marks the entry of the map value updated in its parent map.
+def emit_updated(n, cfg)
+  cfg['type'] == 'map-value' ? tabs(n, "self.EmitUpdated()") : ''
+end
+
+def emit_change(n, field, old_value, new_value)
+  tabs(n, "bsonmodel.EmitChange(self, \"#{field['bname']}\", #{old_value}, #{new_value})")
+end
+
 def fill_xetters(code, cfg)
   cfg['fields'].each_with_index do |field, index|
     name = field['name']
     camel = to_camel(name)
+    checks = partial_checks(cfg, field)
     case field['type']
     when 'int'
       if field['virtual'] == true
-        code << "func (self *default#{cfg['name']}) #{camel}() int {\n"
+        fill_method(code, cfg, "#{camel}() int", checks)
         unless field.has_key? 'formula'
           raise "missing required field `formula` on #{cfg['name']}.#{name}"
         end
         code << tabs(1, "return #{field['formula']}")
         code << "}\n\n"
       else
-        code << "func (self *default#{cfg['name']}) #{camel}() int {\n"
+        fill_method(code, cfg, "#{camel}() int", checks)
         code << tabs(1, "return self.#{name}")
         code << "}\n\n"
-        code << "func (self *default#{cfg['name']}) Set#{camel}(#{name} int) {\n"
+        fill_method(code, cfg, "Set#{camel}(#{name} int)", checks)
         code << tabs(1, "if self.#{name} != #{name} {")
+        code << tabs(2, "bsonmodel.SaveState(self)")
+        code << tabs(2, "old := self.#{name}")
         code << tabs(2, "self.#{name} = #{name}")
         code << tabs(2, "self.updatedFields.Set(#{index + 1})")
         if field.has_key? 'relations'
@@ -848,13 +1617,13 @@ def fill_xetters(code, cfg)
             code << tabs(2, "self.updatedFields.Set(#{relation_index})")
           end
         end
-        if cfg['type'] == 'map-value'
-          code << tabs(2, "self.EmitUpdated()")
-        end
+        code << emit_updated(2, cfg)
+        code << emit_change(2, field, 'old', name)
         code << tabs(1, "}")
         code << "}\n\n"
         if field['increase'] == true
-          code << "func (self *default#{cfg['name']}) Increase#{camel}() int {\n"
+          fill_method(code, cfg, "Increase#{camel}() int", checks)
+          code << tabs(1, "bsonmodel.SaveState(self)")
           code << tabs(1, "#{name} := self.#{name} + 1")
           code << tabs(1, "self.#{name} = #{name}")
           code << tabs(1, "self.updatedFields.Set(#{index + 1})")
@@ -863,11 +1632,14 @@ def fill_xetters(code, cfg)
               code << tabs(2, "self.updatedFields.Set(#{relation_index})")
             end
           end
+          code << emit_updated(1, cfg)
+          code << emit_change(1, field, "#{name}-1", name)
           code << tabs(1, "return #{name}")
           code << "}\n\n"
         end
         if field['add'] == true
-          code << "func (self *default#{cfg['name']}) Add#{camel}(#{name} int) int {\n"
+          fill_method(code, cfg, "Add#{camel}(#{name} int) int", checks)
+          code << tabs(1, "bsonmodel.SaveState(self)")
           code << tabs(1, "new_#{name} := self.#{name} + #{name}")
           code << tabs(1, "self.#{name} = new_#{name}")
           code << tabs(1, "self.updatedFields.Set(#{index + 1})")
@@ -876,24 +1648,28 @@ def fill_xetters(code, cfg)
               code << tabs(2, "self.updatedFields.Set(#{relation_index})")
             end
           end
+          code << emit_updated(1, cfg)
+          code << emit_change(1, field, "new_#{name}-#{name}", "new_#{name}")
           code << tabs(1, "return new_#{name}")
           code << "}\n\n"
         end
       end
     when 'string'
       if field['virtual'] == true
-        code << "func (self *default#{cfg['name']}) #{camel}() string {\n"
+        fill_method(code, cfg, "#{camel}() string", checks)
         unless field.has_key? 'formula'
           raise "missing required field `formula` on #{cfg['name']}.#{name}"
         end
         code << tabs(1, "return #{field['formula']}")
         code << "}\n\n"
       else
-        code << "func (self *default#{cfg['name']}) #{camel}() string {\n"
+        fill_method(code, cfg, "#{camel}() string", checks)
         code << tabs(1, "return self.#{name}")
         code << "}\n\n"
-        code << "func (self *default#{cfg['name']}) Set#{camel}(#{name} string) {\n"
+        fill_method(code, cfg, "Set#{camel}(#{name} string)", checks)
         code << tabs(1, "if self.#{name} != #{name} {")
+        code << tabs(2, "bsonmodel.SaveState(self)")
+        code << tabs(2, "old := self.#{name}")
         code << tabs(2, "self.#{name} = #{name}")
         code << tabs(2, "self.updatedFields.Set(#{index + 1})")
         if field.has_key? 'relations'
@@ -901,23 +1677,27 @@ def fill_xetters(code, cfg)
             code << tabs(2, "self.updatedFields.Set(#{relation_index})")
           end
         end
+        code << emit_updated(2, cfg)
+        code << emit_change(2, field, 'old', name)
         code << tabs(1, "}")
         code << "}\n\n"
       end
     when 'float64'
       if field['virtual'] == true
-        code << "func (self *default#{cfg['name']}) #{camel}() float64 {\n"
+        fill_method(code, cfg, "#{camel}() float64", checks)
         unless field.has_key? 'formula'
           raise "missing required field `formula` on #{cfg['name']}.#{name}"
         end
         code << tabs(1, "return #{field['formula']}")
         code << "}\n\n"
       else
-        code << "func (self *default#{cfg['name']}) #{camel}() float64 {\n"
+        fill_method(code, cfg, "#{camel}() float64", checks)
         code << tabs(1, "return self.#{name}")
         code << "}\n\n"
-        code << "func (self *default#{cfg['name']}) Set#{camel}(#{name} float64) {\n"
+        fill_method(code, cfg, "Set#{camel}(#{name} float64)", checks)
         code << tabs(1, "if self.#{name} != #{name} {")
+        code << tabs(2, "bsonmodel.SaveState(self)")
+        code << tabs(2, "old := self.#{name}")
         code << tabs(2, "self.#{name} = #{name}")
         code << tabs(2, "self.updatedFields.Set(#{index + 1})")
         if field.has_key? 'relations'
@@ -925,23 +1705,27 @@ def fill_xetters(code, cfg)
             code << tabs(2, "self.updatedFields.Set(#{relation_index})")
           end
         end
+        code << emit_updated(2, cfg)
+        code << emit_change(2, field, 'old', name)
         code << tabs(1, "}")
         code << "}\n\n"
       end
     when 'datetime'
       if field['virtual'] == true
-        code << "func (self *default#{cfg['name']}) #{camel}() time.Time {\n"
+        fill_method(code, cfg, "#{camel}() time.Time", checks)
         unless field.has_key? 'formula'
           raise "missing required field `formula` on #{cfg['name']}.#{name}"
         end
         code << tabs(1, "return #{field['formula']}")
         code << "}\n\n"
       else
-        code << "func (self *default#{cfg['name']}) #{camel}() time.Time {\n"
+        fill_method(code, cfg, "#{camel}() time.Time", checks)
         code << tabs(1, "return self.#{name}")
         code << "}\n\n"
-        code << "func (self *default#{cfg['name']}) Set#{camel}(#{name} time.Time) {\n"
+        fill_method(code, cfg, "Set#{camel}(#{name} time.Time)", checks)
         code << tabs(1, "if self.#{name} != #{name} {")
+        code << tabs(2, "bsonmodel.SaveState(self)")
+        code << tabs(2, "old := self.#{name}")
         code << tabs(2, "self.#{name} = #{name}")
         code << tabs(2, "self.updatedFields.Set(#{index + 1})")
         if field.has_key? 'relations'
@@ -949,23 +1733,27 @@ def fill_xetters(code, cfg)
             code << tabs(2, "self.updatedFields.Set(#{relation_index})")
           end
         end
+        code << emit_updated(2, cfg)
+        code << emit_change(2, field, 'old', name)
         code << tabs(1, "}")
         code << "}\n\n"
       end
     when 'date'
       if field['virtual'] == true
-        code << "func (self *default#{cfg['name']}) #{camel}() time.Time {\n"
+        fill_method(code, cfg, "#{camel}() time.Time", checks)
         unless field.has_key? 'formula'
           raise "missing required field `formula` on #{cfg['name']}.#{name}"
         end
         code << tabs(1, "return #{field['formula']}")
         code << "}\n\n"
       else
-        code << "func (self *default#{cfg['name']}) #{camel}() time.Time {\n"
+        fill_method(code, cfg, "#{camel}() time.Time", checks)
         code << tabs(1, "return self.#{name}")
         code << "}\n\n"
-        code << "func (self *default#{cfg['name']}) Set#{camel}(#{name} time.Time) {\n"
+        fill_method(code, cfg, "Set#{camel}(#{name} time.Time)", checks)
         code << tabs(1, "if self.#{name} != #{name} {")
+        code << tabs(2, "bsonmodel.SaveState(self)")
+        code << tabs(2, "old := self.#{name}")
         code << tabs(2, "self.#{name} = #{name}")
         code << tabs(2, "self.updatedFields.Set(#{index + 1})")
         if field.has_key? 'relations'
@@ -973,9 +1761,13 @@ def fill_xetters(code, cfg)
             code << tabs(2, "self.updatedFields.Set(#{relation_index})")
           end
         end
+        code << emit_updated(2, cfg)
+        code << emit_change(2, field, 'old', name)
         code << tabs(1, "}")
         code << "}\n\n"
-        code << "func (self *default#{cfg['name']}) Set#{camel}Number(#{name} int) {\n"
+        fill_method(code, cfg, "Set#{camel}Number(#{name} int)", checks)
+        code << tabs(1, "bsonmodel.SaveState(self)")
+        code << tabs(1, "old := self.#{name}")
         code << tabs(1, "self.#{name} = bsonmodel.NumberToDate(#{name})")
         code << tabs(1, "self.updatedFields.Set(#{index + 1})")
         if field.has_key? 'relations'
@@ -983,20 +1775,22 @@ def fill_xetters(code, cfg)
             code << tabs(2, "self.updatedFields.Set(#{relation_index})")
           end
         end
+        code << emit_updated(1, cfg)
+        code << emit_change(1, field, 'old', "self.#{name}")
         code << "}\n\n"
       end
     when 'object'
-      code << "func (self *default#{cfg['name']}) #{camel}() #{field['model']} {\n"
+      fill_method(code, cfg, "#{camel}() #{field['model']}", checks)
       code << tabs(1, "return self.#{name}")
       code << "}\n\n"
     when 'map'
       key_type = field['key']
       value_type = field['value']
-      code << "func (self *default#{cfg['name']}) #{camel}() #{map_type(key_type)} {\n"
+      fill_method(code, cfg, "#{camel}() #{map_type(key_type)}", checks)
       code << tabs(1, "return self.#{name}")
       code << "}\n\n"
       if field.has_key? 'quick-access-method'
-        code << "func (self *default#{cfg['name']}) #{field['quick-access-method']}(id #{key_type}) #{value_type} {\n"
+        fill_method(code, cfg, "#{field['quick-access-method']}(id #{key_type}) #{value_type}", checks)
         code << tabs(1, "value := self.#{name}.Get(id)")
         code << tabs(1, "if value == nil {")
         code << tabs(2, "return nil")
@@ -1004,7 +1798,7 @@ def fill_xetters(code, cfg)
         code << tabs(1, "return value.(#{value_type})")
         code << "}\n\n"
       elsif camel.end_with? 's'
-        code << "func (self *default#{cfg['name']}) #{camel[0..-2]}(id #{key_type}) #{value_type} {\n"
+        fill_method(code, cfg, "#{camel[0..-2]}(id #{key_type}) #{value_type}", checks)
         code << tabs(1, "value := self.#{name}.Get(id)")
         code << tabs(1, "if value == nil {")
         code << tabs(2, "return nil")
@@ -1014,15 +1808,17 @@ def fill_xetters(code, cfg)
       end
     when 'simple-map'
       key_type = field['key']
-      code << "func (self *default#{cfg['name']}) #{camel}() #{simple_map_type(key_type)} {\n"
+      fill_method(code, cfg, "#{camel}() #{simple_map_type(key_type)}", checks)
       code << tabs(1, "return self.#{name}")
       code << "}\n\n"
     when 'simple-list'
       value_type = field['value']
-      code << "func (self *default#{cfg['name']}) #{camel}() []#{value_type} {\n"
+      fill_method(code, cfg, "#{camel}() []#{value_type}", checks)
       code << tabs(1, "return self.#{name}")
       code << "}\n\n"
-      code << "func (self *default#{cfg['name']}) Set#{camel}(#{name} []#{value_type}) {\n"
+      fill_method(code, cfg, "Set#{camel}(#{name} []#{value_type})", checks)
+      code << tabs(1, "bsonmodel.SaveState(self)")
+      code << tabs(1, "old := self.#{name}")
       code << tabs(1, "self.#{name} = #{name}")
       code << tabs(1, "self.updatedFields.Set(#{index + 1})")
       if field.has_key? 'relations'
@@ -1030,11 +1826,120 @@ def fill_xetters(code, cfg)
           code << tabs(1, "self.updatedFields.Set(#{relation_index})")
         end
       end
+      code << emit_updated(1, cfg)
+      code << emit_change(1, field, 'old', name)
       code << "}\n\n"
     else
       raise "unsupported field type `#{field['type']}` on #{cfg['name']}.#{field['name']}"
     end
+    fill_change_listener(code, cfg, field)
+  end
+end
+
+# The Go type of the values in the change events of the field, or nil if the
+# field has no setter.
+def change_value_type(field)
+  return nil if field['virtual'] == true
+  case field['type']
+  when 'int', 'string', 'float64'
+    field['type']
+  when 'datetime', 'date'
+    'time.Time'
+  when 'simple-list'
+    "[]#{field['value']}"
+  end
+end
+
+def change_listener_signature(field)
+  value_type = change_value_type(field)
+  "On#{to_camel(field['name'])}Changed(listener func(oldValue #{value_type}, newValue #{value_type})) (remove func(), err error)"
+end
+
+def fill_change_listener(code, cfg, field)
+  value_type = change_value_type(field)
+  return if value_type.nil?
+  fill_method(code, cfg, "#{change_listener_signature(field)}")
+  code << tabs(1, "return bsonmodel.ObserveField(self, Bname#{cfg['name']}#{to_camel(field['name'])}, func(event *bsonmodel.ChangeEvent) {")
+  code << tabs(2, "listener(event.OldValue.(#{value_type}), event.NewValue.(#{value_type}))")
+  code << tabs(1, "})")
+  code << "}\n\n"
+end
+
+# Checks the fields accessed by the accessors of the field are loaded, for
+# partially loaded root models.
+def partial_checks(cfg, field)
+  return '' unless cfg['type'] == 'root'
+  partial_sources(cfg, field).map { |f| tabs(1, "self.partial.Check(Bname#{cfg['name']}#{to_camel(f['name'])})") }.join
+end
+
+
+# The stored fields which the field is computed from.
+def partial_sources(cfg, field)
+  if field['virtual'] == true
+    cfg['fields'].select { |f| field['sources'].include?(f['name']) }
+  else
+    [field]
+  end
+end
+
+# The condition that the field is loaded, for partially loaded root models.
+def partial_loaded(cfg, field, receiver)
+  partial_sources(cfg, field).map { |f| "#{receiver}.partial.Loaded(Bname#{cfg['name']}#{to_camel(f['name'])})" }.join(' && ')
+end
+
+def fill_clone(code, cfg)
+  fill_method(code, cfg, "Clone() #{cfg['name']}")
+  if cfg['type'] == 'object'
+    code << tabs(1, "clone := New#{cfg['name']}(nil)")
+  else
+    code << tabs(1, "clone := New#{cfg['name']}()")
+  end
+  code << tabs(1, "clone.CopyFrom(self)")
+  code << tabs(1, "clone.Reset()")
+  code << tabs(1, "return clone")
+  code << "}\n\n"
+  fill_method(code, cfg, "CopyFrom(other #{cfg['name']})")
+  is_root = cfg['type'] == 'root'
+  if is_root
+    # only the loaded fields are copied from partially loaded models
+    code << tabs(1, "self.partial = other.(*default#{cfg['name']}).partial")
+  end
+  n = is_root ? 2 : 1
+  cfg['fields'].each do |field|
+    next if field['virtual'] == true
+    name = field['name']
+    camel = to_camel(name)
+    code << tabs(1, "if other.FieldLoaded(Bname#{cfg['name']}#{camel}) {") if is_root
+    case field['type']
+    when 'int', 'string', 'float64', 'datetime', 'date'
+      code << tabs(n, "self.Set#{camel}(other.#{camel}())")
+    when 'object', 'simple-map'
+      code << tabs(n, "self.#{name}.CopyFrom(other.#{camel}())")
+    when 'map'
+      value_type = field['value']
+      code << tabs(n, "other#{camel} := other.#{camel}()")
+      code << tabs(n, "for _, key := range self.#{name}.Keys() {")
+      code << tabs(n + 1, "if other#{camel}.Get(key) == nil {")
+      code << tabs(n + 2, "self.#{name}.Remove(key)")
+      code << tabs(n + 1, "}")
+      code << tabs(n, "}")
+      code << tabs(n, "for _, key := range other#{camel}.Keys() {")
+      code << tabs(n + 1, "value := other#{camel}.Get(key).(#{value_type})")
+      code << tabs(n + 1, "if current := self.#{name}.Get(key); current != nil {")
+      code << tabs(n + 2, "current.(#{value_type}).CopyFrom(value)")
+      code << tabs(n + 1, "} else {")
+      code << tabs(n + 2, "self.#{name}.Put(key, value.Clone())")
+      code << tabs(n + 1, "}")
+      code << tabs(n, "}")
+    when 'simple-list'
+      value_camel = to_camel(field['value'])
+      code << tabs(n, "if !bsonmodel.#{value_camel}SliceEquals(self.#{name}, other.#{camel}()) {")
+      code << tabs(n + 1, "self.Set#{camel}(bsonmodel.Copy#{value_camel}Slice(other.#{camel}()))")
+      code << tabs(n, "}")
+    end
+    code << tabs(1, "}") if is_root
   end
+  code << "}\n\n"
 end
 
 def fill_new(code, cfg, has_parent = false)
@@ -1076,53 +1981,53 @@ def fill_encoder(code, cfg)
   # Encode
   code << "func (codec *#{small_camel}Encoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {\n"
   code << tabs(1, "p := ((*default#{cfg['name']})(ptr))")
-  code << tabs(1, "stream.WriteObjectStart()")
-  first = true
+  code << tabs(1, "object := bsonmodel.BeginJsonObject(stream)")
   cfg['fields'].each do |field|
     next if field['json-ignore'] == true
     name = field['name']
-    if first
-      first = false
-    else
-      code << tabs(1, "stream.WriteMore()")
+    n = 1
+    if cfg['type'] == 'root'
+      code << tabs(1, "if #{partial_loaded(cfg, field, 'p')} {")
+      n = 2
     end
-    code << tabs(1, "stream.WriteObjectField(\"#{name}\")")
+    code << tabs(n, "object.Field(\"#{name}\")")
     case field['type']
     when 'int'
       if field['virtual'] == true
-        code << tabs(1, "stream.WriteInt(p.#{to_camel(name)}())")
+        code << tabs(n, "stream.WriteInt(p.#{to_camel(name)}())")
       else
-        code << tabs(1, "stream.WriteInt(p.#{name})")
+        code << tabs(n, "stream.WriteInt(p.#{name})")
       end
     when 'string'
       if field['virtual'] == true
-        code << tabs(1, "stream.WriteString(p.#{to_camel(name)}())")
+        code << tabs(n, "stream.WriteString(p.#{to_camel(name)}())")
       else
-        code << tabs(1, "stream.WriteString(p.#{name})")
+        code << tabs(n, "stream.WriteString(p.#{name})")
       end
     when 'float64'
       if field['virtual'] == true
-        code << tabs(1, "stream.WriteFloat64(p.#{to_camel(name)}())")
+        code << tabs(n, "stream.WriteFloat64(p.#{to_camel(name)}())")
       else
-        code << tabs(1, "stream.WriteFloat64(p.#{name})")
+        code << tabs(n, "stream.WriteFloat64(p.#{name})")
       end
     when 'datetime'
       if field['virtual'] == true
-        code << tabs(1, "stream.WriteInt64(p.#{to_camel(name)}().Unix())")
+        code << tabs(n, "stream.WriteInt64(p.#{to_camel(name)}().Unix())")
       else
-        code << tabs(1, "stream.WriteInt64(p.#{name}.Unix())")
+        code << tabs(n, "stream.WriteInt64(p.#{name}.Unix())")
       end
     when 'date'
       if field['virtual'] == true
-        code << tabs(1, "stream.WriteInt(bsonmodel.DateToNumber(p.#{to_camel(name)}()))")
+        code << tabs(n, "stream.WriteInt(bsonmodel.DateToNumber(p.#{to_camel(name)}()))")
       else
-        code << tabs(1, "stream.WriteInt(bsonmodel.DateToNumber(p.#{name}))")
+        code << tabs(n, "stream.WriteInt(bsonmodel.DateToNumber(p.#{name}))")
       end
     else
-      code << tabs(1, "stream.WriteVal(p.#{name})")
+      code << tabs(n, "stream.WriteVal(p.#{name})")
     end
+    code << tabs(1, "}") if cfg['type'] == 'root'
   end
-  code << tabs(1, "stream.WriteObjectEnd()")
+  code << tabs(1, "object.End()")
   code << "}\n\n"
   # init
   code << "func init() {\n"
@@ -1136,11 +2041,14 @@ def generate_root(cfg)
   fill_imports(code, cfg)
   fill_interface(code, 'bsonmodel.RootModel', cfg)
   fill_const(code, cfg)
+  fill_schema(code, cfg)
   fill_struct(code, cfg)
   fill_to_bson(code, cfg)
   fill_to_data(code, cfg)
   fill_load_jsoniter(code, cfg, true)
+  fill_load_json_iterator(code, cfg, true)
   fill_reset(code, cfg)
+  fill_clear(code, cfg)
   fill_any_updated(code, cfg)
   fill_any_deleted(code, cfg)
   code << "func (self *default#{cfg['name']}) Parent() bsonmodel.BsonModel {\n"
@@ -1152,36 +2060,120 @@ def generate_root(cfg)
   fill_append_updates(code, cfg)
   fill_to_document(code, cfg)
   fill_load_document(code, cfg, true)
+  fill_load_raw(code, cfg, true)
+  fill_load_document_tracked(code, cfg)
   fill_deleted_size(code, cfg)
   fill_fully_update(code, cfg, true)
+  fill_snapshot(code, cfg)
   fill_to_sync(code, cfg, true)
   fill_to_delete(code, cfg)
   fill_to_x_json(code, cfg)
-  code << "func (self *default#{cfg['name']}) ToUpdate() bson.M {\n"
+  fill_encode(code, cfg, true)
+  fill_to_patch(code, cfg)
+  fill_path_accessors(code, cfg)
+  fill_method(code, cfg, "ToUpdate() bson.M")
   code << tabs(1, "if self.AnyUpdated() {")
-  code << tabs(2, "return self.AppendUpdates(bson.M{})")
+  code << tabs(2, "return self.partial.FilterUpdates(self.AppendUpdates(bson.M{}))")
   code << tabs(1, "}")
   code << tabs(1, "return bson.M{}")
   code << "}\n\n"
-  code << "func (self *default#{cfg['name']}) MarshalToJsonString() (string, error) {\n"
+  fill_method(code, cfg, "ToOrderedDocument() bson.D")
+  code << tabs(1, "return bsonmodel.OrderDocument(self, self.ToDocument())")
+  code << "}\n\n"
+  fill_method(code, cfg, "ToOrderedUpdate() bson.D")
+  code << tabs(1, "return bsonmodel.OrderUpdates(self, self.ToUpdate())")
+  code << "}\n\n"
+  fill_method(code, cfg, "LoadPartialDocument(document bson.M, projection bson.M) error")
+  code << tabs(1, "partial, err := bsonmodel.PartialStateOf(#{to_small_camel(cfg['name'])}Schema, projection)")
+  code << tabs(1, "if err != nil {")
+  code << tabs(2, "return err")
+  code << tabs(1, "}")
+  code << tabs(1, "err = self.LoadDocument(document)")
+  code << tabs(1, "if err != nil {")
+  code << tabs(2, "return err")
+  code << tabs(1, "}")
+  code << tabs(1, "self.partial = partial")
+  code << tabs(1, "return nil")
+  code << "}\n\n"
+  fill_method(code, cfg, "FieldLoaded(bname string) bool")
+  code << tabs(1, "return self.partial.Loaded(bname)")
+  code << "}\n\n"
+  fill_method(code, cfg, "MarshalToJsonString() (string, error)")
   code << tabs(1, "return jsoniter.MarshalToString(self)")
   code << "}\n\n"
-  code << "func (self *default#{cfg['name']}) MarshalJSON() ([]byte, error) {\n"
+  fill_method(code, cfg, "MarshalJSON() ([]byte, error)")
   code << tabs(1, "return jsoniter.Marshal(self)")
   code << "}\n\n"
+  fill_method(code, cfg, "UnmarshalJSON(data []byte) error")
+  code << tabs(1, "iter := jsoniter.ConfigDefault.BorrowIterator(data)")
+  code << tabs(1, "defer jsoniter.ConfigDefault.ReturnIterator(iter)")
+  code << tabs(1, "return self.LoadJsonIterator(iter)")
+  code << "}\n\n"
+  fill_method(code, cfg, "UnmarshalDataJson(data []byte) error")
+  code << tabs(1, "iter := jsoniter.ConfigDefault.BorrowIterator(data)")
+  code << tabs(1, "defer jsoniter.ConfigDefault.ReturnIterator(iter)")
+  code << tabs(1, "return self.LoadJsonIterator(iter)")
+  code << "}\n\n"
+  fill_method(code, cfg, "MarshalBSON() ([]byte, error)")
+  code << tabs(1, "return bson.Marshal(self.ToDocument())")
+  code << "}\n\n"
+  fill_method(code, cfg, "UnmarshalBSON(data []byte) error")
+  code << tabs(1, "return self.LoadRaw(data)")
+  code << "}\n\n"
+  fill_method(code, cfg, "Observers() *bsonmodel.ChangeObservers")
+  code << tabs(1, "return &self.observers")
+  code << "}\n\n"
+  fill_method(code, cfg, "Transaction() *bsonmodel.Transaction")
+  code << tabs(1, "return &self.transaction")
+  code << "}\n\n"
+  fill_method(code, cfg, "SetSyncReplaceMarker(enabled bool)")
+  code << tabs(1, "self.replaceMarker = enabled")
+  code << "}\n\n"
+  fill_method(code, cfg, "SyncReplaceMarker() bool")
+  code << tabs(1, "return self.replaceMarker")
+  code << "}\n\n"
+  fill_method(code, cfg, "Begin() error")
+  code << tabs(1, "return self.transaction.Begin()")
+  code << "}\n\n"
+  fill_method(code, cfg, "Commit() error")
+  code << tabs(1, "return self.transaction.Commit()")
+  code << "}\n\n"
+  fill_method(code, cfg, "Rollback() error")
+  code << tabs(1, "return self.transaction.Rollback()")
+  code << "}\n\n"
   fill_xetters(code, cfg)
+  fill_clone(code, cfg)
   fill_new(code, cfg)
+  fill_release(code, cfg)
   small_camel = to_small_camel(cfg['name'])
   code << "func Load#{cfg['name']}FromDocument(m bson.M) (#{small_camel} #{cfg['name']}, err error) {\n"
   code << tabs(1, "#{small_camel} = New#{cfg['name']}()")
   code << tabs(1, "err = #{small_camel}.LoadDocument(m)")
   code << tabs(1, "return")
   code << "}\n\n"
+  code << "func Load#{cfg['name']}FromJsonIterator(iter *jsoniter.Iterator) (#{small_camel} #{cfg['name']}, err error) {\n"
+  code << tabs(1, "#{small_camel} = New#{cfg['name']}()")
+  code << tabs(1, "err = #{small_camel}.LoadJsonIterator(iter)")
+  code << tabs(1, "return")
+  code << "}\n\n"
+  code << "func Load#{cfg['name']}FromRaw(raw bson.Raw) (#{small_camel} #{cfg['name']}, err error) {\n"
+  code << tabs(1, "#{small_camel} = New#{cfg['name']}()")
+  code << tabs(1, "err = #{small_camel}.LoadRaw(raw)")
+  code << tabs(1, "return")
+  code << "}\n\n"
   code << "func Load#{cfg['name']}FromJsoniter(any jsoniter.Any) (#{small_camel} #{cfg['name']}, err error) {\n"
   code << tabs(1, "#{small_camel} = New#{cfg['name']}()")
   code << tabs(1, "err = #{small_camel}.LoadJsoniter(any)")
   code << tabs(1, "return")
   code << "}\n\n"
+  code << "func Load#{cfg['name']}Partially(m bson.M, projection bson.M) (#{small_camel} #{cfg['name']}, err error) {\n"
+  code << tabs(1, "#{small_camel} = New#{cfg['name']}()")
+  code << tabs(1, "err = #{small_camel}.LoadPartialDocument(m, projection)")
+  code << tabs(1, "return")
+  code << "}\n\n"
+  fill_projection(code, cfg)
+  fill_paths(code, cfg, true)
+  fill_indexes(code, cfg)
   fill_encoder(code, cfg)
   code << "\n"
 end
@@ -1191,6 +2183,7 @@ def generate_object(cfg)
   fill_imports(code, cfg)
   fill_interface(code, 'bsonmodel.ObjectModel', cfg)
   fill_const(code, cfg)
+  fill_schema(code, cfg)
   static_xpath = all_object(cfg)
   if static_xpath
     parent = cfg['parent']
@@ -1206,7 +2199,9 @@ def generate_object(cfg)
   fill_to_bson(code, cfg)
   fill_to_data(code, cfg)
   fill_load_jsoniter(code, cfg)
+  fill_load_json_iterator(code, cfg)
   fill_reset(code, cfg)
+  fill_clear(code, cfg)
   fill_any_updated(code, cfg)
   fill_any_deleted(code, cfg)
   code << "func (self *default#{cfg['name']}) Parent() bsonmodel.BsonModel {\n"
@@ -1218,22 +2213,30 @@ def generate_object(cfg)
     code << "}\n\n"
   else
     code << "func (self *default#{cfg['name']}) XPath() bsonmodel.DotNotation {\n"
-    code << tabs(1, "return self.parent.XPath().Resolve(#{cfg['bname']})")
+    code << tabs(1, "return self.xpath.Resolve(self.parent.XPath(), \"#{cfg['bname']}\")")
     code << "}\n\n"
   end
   fill_append_updates(code, cfg)
   fill_to_document(code, cfg)
   fill_load_document(code, cfg)
+  fill_load_raw(code, cfg)
+  fill_load_document_tracked(code, cfg)
   fill_deleted_size(code, cfg)
   fill_fully_update(code, cfg)
+  fill_snapshot(code, cfg)
   fill_to_sync(code, cfg)
   fill_to_delete(code, cfg)
   fill_to_x_json(code, cfg)
-  code << "func (self *default#{cfg['name']}) MarshalJSON() ([]byte, error) {\n"
+  fill_encode(code, cfg)
+  fill_to_patch(code, cfg)
+  fill_path_accessors(code, cfg)
+  fill_method(code, cfg, "MarshalJSON() ([]byte, error)")
   code << tabs(1, "return jsoniter.Marshal(self)")
   code << "}\n\n"
   fill_xetters(code, cfg)
+  fill_clone(code, cfg)
   fill_new(code, cfg, true)
+  fill_paths(code, cfg)
   fill_encoder(code, cfg)
   code << "\n"
 end
@@ -1244,25 +2247,35 @@ def generate_map_value(cfg)
   key_type = cfg['key']
   fill_interface(code, map_value_type(key_type), cfg)
   fill_const(code, cfg)
+  fill_schema(code, cfg)
   fill_struct(code, cfg, map_value_struct(key_type))
   fill_to_bson(code, cfg)
   fill_to_data(code, cfg)
   fill_load_jsoniter(code, cfg)
+  fill_load_json_iterator(code, cfg)
   fill_reset(code, cfg)
+  fill_clear(code, cfg)
   fill_any_updated(code, cfg)
   fill_any_deleted(code, cfg)
   fill_append_updates(code, cfg)
   fill_to_document(code, cfg)
   fill_load_document(code, cfg)
+  fill_load_raw(code, cfg)
+  fill_load_document_tracked(code, cfg)
   fill_deleted_size(code, cfg)
   fill_fully_update(code, cfg)
+  fill_snapshot(code, cfg)
   fill_to_sync(code, cfg)
   fill_to_delete(code, cfg)
   fill_to_x_json(code, cfg)
-  code << "func (self *default#{cfg['name']}) MarshalJSON() ([]byte, error) {\n"
+  fill_encode(code, cfg)
+  fill_to_patch(code, cfg)
+  fill_path_accessors(code, cfg)
+  fill_method(code, cfg, "MarshalJSON() ([]byte, error)")
   code << tabs(1, "return jsoniter.Marshal(self)")
   code << "}\n\n"
   fill_xetters(code, cfg)
+  fill_clone(code, cfg)
   fill_new(code, cfg)
   small_camel = to_small_camel(cfg['name'])
   code << "var #{small_camel}Factory #{map_value_factory(key_type)} = func() #{map_value_type(key_type)} {\n"
@@ -1271,15 +2284,380 @@ def generate_map_value(cfg)
   code << "func #{cfg['name']}Factory() #{map_value_factory(key_type)} {\n"
   code << tabs(1, "return #{small_camel}Factory")
   code << "}\n\n"
+  fill_paths(code, cfg)
   fill_encoder(code, cfg)
   code << "\n"
 end
 
+def ts_simple_type(value_type)
+  case value_type
+  when 'int', 'float64', 'datetime', 'date'
+    'number'
+  when 'string'
+    'string'
+  when 'bool'
+    'boolean'
+  else
+    raise "unsupported value type `#{value_type}` for typescript"
+  end
+end
+
+def ts_field_type(field, suffix = '')
+  case field['type']
+  when 'object'
+    "#{field['model']}#{suffix}"
+  when 'map'
+    "{ [key: string]: #{field['value']}#{suffix} }"
+  when 'simple-map'
+    "{ [key: string]: #{ts_simple_type(field['value'])} }"
+  when 'simple-list'
+    "#{ts_simple_type(field['value'])}[]"
+  else
+    ts_simple_type(field['type'])
+  end
+end
+
+def ts_property(name)
+  if name =~ /\A[A-Za-z_$][A-Za-z0-9_$]*\z/
+    name
+  else
+    "'#{name}'"
+  end
+end
+
+def fill_ts_interfaces(code, cfg)
+  name = cfg['name']
+  fields = cfg['fields']
+  # same structure as ToData()
+  code << "export interface #{name}Data {\n"
+  fields.each do |field|
+    next if field['virtual'] == true
+    optional = field['type'] == 'simple-list' ? '?' : ''
+    code << "  #{ts_property(field['bname'])}#{optional}: #{ts_field_type(field, 'Data')};\n"
+  end
+  code << "}\n\n"
+  # same structure as MarshalToJsonString()
+  code << "export interface #{name} {\n"
+  fields.each do |field|
+    next if field['json-ignore'] == true
+    nullable = field['type'] == 'simple-list' ? ' | null' : ''
+    code << "  #{ts_property(field['name'])}: #{ts_field_type(field)}#{nullable};\n"
+  end
+  code << "}\n\n"
+  # same structure as ToSync()
+  code << "export interface #{name}Sync {\n"
+  fields.each do |field|
+    next if field['json-ignore'] == true
+    nullable = field['type'] == 'simple-list' ? ' | null' : ''
+    code << "  #{ts_property(field['name'])}?: #{ts_field_type(field, 'Sync')}#{nullable};\n"
+  end
+  code << "}\n\n"
+  # same structure as ToDelete()
+  code << "export interface #{name}Delete {\n"
+  fields.each do |field|
+    next if field['json-ignore'] == true
+    case field['type']
+    when 'object'
+      code << "  #{ts_property(field['name'])}?: #{field['model']}Delete;\n"
+    when 'map', 'simple-map'
+      code << "  #{ts_property(field['name'])}?: { [key: string]: 1 };\n"
+    when 'simple-list'
+      code << "  #{ts_property(field['name'])}?: 1;\n"
+    end
+  end
+  code << "}\n\n"
+end
+
+def fill_ts_appliers(code, cfg)
+  code << "type JsonObject = { [key: string]: any };\n\n"
+  code << "const REPLACE_KEY = '$replace';\n\n"
+  code << "function isJsonObject(value: any): value is JsonObject {\n"
+  code << "  return typeof value === 'object' && value !== null && !Array.isArray(value);\n"
+  code << "}\n\n"
+  code << "export function applySync(state: object, sync: object): void {\n"
+  code << "  const target = state as JsonObject;\n"
+  code << "  const source = sync as JsonObject;\n"
+  code << "  for (const key of Object.keys(source)) {\n"
+  code << "    const value = source[key];\n"
+  code << "    if (isJsonObject(value) && value[REPLACE_KEY] === true) {\n"
+  code << "      const replaced = { ...value };\n"
+  code << "      delete replaced[REPLACE_KEY];\n"
+  code << "      target[key] = replaced;\n"
+  code << "    } else if (isJsonObject(value) && isJsonObject(target[key])) {\n"
+  code << "      applySync(target[key], value);\n"
+  code << "    } else {\n"
+  code << "      target[key] = value;\n"
+  code << "    }\n"
+  code << "  }\n"
+  code << "}\n\n"
+  code << "function deleteKeys(target: JsonObject, keys: { [key: string]: 1 }): void {\n"
+  code << "  for (const key of Object.keys(keys)) {\n"
+  code << "    delete target[key];\n"
+  code << "  }\n"
+  code << "}\n"
+  cfg['objects'].each do |model|
+    next unless model['type'] == 'root'
+    name = model['name']
+    code << "\n"
+    code << "export function apply#{name}Sync(state: #{name}, sync: #{name}Sync): void {\n"
+    code << "  applySync(state, sync);\n"
+    code << "}\n"
+  end
+  cfg['objects'].each do |model|
+    next if model['type'] == 'map-value'
+    fill_ts_delete_applier(code, model)
+  end
+end
+
+def fill_ts_delete_applier(code, cfg)
+  name = cfg['name']
+  code << "\n"
+  code << "export function apply#{name}Delete(state: #{name}, del: #{name}Delete): void {\n"
+  cfg['fields'].each do |field|
+    next if field['json-ignore'] == true
+    property = field['name']
+    case field['type']
+    when 'object'
+      code << "  if (del.#{property} !== undefined) {\n"
+      code << "    apply#{field['model']}Delete(state.#{property}, del.#{property});\n"
+      code << "  }\n"
+    when 'map', 'simple-map'
+      code << "  if (del.#{property} !== undefined) {\n"
+      code << "    deleteKeys(state.#{property}, del.#{property});\n"
+      code << "  }\n"
+    when 'simple-list'
+      code << "  if (del.#{property} !== undefined) {\n"
+      code << "    // removed list is encoded as null\n"
+      code << "    state.#{property} = null;\n"
+      code << "  }\n"
+    end
+  end
+  code << "}\n"
+end
+
+def generate_typescript(cfg)
+  code = ''
+  cfg['objects'].each do |model|
+    fill_ts_interfaces(code, model)
+  end
+  fill_ts_appliers(code, cfg)
+  code
+end
+
+def spaces(n, value = '')
+  "    " * n + value + "\n"
+end
+
+def cs_value_type(value_type, long = false)
+  case value_type
+  when 'int'
+    long ? 'long' : 'int'
+  when 'float64'
+    'double'
+  when 'string'
+    'string'
+  when 'bool'
+    'bool'
+  when 'datetime', 'date'
+    'DateTime'
+  else
+    raise "unsupported value type `#{value_type}` for csharp"
+  end
+end
+
+def cs_key_type(key_type)
+  case key_type
+  when 'int'
+    'int'
+  when 'string'
+    'string'
+  else
+    raise "unsupported key type `#{key_type}` for csharp"
+  end
+end
+
+def cs_field_type(field)
+  case field['type']
+  when 'object'
+    field['model']
+  when 'map'
+    "Dictionary<#{cs_key_type(field['key'])}, #{field['value']}>"
+  when 'simple-map'
+    "Dictionary<#{cs_key_type(field['key'])}, #{cs_value_type(field['value'])}>"
+  when 'simple-list'
+    "List<#{cs_value_type(field['value'])}>"
+  else
+    cs_value_type(field['type'], field['long'] == true)
+  end
+end
+
+def cs_key(key_type, name)
+  if key_type == 'int'
+    "int.Parse(#{name})"
+  else
+    name
+  end
+end
+
+def cs_simple_value(value_type, token)
+  case value_type
+  when 'datetime'
+    "SyncConverter.FromUnixMillis(#{token}.Value<long>())"
+  when 'date'
+    "SyncConverter.FromDateNumber(#{token}.Value<int>())"
+  else
+    "#{token}.Value<#{cs_value_type(value_type)}>()"
+  end
+end
+
+def fill_cs_class(code, cfg)
+  fields = cfg['fields'].reject { |field| field['json-ignore'] == true }
+  code << spaces(1, "public class #{cfg['name']}")
+  code << spaces(1, "{")
+  fields.each do |field|
+    camel = to_camel(field['name'])
+    type = cs_field_type(field)
+    if %w(object map simple-map).include? field['type']
+      code << spaces(2, "public #{type} #{camel} = new #{type}();")
+    else
+      code << spaces(2, "public #{type} #{camel};")
+    end
+  end
+  code << "\n"
+  code << spaces(2, "public void ApplySync(string json)")
+  code << spaces(2, "{")
+  code << spaces(3, "ApplySync(JObject.Parse(json));")
+  code << spaces(2, "}")
+  code << "\n"
+  code << spaces(2, "public void ApplySync(JObject sync)")
+  code << spaces(2, "{")
+  unless fields.empty?
+    code << spaces(3, "JToken token;")
+  end
+  fields.each do |field|
+    camel = to_camel(field['name'])
+    code << spaces(3, "if (sync.TryGetValue(\"#{field['name']}\", out token))")
+    code << spaces(3, "{")
+    case field['type']
+    when 'object'
+      code << spaces(4, "if (SyncConverter.IsReplace(token))")
+      code << spaces(4, "{")
+      code << spaces(5, "#{camel} = new #{field['model']}();")
+      code << spaces(4, "}")
+      code << spaces(4, "#{camel}.ApplySync((JObject)token);")
+    when 'map'
+      value_type = field['value']
+      code << spaces(4, "foreach (var property in ((JObject)token).Properties())")
+      code << spaces(4, "{")
+      code << spaces(5, "var key = #{cs_key(field['key'], 'property.Name')};")
+      code << spaces(5, "#{value_type} value;")
+      code << spaces(5, "if (!#{camel}.TryGetValue(key, out value) || SyncConverter.IsReplace(property.Value))")
+      code << spaces(5, "{")
+      code << spaces(6, "value = new #{value_type}();")
+      code << spaces(6, "#{camel}[key] = value;")
+      code << spaces(5, "}")
+      code << spaces(5, "value.ApplySync((JObject)property.Value);")
+      code << spaces(4, "}")
+    when 'simple-map'
+      code << spaces(4, "foreach (var property in ((JObject)token).Properties())")
+      code << spaces(4, "{")
+      code << spaces(5, "#{camel}[#{cs_key(field['key'], 'property.Name')}] = #{cs_simple_value(field['value'], 'property.Value')};")
+      code << spaces(4, "}")
+    when 'simple-list'
+      code << spaces(4, "#{camel} = token.Type == JTokenType.Null ? null : token.ToObject<#{cs_field_type(field)}>();")
+    when 'datetime'
+      code << spaces(4, "#{camel} = SyncConverter.FromUnixSeconds(token.Value<long>());")
+    when 'date'
+      code << spaces(4, "#{camel} = SyncConverter.FromDateNumber(token.Value<int>());")
+    else
+      code << spaces(4, "#{camel} = token.Value<#{cs_field_type(field)}>();")
+    end
+    code << spaces(3, "}")
+  end
+  code << spaces(2, "}")
+  code << "\n"
+  code << spaces(2, "public void ApplyDelete(string json)")
+  code << spaces(2, "{")
+  code << spaces(3, "ApplyDelete(JObject.Parse(json));")
+  code << spaces(2, "}")
+  code << "\n"
+  code << spaces(2, "public void ApplyDelete(JObject delete)")
+  code << spaces(2, "{")
+  deletes = fields.select { |field| %w(object map simple-map simple-list).include? field['type'] }
+  unless deletes.empty?
+    code << spaces(3, "JToken token;")
+  end
+  deletes.each do |field|
+    camel = to_camel(field['name'])
+    code << spaces(3, "if (delete.TryGetValue(\"#{field['name']}\", out token))")
+    code << spaces(3, "{")
+    case field['type']
+    when 'object'
+      code << spaces(4, "#{camel}.ApplyDelete((JObject)token);")
+    when 'map', 'simple-map'
+      code << spaces(4, "foreach (var property in ((JObject)token).Properties())")
+      code << spaces(4, "{")
+      code << spaces(5, "#{camel}.Remove(#{cs_key(field['key'], 'property.Name')});")
+      code << spaces(4, "}")
+    when 'simple-list'
+      code << spaces(4, "#{camel} = null;")
+    end
+    code << spaces(3, "}")
+  end
+  code << spaces(2, "}")
+  code << spaces(1, "}")
+end
+
+def fill_cs_converter(code)
+  code << spaces(1, "internal static class SyncConverter")
+  code << spaces(1, "{")
+  code << spaces(2, "private static readonly DateTime Epoch = new DateTime(1970, 1, 1, 0, 0, 0, DateTimeKind.Utc);")
+  code << "\n"
+  code << spaces(2, "public static DateTime FromUnixSeconds(long seconds)")
+  code << spaces(2, "{")
+  code << spaces(3, "return Epoch.AddSeconds(seconds).ToLocalTime();")
+  code << spaces(2, "}")
+  code << "\n"
+  code << spaces(2, "public static DateTime FromUnixMillis(long millis)")
+  code << spaces(2, "{")
+  code << spaces(3, "return Epoch.AddMilliseconds(millis).ToLocalTime();")
+  code << spaces(2, "}")
+  code << "\n"
+  code << spaces(2, "public static DateTime FromDateNumber(int number)")
+  code << spaces(2, "{")
+  code << spaces(3, "return new DateTime(number / 10000, number / 100 % 100, number % 100);")
+  code << spaces(2, "}")
+  code << "\n"
+  code << spaces(2, "public static bool IsReplace(JToken token)")
+  code << spaces(2, "{")
+  code << spaces(3, "var replace = token[\"$replace\"];")
+  code << spaces(3, "return replace != null && replace.Type == JTokenType.Boolean && replace.Value<bool>();")
+  code << spaces(2, "}")
+  code << spaces(1, "}")
+end
+
+def generate_csharp(cfg)
+  code = ''
+  code << "using System;\n"
+  code << "using System.Collections.Generic;\n"
+  code << "using Newtonsoft.Json.Linq;\n\n"
+  namespace = cfg.has_key?('cs-namespace') ? cfg['cs-namespace'] : to_camel(cfg['package'])
+  code << "namespace #{namespace}\n"
+  code << "{\n"
+  cfg['objects'].each do |model|
+    fill_cs_class(code, model)
+    code << "\n"
+  end
+  fill_cs_converter(code)
+  code << "}\n"
+end
+
 
 cfg = File.open(ARGV[0]) { |io| YAML.load io.read }
 
 parents = Hash.new
 bnames = Hash.new
+models = Hash.new
 map_models = Set.new
 
 if cfg.has_key? 'go-package'
@@ -1291,6 +2669,7 @@ end
 
 cfg['objects'].each do |model|
   model['package'] = cfg['package']
+  model['models'] = models
   unless model.has_key? 'name'
     raise "missing required field `name`"
   end
@@ -1300,6 +2679,7 @@ cfg['objects'].each do |model|
   unless model['file'].end_with? '.go'
     model['file'] = "#{model['file']}.go"
   end
+  models[model['name']] = model
   model['fields'].each_with_index do |field, index|
     if field['type'] == 'object'
       parents[field['model']] = model
@@ -1319,9 +2699,13 @@ cfg['objects'].each do |model|
         end
       end
     end
+    if field['lazy'] == true && model['type'] != 'root'
+      raise "lazy is only supported on root models, but found on #{model['name']}.#{field['name']}"
+    end
     if field['type'] == 'long'
       # Compatible with java
       field['type'] = 'int'
+      field['long'] = true
     elsif field['type'] == 'map'
       map_models << field['value']
     end
@@ -1359,4 +2743,38 @@ end.each do |model|
   puts "OK"
 end
 
+if cfg.has_key? 'ts-file'
+  require 'fileutils'
+  package_dir = File.join(ARGV[1], cfg['package'])
+  unless File.directory?(package_dir)
+    FileUtils.mkdir_p(package_dir)
+  end
+  filename = cfg['ts-file']
+  unless filename.end_with? '.ts'
+    filename = "#{filename}.ts"
+  end
+  puts "Generating #{filename} ... (on path: #{package_dir})"
+  File.open(File.join(package_dir, filename), "w") do |io|
+    io.syswrite(generate_typescript(cfg))
+  end
+  puts "OK"
+end
+
+if cfg.has_key? 'cs-file'
+  require 'fileutils'
+  package_dir = File.join(ARGV[1], cfg['package'])
+  unless File.directory?(package_dir)
+    FileUtils.mkdir_p(package_dir)
+  end
+  filename = cfg['cs-file']
+  unless filename.end_with? '.cs'
+    filename = "#{filename}.cs"
+  end
+  puts "Generating #{filename} ... (on path: #{package_dir})"
+  File.open(File.join(package_dir, filename), "w") do |io|
+    io.syswrite(generate_csharp(cfg))
+  end
+  puts "OK"
+end
+
 puts "Done."
@@ -0,0 +1,305 @@
+using System;
+using System.Collections.Generic;
+using Newtonsoft.Json.Linq;
+
+namespace Example
+{
+    public class Player
+    {
+        public int Uid;
+        public Wallet Wallet = new Wallet();
+        public Dictionary<string, Equipment> Equipments = new Dictionary<string, Equipment>();
+        public Dictionary<int, int> Items = new Dictionary<int, int>();
+        public CashInfo Cash = new CashInfo();
+
+        public void ApplySync(string json)
+        {
+            ApplySync(JObject.Parse(json));
+        }
+
+        public void ApplySync(JObject sync)
+        {
+            JToken token;
+            if (sync.TryGetValue("uid", out token))
+            {
+                Uid = token.Value<int>();
+            }
+            if (sync.TryGetValue("wallet", out token))
+            {
+                if (SyncConverter.IsReplace(token))
+                {
+                    Wallet = new Wallet();
+                }
+                Wallet.ApplySync((JObject)token);
+            }
+            if (sync.TryGetValue("equipments", out token))
+            {
+                foreach (var property in ((JObject)token).Properties())
+                {
+                    var key = property.Name;
+                    Equipment value;
+                    if (!Equipments.TryGetValue(key, out value) || SyncConverter.IsReplace(property.Value))
+                    {
+                        value = new Equipment();
+                        Equipments[key] = value;
+                    }
+                    value.ApplySync((JObject)property.Value);
+                }
+            }
+            if (sync.TryGetValue("items", out token))
+            {
+                foreach (var property in ((JObject)token).Properties())
+                {
+                    Items[int.Parse(property.Name)] = property.Value.Value<int>();
+                }
+            }
+            if (sync.TryGetValue("cash", out token))
+            {
+                if (SyncConverter.IsReplace(token))
+                {
+                    Cash = new CashInfo();
+                }
+                Cash.ApplySync((JObject)token);
+            }
+        }
+
+        public void ApplyDelete(string json)
+        {
+            ApplyDelete(JObject.Parse(json));
+        }
+
+        public void ApplyDelete(JObject delete)
+        {
+            JToken token;
+            if (delete.TryGetValue("wallet", out token))
+            {
+                Wallet.ApplyDelete((JObject)token);
+            }
+            if (delete.TryGetValue("equipments", out token))
+            {
+                foreach (var property in ((JObject)token).Properties())
+                {
+                    Equipments.Remove(property.Name);
+                }
+            }
+            if (delete.TryGetValue("items", out token))
+            {
+                foreach (var property in ((JObject)token).Properties())
+                {
+                    Items.Remove(int.Parse(property.Name));
+                }
+            }
+            if (delete.TryGetValue("cash", out token))
+            {
+                Cash.ApplyDelete((JObject)token);
+            }
+        }
+    }
+
+    public class Wallet
+    {
+        public int CoinTotal;
+        public int Coin;
+        public long Diamond;
+
+        public void ApplySync(string json)
+        {
+            ApplySync(JObject.Parse(json));
+        }
+
+        public void ApplySync(JObject sync)
+        {
+            JToken token;
+            if (sync.TryGetValue("coinTotal", out token))
+            {
+                CoinTotal = token.Value<int>();
+            }
+            if (sync.TryGetValue("coin", out token))
+            {
+                Coin = token.Value<int>();
+            }
+            if (sync.TryGetValue("diamond", out token))
+            {
+                Diamond = token.Value<long>();
+            }
+        }
+
+        public void ApplyDelete(string json)
+        {
+            ApplyDelete(JObject.Parse(json));
+        }
+
+        public void ApplyDelete(JObject delete)
+        {
+        }
+    }
+
+    public class Equipment
+    {
+        public string Id;
+        public int RefId;
+        public int Atk;
+        public int Def;
+        public int Hp;
+
+        public void ApplySync(string json)
+        {
+            ApplySync(JObject.Parse(json));
+        }
+
+        public void ApplySync(JObject sync)
+        {
+            JToken token;
+            if (sync.TryGetValue("id", out token))
+            {
+                Id = token.Value<string>();
+            }
+            if (sync.TryGetValue("refId", out token))
+            {
+                RefId = token.Value<int>();
+            }
+            if (sync.TryGetValue("atk", out token))
+            {
+                Atk = token.Value<int>();
+            }
+            if (sync.TryGetValue("def", out token))
+            {
+                Def = token.Value<int>();
+            }
+            if (sync.TryGetValue("hp", out token))
+            {
+                Hp = token.Value<int>();
+            }
+        }
+
+        public void ApplyDelete(string json)
+        {
+            ApplyDelete(JObject.Parse(json));
+        }
+
+        public void ApplyDelete(JObject delete)
+        {
+        }
+    }
+
+    public class CashInfo
+    {
+        public Dictionary<int, int> Stages = new Dictionary<int, int>();
+        public List<int> Cards;
+        public List<string> OrderIds;
+
+        public void ApplySync(string json)
+        {
+            ApplySync(JObject.Parse(json));
+        }
+
+        public void ApplySync(JObject sync)
+        {
+            JToken token;
+            if (sync.TryGetValue("stages", out token))
+            {
+                foreach (var property in ((JObject)token).Properties())
+                {
+                    Stages[int.Parse(property.Name)] = property.Value.Value<int>();
+                }
+            }
+            if (sync.TryGetValue("cards", out token))
+            {
+                Cards = token.Type == JTokenType.Null ? null : token.ToObject<List<int>>();
+            }
+            if (sync.TryGetValue("orderIds", out token))
+            {
+                OrderIds = token.Type == JTokenType.Null ? null : token.ToObject<List<string>>();
+            }
+        }
+
+        public void ApplyDelete(string json)
+        {
+            ApplyDelete(JObject.Parse(json));
+        }
+
+        public void ApplyDelete(JObject delete)
+        {
+            JToken token;
+            if (delete.TryGetValue("stages", out token))
+            {
+                foreach (var property in ((JObject)token).Properties())
+                {
+                    Stages.Remove(int.Parse(property.Name));
+                }
+            }
+            if (delete.TryGetValue("cards", out token))
+            {
+                Cards = null;
+            }
+            if (delete.TryGetValue("orderIds", out token))
+            {
+                OrderIds = null;
+            }
+        }
+    }
+
+    public class LoginLog
+    {
+        public string Id;
+        public int Uid;
+        public DateTime LoginTime;
+
+        public void ApplySync(string json)
+        {
+            ApplySync(JObject.Parse(json));
+        }
+
+        public void ApplySync(JObject sync)
+        {
+            JToken token;
+            if (sync.TryGetValue("id", out token))
+            {
+                Id = token.Value<string>();
+            }
+            if (sync.TryGetValue("uid", out token))
+            {
+                Uid = token.Value<int>();
+            }
+            if (sync.TryGetValue("loginTime", out token))
+            {
+                LoginTime = SyncConverter.FromUnixSeconds(token.Value<long>());
+            }
+        }
+
+        public void ApplyDelete(string json)
+        {
+            ApplyDelete(JObject.Parse(json));
+        }
+
+        public void ApplyDelete(JObject delete)
+        {
+        }
+    }
+
+    internal static class SyncConverter
+    {
+        private static readonly DateTime Epoch = new DateTime(1970, 1, 1, 0, 0, 0, DateTimeKind.Utc);
+
+        public static DateTime FromUnixSeconds(long seconds)
+        {
+            return Epoch.AddSeconds(seconds).ToLocalTime();
+        }
+
+        public static DateTime FromUnixMillis(long millis)
+        {
+            return Epoch.AddMilliseconds(millis).ToLocalTime();
+        }
+
+        public static DateTime FromDateNumber(int number)
+        {
+            return new DateTime(number / 10000, number / 100 % 100, number % 100);
+        }
+
+        public static bool IsReplace(JToken token)
+        {
+            var replace = token["$replace"];
+            return replace != null && replace.Type == JTokenType.Boolean && replace.Value<bool>();
+        }
+    }
+}
@@ -0,0 +1,99 @@
+package example
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func newBenchmarkPlayer() Player {
+	createTime := time.Now().Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	for i := 0; i < 100; i++ {
+		player.Items().Put(3000+i, i)
+		equipment := NewEquipment()
+		equipment.SetId(fmt.Sprintf("equipment-%d", i))
+		equipment.SetRefId(1000 + i)
+		player.Equipments().Put(equipment.Id(), equipment)
+	}
+	player.Reset()
+	for i := 0; i < 100; i += 2 {
+		player.Items().Put(3000+i, i+1)
+		player.Equipment(fmt.Sprintf("equipment-%d", i)).SetAtk(i)
+	}
+	player.Items().Remove(3001)
+	player.Equipments().Remove("equipment-1")
+	return player
+}
+
+func BenchmarkIntSimpleMapPut(b *testing.B) {
+	items := newBenchmarkPlayer().Items()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		items.Put(3000+i%100, i)
+	}
+}
+
+func BenchmarkIntSimpleMapAppendUpdates(b *testing.B) {
+	items := newBenchmarkPlayer().Items()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		items.AppendUpdates(bson.M{})
+	}
+}
+
+func BenchmarkIntSimpleMapToSync(b *testing.B) {
+	items := newBenchmarkPlayer().Items()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		items.ToSync()
+	}
+}
+
+func BenchmarkStringObjectMapAppendUpdates(b *testing.B) {
+	equipments := newBenchmarkPlayer().Equipments()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		equipments.AppendUpdates(bson.M{})
+	}
+}
+
+func BenchmarkStringObjectMapToSync(b *testing.B) {
+	equipments := newBenchmarkPlayer().Equipments()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		equipments.ToSync()
+	}
+}
@@ -1,6 +1,7 @@
 package example
 
 import (
+	"io"
 	"unsafe"
 
 	"github.com/bits-and-blooms/bitset"
@@ -14,8 +15,12 @@ type CashInfo interface {
 	Stages() bsonmodel.IntSimpleMapModel
 	Cards() []int
 	SetCards(cards []int)
+	OnCardsChanged(listener func(oldValue []int, newValue []int)) (remove func(), err error)
 	OrderIds() []string
 	SetOrderIds(orderIds []string)
+	OnOrderIdsChanged(listener func(oldValue []string, newValue []string)) (remove func(), err error)
+	Clone() CashInfo
+	CopyFrom(other CashInfo)
 }
 
 const (
@@ -24,10 +29,25 @@ const (
 	BnameCashInfoOrderIds = "ois"
 )
 
+var cashInfoSchema = &bsonmodel.ModelSchema{
+	Name: "CashInfo",
+	Type: bsonmodel.ModelTypeObject,
+	Fields: []*bsonmodel.FieldSchema{
+		{Name: "stages", Bname: "stg", Type: bsonmodel.FieldTypeSimpleMap, KeyType: "int", ValueType: "int"},
+		{Name: "cards", Bname: "cs", Type: bsonmodel.FieldTypeSimpleList, ValueType: "int"},
+		{Name: "orderIds", Bname: "ois", Type: bsonmodel.FieldTypeSimpleList, ValueType: "string"},
+	},
+}
+
+func CashInfoSchema() *bsonmodel.ModelSchema {
+	return cashInfoSchema
+}
+
 var xpathCashInfo bsonmodel.DotNotation = bsonmodel.PathOfNames("cs")
 
 type defaultCashInfo struct {
 	updatedFields *bitset.BitSet
+	released      bool
 	parent        Player
 	stages        bsonmodel.IntSimpleMapModel
 	cards         []int
@@ -35,10 +55,12 @@ type defaultCashInfo struct {
 }
 
 func (self *defaultCashInfo) ToBson() interface{} {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	return self.ToDocument()
 }
 
 func (self *defaultCashInfo) ToData() interface{} {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	data := make(map[string]interface{})
 	data["stg"] = self.stages.ToData()
 	if self.cards != nil {
@@ -51,6 +73,8 @@ func (self *defaultCashInfo) ToData() interface{} {
 }
 
 func (self *defaultCashInfo) LoadJsoniter(any jsoniter.Any) error {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	bsonmodel.SaveState(self)
 	if any.ValueType() != jsoniter.ObjectValue {
 		return nil
 	}
@@ -76,16 +100,63 @@ func (self *defaultCashInfo) LoadJsoniter(any jsoniter.Any) error {
 	return nil
 }
 
+func (self *defaultCashInfo) LoadJsonIterator(iter *jsoniter.Iterator) error {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	bsonmodel.SaveState(self)
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		return bsonmodel.IterError(iter)
+	}
+	self.cards = nil
+	self.orderIds = nil
+	stagesLoaded := false
+	var err error
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, field string) bool {
+		switch field {
+		case BnameCashInfoStages, "stages":
+			stagesLoaded = true
+			err = self.stages.LoadJsonIterator(iter)
+		case BnameCashInfoCards, "cards":
+			self.cards, err = bsonmodel.IterIntArrayValue(iter)
+		case BnameCashInfoOrderIds, "orderIds":
+			self.orderIds, err = bsonmodel.IterStringArrayValue(iter)
+		default:
+			iter.Skip()
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	if !stagesLoaded {
+		self.stages.Clear()
+	}
+	return bsonmodel.IterError(iter)
+}
+
 func (self *defaultCashInfo) Reset() {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	bsonmodel.SaveState(self)
 	self.stages.Reset()
 	self.updatedFields.ClearAll()
 }
 
+func (self *defaultCashInfo) clear() {
+	self.stages.Discard()
+	self.cards = nil
+	self.orderIds = nil
+	if bsonmodel.Debug {
+		self.released = true
+	}
+}
+
 func (self *defaultCashInfo) AnyUpdated() bool {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	return self.updatedFields.Any() || self.stages.AnyUpdated()
 }
 
 func (self *defaultCashInfo) AnyDeleted() bool {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	return self.DeletedSize() > 0
 }
 
@@ -98,6 +169,7 @@ func (self *defaultCashInfo) XPath() bsonmodel.DotNotation {
 }
 
 func (self *defaultCashInfo) AppendUpdates(updates bson.M) bson.M {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	dset := bsonmodel.FixedEmbedded(updates, "$set")
 	xpath := self.XPath()
 	if self.FullyUpdate() {
@@ -134,6 +206,7 @@ func (self *defaultCashInfo) AppendUpdates(updates bson.M) bson.M {
 }
 
 func (self *defaultCashInfo) ToDocument() bson.M {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	doc := bson.M{}
 	doc["stg"] = self.stages.ToBson()
 	if self.cards != nil {
@@ -154,6 +227,8 @@ func (self *defaultCashInfo) ToDocument() bson.M {
 }
 
 func (self *defaultCashInfo) LoadDocument(document bson.M) error {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	bsonmodel.SaveState(self)
 	stages, err := bsonmodel.EmbeddedValue(document, "stg")
 	if err != nil {
 		return err
@@ -179,7 +254,47 @@ func (self *defaultCashInfo) LoadDocument(document bson.M) error {
 	return nil
 }
 
+func (self *defaultCashInfo) LoadRaw(raw bson.Raw) error {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	bsonmodel.SaveState(self)
+	stages, err := bsonmodel.RawEmbeddedValue(raw, "stg")
+	if err != nil {
+		return err
+	}
+	if stages != nil {
+		err = self.stages.LoadRaw(stages)
+		if err != nil {
+			return err
+		}
+	} else {
+		self.stages.Clear()
+	}
+	cards, err := bsonmodel.RawIntArrayValue(raw, "cs")
+	if err != nil {
+		return err
+	}
+	self.cards = cards
+	orderIds, err := bsonmodel.RawStringArrayValue(raw, "ois")
+	if err != nil {
+		return err
+	}
+	self.orderIds = orderIds
+	return nil
+}
+
+func (self *defaultCashInfo) LoadDocumentTracked(document bson.M) error {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	loaded := NewCashInfo(nil)
+	err := loaded.LoadDocument(document)
+	if err != nil {
+		return err
+	}
+	self.CopyFrom(loaded)
+	return nil
+}
+
 func (self *defaultCashInfo) DeletedSize() int {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	n := 0
 	if self.stages.AnyDeleted() {
 		n += 1
@@ -198,6 +313,7 @@ func (self *defaultCashInfo) FullyUpdate() bool {
 }
 
 func (self *defaultCashInfo) SetFullyUpdate(fullyUpdate bool) {
+	bsonmodel.SaveState(self)
 	if fullyUpdate {
 		self.updatedFields.Set(0)
 	} else {
@@ -205,9 +321,22 @@ func (self *defaultCashInfo) SetFullyUpdate(fullyUpdate bool) {
 	}
 }
 
+func (self *defaultCashInfo) Snapshot() func() {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	updatedFields := self.updatedFields.Clone()
+	cards := self.cards
+	orderIds := self.orderIds
+	return func() {
+		self.updatedFields = updatedFields
+		self.cards = cards
+		self.orderIds = orderIds
+	}
+}
+
 func (self *defaultCashInfo) ToSync() interface{} {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	if self.FullyUpdate() {
-		return self
+		return bsonmodel.FullySync(self)
 	}
 	sync := make(map[string]interface{})
 	updatedFields := self.updatedFields
@@ -228,6 +357,7 @@ func (self *defaultCashInfo) ToSync() interface{} {
 }
 
 func (self *defaultCashInfo) ToDelete() interface{} {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	delete := make(map[string]interface{})
 	if self.stages.AnyDeleted() {
 		delete["stages"] = self.stages.ToDelete()
@@ -242,41 +372,203 @@ func (self *defaultCashInfo) ToDelete() interface{} {
 }
 
 func (self *defaultCashInfo) ToDataJson() (string, error) {
-	return jsoniter.MarshalToString(self.ToData())
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	return bsonmodel.DataJsonOf(self)
 }
 
 func (self *defaultCashInfo) ToSyncJson() (string, error) {
-	return jsoniter.MarshalToString(self.ToSync())
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	return bsonmodel.SyncJsonOf(self)
 }
 
 func (self *defaultCashInfo) ToDeleteJson() (string, error) {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	return jsoniter.MarshalToString(self.ToDelete())
 }
 
+func (self *defaultCashInfo) EncodeData(stream *jsoniter.Stream) {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	object := bsonmodel.BeginJsonObject(stream)
+	object.Field("stg")
+	self.stages.EncodeData(stream)
+	if self.cards != nil {
+		object.Field("cs")
+		stream.WriteVal(self.cards)
+	}
+	if self.orderIds != nil {
+		object.Field("ois")
+		stream.WriteVal(self.orderIds)
+	}
+	object.End()
+}
+
+func (self *defaultCashInfo) EncodeSync(stream *jsoniter.Stream) {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	if self.FullyUpdate() {
+		stream.WriteVal(bsonmodel.FullySync(self))
+		return
+	}
+	object := bsonmodel.BeginJsonObject(stream)
+	if self.stages.AnyUpdated() {
+		object.Field("stages")
+		self.stages.EncodeSync(stream)
+	}
+	if self.updatedFields.Test(2) && self.cards != nil {
+		object.Field("cards")
+		stream.WriteVal(self.cards)
+	}
+	if self.updatedFields.Test(3) && self.orderIds != nil {
+		object.Field("orderIds")
+		stream.WriteVal(self.orderIds)
+	}
+	object.End()
+}
+
+func (self *defaultCashInfo) WriteDataJson(w io.Writer) error {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	return bsonmodel.WriteDataJson(w, self)
+}
+
+func (self *defaultCashInfo) WriteSyncJson(w io.Writer) error {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	return bsonmodel.WriteSyncJson(w, self)
+}
+
+func (self *defaultCashInfo) ToMergePatch() interface{} {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	return bsonmodel.MergePatchOf(self)
+}
+
+func (self *defaultCashInfo) ToJsonPatch() []bsonmodel.JsonPatchOperation {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	return bsonmodel.JsonPatchOf(self)
+}
+
+func (self *defaultCashInfo) Changes() []bsonmodel.Change {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	return bsonmodel.ChangesOf(self)
+}
+
+func (self *defaultCashInfo) Schema() *bsonmodel.ModelSchema {
+	return cashInfoSchema
+}
+
+func (self *defaultCashInfo) GetField(name string) (interface{}, error) {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	switch name {
+	case BnameCashInfoStages:
+		return self.stages, nil
+	case BnameCashInfoCards:
+		return self.cards, nil
+	case BnameCashInfoOrderIds:
+		return self.orderIds, nil
+	default:
+		return nil, bsonmodel.NoSuchFieldError(name)
+	}
+}
+
+func (self *defaultCashInfo) SetField(name string, value interface{}) error {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	switch name {
+	case BnameCashInfoCards:
+		v, err := bsonmodel.ParseIntArray(value)
+		if err != nil {
+			return err
+		}
+		self.SetCards(v)
+	case BnameCashInfoOrderIds:
+		v, err := bsonmodel.ParseStringArray(value)
+		if err != nil {
+			return err
+		}
+		self.SetOrderIds(v)
+	case BnameCashInfoStages:
+		return bsonmodel.UnsettableFieldError(name)
+	default:
+		return bsonmodel.NoSuchFieldError(name)
+	}
+	return nil
+}
+
+func (self *defaultCashInfo) GetPath(xpath bsonmodel.DotNotation) (interface{}, error) {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	return bsonmodel.GetPath(self, xpath)
+}
+
+func (self *defaultCashInfo) SetPath(xpath bsonmodel.DotNotation, value interface{}) error {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	return bsonmodel.SetPath(self, xpath, value)
+}
+
 func (self *defaultCashInfo) MarshalJSON() ([]byte, error) {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	return jsoniter.Marshal(self)
 }
 
 func (self *defaultCashInfo) Stages() bsonmodel.IntSimpleMapModel {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	return self.stages
 }
 
 func (self *defaultCashInfo) Cards() []int {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	return self.cards
 }
 
 func (self *defaultCashInfo) SetCards(cards []int) {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	bsonmodel.SaveState(self)
+	old := self.cards
 	self.cards = cards
 	self.updatedFields.Set(2)
+	bsonmodel.EmitChange(self, "cs", old, cards)
+}
+
+func (self *defaultCashInfo) OnCardsChanged(listener func(oldValue []int, newValue []int)) (remove func(), err error) {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	return bsonmodel.ObserveField(self, BnameCashInfoCards, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.([]int), event.NewValue.([]int))
+	})
 }
 
 func (self *defaultCashInfo) OrderIds() []string {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
 	return self.orderIds
 }
 
 func (self *defaultCashInfo) SetOrderIds(orderIds []string) {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	bsonmodel.SaveState(self)
+	old := self.orderIds
 	self.orderIds = orderIds
 	self.updatedFields.Set(3)
+	bsonmodel.EmitChange(self, "ois", old, orderIds)
+}
+
+func (self *defaultCashInfo) OnOrderIdsChanged(listener func(oldValue []string, newValue []string)) (remove func(), err error) {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	return bsonmodel.ObserveField(self, BnameCashInfoOrderIds, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.([]string), event.NewValue.([]string))
+	})
+}
+
+func (self *defaultCashInfo) Clone() CashInfo {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	clone := NewCashInfo(nil)
+	clone.CopyFrom(self)
+	clone.Reset()
+	return clone
+}
+
+func (self *defaultCashInfo) CopyFrom(other CashInfo) {
+	bsonmodel.CheckReleased(self.released, "CashInfo")
+	self.stages.CopyFrom(other.Stages())
+	if !bsonmodel.IntSliceEquals(self.cards, other.Cards()) {
+		self.SetCards(bsonmodel.CopyIntSlice(other.Cards()))
+	}
+	if !bsonmodel.StringSliceEquals(self.orderIds, other.OrderIds()) {
+		self.SetOrderIds(bsonmodel.CopyStringSlice(other.OrderIds()))
+	}
 }
 
 func NewCashInfo(parent Player) CashInfo {
@@ -285,6 +577,26 @@ func NewCashInfo(parent Player) CashInfo {
 	return self
 }
 
+type CashInfoPaths struct {
+	base bsonmodel.DotNotation
+}
+
+func (paths CashInfoPaths) Path() bsonmodel.DotNotation {
+	return paths.base
+}
+
+func (paths CashInfoPaths) Stages() bsonmodel.IntMapPath {
+	return bsonmodel.NewIntMapPath(paths.base.Resolve(BnameCashInfoStages))
+}
+
+func (paths CashInfoPaths) Cards() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameCashInfoCards)
+}
+
+func (paths CashInfoPaths) OrderIds() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameCashInfoOrderIds)
+}
+
 type cashInfoEncoder struct{}
 
 func (codec *cashInfoEncoder) IsEmpty(ptr unsafe.Pointer) bool {
@@ -293,16 +605,14 @@ func (codec *cashInfoEncoder) IsEmpty(ptr unsafe.Pointer) bool {
 
 func (codec *cashInfoEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
 	p := ((*defaultCashInfo)(ptr))
-	stream.WriteObjectStart()
-	stream.WriteObjectField("stages")
+	object := bsonmodel.BeginJsonObject(stream)
+	object.Field("stages")
 	stream.WriteVal(p.stages)
-	stream.WriteMore()
-	stream.WriteObjectField("cards")
+	object.Field("cards")
 	stream.WriteVal(p.cards)
-	stream.WriteMore()
-	stream.WriteObjectField("orderIds")
+	object.Field("orderIds")
 	stream.WriteVal(p.orderIds)
-	stream.WriteObjectEnd()
+	object.End()
 }
 
 func init() {
@@ -1,6 +1,7 @@
 package example
 
 import (
+	"io"
 	"unsafe"
 
 	"github.com/bits-and-blooms/bitset"
@@ -13,14 +14,21 @@ type Equipment interface {
 	bsonmodel.StringObjectMapValueModel
 	Id() string
 	SetId(id string)
+	OnIdChanged(listener func(oldValue string, newValue string)) (remove func(), err error)
 	RefId() int
 	SetRefId(refId int)
+	OnRefIdChanged(listener func(oldValue int, newValue int)) (remove func(), err error)
 	Atk() int
 	SetAtk(atk int)
+	OnAtkChanged(listener func(oldValue int, newValue int)) (remove func(), err error)
 	Def() int
 	SetDef(def int)
+	OnDefChanged(listener func(oldValue int, newValue int)) (remove func(), err error)
 	Hp() int
 	SetHp(hp int)
+	OnHpChanged(listener func(oldValue int, newValue int)) (remove func(), err error)
+	Clone() Equipment
+	CopyFrom(other Equipment)
 }
 
 const (
@@ -31,6 +39,22 @@ const (
 	BnameEquipmentHp    = "hp"
 )
 
+var equipmentSchema = &bsonmodel.ModelSchema{
+	Name: "Equipment",
+	Type: bsonmodel.ModelTypeMapValue,
+	Fields: []*bsonmodel.FieldSchema{
+		{Name: "id", Bname: "id", Type: bsonmodel.FieldTypeString},
+		{Name: "refId", Bname: "rid", Type: bsonmodel.FieldTypeInt},
+		{Name: "atk", Bname: "atk", Type: bsonmodel.FieldTypeInt},
+		{Name: "def", Bname: "def", Type: bsonmodel.FieldTypeInt},
+		{Name: "hp", Bname: "hp", Type: bsonmodel.FieldTypeInt},
+	},
+}
+
+func EquipmentSchema() *bsonmodel.ModelSchema {
+	return equipmentSchema
+}
+
 type defaultEquipment struct {
 	bsonmodel.BaseStringObjectMapValue
 	updatedFields *bitset.BitSet
@@ -42,10 +66,12 @@ type defaultEquipment struct {
 }
 
 func (self *defaultEquipment) ToBson() interface{} {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	return self.ToDocument()
 }
 
 func (self *defaultEquipment) ToData() interface{} {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	data := make(map[string]interface{})
 	data["id"] = self.id
 	data["rid"] = self.refId
@@ -56,6 +82,8 @@ func (self *defaultEquipment) ToData() interface{} {
 }
 
 func (self *defaultEquipment) LoadJsoniter(any jsoniter.Any) error {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	bsonmodel.SaveState(self)
 	if any.ValueType() != jsoniter.ObjectValue {
 		return nil
 	}
@@ -87,19 +115,68 @@ func (self *defaultEquipment) LoadJsoniter(any jsoniter.Any) error {
 	return nil
 }
 
+func (self *defaultEquipment) LoadJsonIterator(iter *jsoniter.Iterator) error {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	bsonmodel.SaveState(self)
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		return bsonmodel.IterError(iter)
+	}
+	self.id = ""
+	self.refId = 0
+	self.atk = 0
+	self.def = 0
+	self.hp = 0
+	var err error
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, field string) bool {
+		switch field {
+		case BnameEquipmentId:
+			self.id, err = bsonmodel.IterStringValue(iter, "")
+		case BnameEquipmentRefId, "refId":
+			self.refId, err = bsonmodel.IterIntValue(iter, 0)
+		case BnameEquipmentAtk:
+			self.atk, err = bsonmodel.IterIntValue(iter, 0)
+		case BnameEquipmentDef:
+			self.def, err = bsonmodel.IterIntValue(iter, 0)
+		case BnameEquipmentHp:
+			self.hp, err = bsonmodel.IterIntValue(iter, 0)
+		default:
+			iter.Skip()
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return bsonmodel.IterError(iter)
+}
+
 func (self *defaultEquipment) Reset() {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	bsonmodel.SaveState(self)
 	self.updatedFields.ClearAll()
 }
 
+func (self *defaultEquipment) clear() {
+	self.id = ""
+	self.refId = 0
+	self.atk = 0
+	self.def = 0
+	self.hp = 0
+}
+
 func (self *defaultEquipment) AnyUpdated() bool {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	return self.updatedFields.Any()
 }
 
 func (self *defaultEquipment) AnyDeleted() bool {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	return self.DeletedSize() > 0
 }
 
 func (self *defaultEquipment) AppendUpdates(updates bson.M) bson.M {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	dset := bsonmodel.FixedEmbedded(updates, "$set")
 	xpath := self.XPath()
 	if self.FullyUpdate() {
@@ -126,6 +203,7 @@ func (self *defaultEquipment) AppendUpdates(updates bson.M) bson.M {
 }
 
 func (self *defaultEquipment) ToDocument() bson.M {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	doc := bson.M{}
 	doc["id"] = self.id
 	doc["rid"] = self.refId
@@ -136,6 +214,8 @@ func (self *defaultEquipment) ToDocument() bson.M {
 }
 
 func (self *defaultEquipment) LoadDocument(document bson.M) error {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	bsonmodel.SaveState(self)
 	id, err := bsonmodel.StringValue(document, "id", "")
 	if err != nil {
 		return err
@@ -164,7 +244,50 @@ func (self *defaultEquipment) LoadDocument(document bson.M) error {
 	return nil
 }
 
+func (self *defaultEquipment) LoadRaw(raw bson.Raw) error {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	bsonmodel.SaveState(self)
+	id, err := bsonmodel.RawStringValue(raw, "id", "")
+	if err != nil {
+		return err
+	}
+	self.id = id
+	refId, err := bsonmodel.RawIntValue(raw, "rid", 0)
+	if err != nil {
+		return err
+	}
+	self.refId = refId
+	atk, err := bsonmodel.RawIntValue(raw, "atk", 0)
+	if err != nil {
+		return err
+	}
+	self.atk = atk
+	def, err := bsonmodel.RawIntValue(raw, "def", 0)
+	if err != nil {
+		return err
+	}
+	self.def = def
+	hp, err := bsonmodel.RawIntValue(raw, "hp", 0)
+	if err != nil {
+		return err
+	}
+	self.hp = hp
+	return nil
+}
+
+func (self *defaultEquipment) LoadDocumentTracked(document bson.M) error {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	loaded := NewEquipment()
+	err := loaded.LoadDocument(document)
+	if err != nil {
+		return err
+	}
+	self.CopyFrom(loaded)
+	return nil
+}
+
 func (self *defaultEquipment) DeletedSize() int {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	return 0
 }
 
@@ -173,6 +296,7 @@ func (self *defaultEquipment) FullyUpdate() bool {
 }
 
 func (self *defaultEquipment) SetFullyUpdate(fullyUpdate bool) {
+	bsonmodel.SaveState(self)
 	if fullyUpdate {
 		self.updatedFields.Set(0)
 	} else {
@@ -180,9 +304,28 @@ func (self *defaultEquipment) SetFullyUpdate(fullyUpdate bool) {
 	}
 }
 
+func (self *defaultEquipment) Snapshot() func() {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	updatedFields := self.updatedFields.Clone()
+	id := self.id
+	refId := self.refId
+	atk := self.atk
+	def := self.def
+	hp := self.hp
+	return func() {
+		self.updatedFields = updatedFields
+		self.id = id
+		self.refId = refId
+		self.atk = atk
+		self.def = def
+		self.hp = hp
+	}
+}
+
 func (self *defaultEquipment) ToSync() interface{} {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	if self.FullyUpdate() {
-		return self
+		return bsonmodel.FullySync(self)
 	}
 	sync := make(map[string]interface{})
 	updatedFields := self.updatedFields
@@ -205,85 +348,310 @@ func (self *defaultEquipment) ToSync() interface{} {
 }
 
 func (self *defaultEquipment) ToDelete() interface{} {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	delete := make(map[string]interface{})
 	return delete
 }
 
 func (self *defaultEquipment) ToDataJson() (string, error) {
-	return jsoniter.MarshalToString(self.ToData())
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.DataJsonOf(self)
 }
 
 func (self *defaultEquipment) ToSyncJson() (string, error) {
-	return jsoniter.MarshalToString(self.ToSync())
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.SyncJsonOf(self)
 }
 
 func (self *defaultEquipment) ToDeleteJson() (string, error) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	return jsoniter.MarshalToString(self.ToDelete())
 }
 
+func (self *defaultEquipment) EncodeData(stream *jsoniter.Stream) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	object := bsonmodel.BeginJsonObject(stream)
+	object.Field("id")
+	stream.WriteString(self.id)
+	object.Field("rid")
+	stream.WriteInt(self.refId)
+	object.Field("atk")
+	stream.WriteInt(self.atk)
+	object.Field("def")
+	stream.WriteInt(self.def)
+	object.Field("hp")
+	stream.WriteInt(self.hp)
+	object.End()
+}
+
+func (self *defaultEquipment) EncodeSync(stream *jsoniter.Stream) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	if self.FullyUpdate() {
+		stream.WriteVal(bsonmodel.FullySync(self))
+		return
+	}
+	object := bsonmodel.BeginJsonObject(stream)
+	if self.updatedFields.Test(1) {
+		object.Field("id")
+		stream.WriteString(self.id)
+	}
+	if self.updatedFields.Test(2) {
+		object.Field("refId")
+		stream.WriteInt(self.refId)
+	}
+	if self.updatedFields.Test(3) {
+		object.Field("atk")
+		stream.WriteInt(self.atk)
+	}
+	if self.updatedFields.Test(4) {
+		object.Field("def")
+		stream.WriteInt(self.def)
+	}
+	if self.updatedFields.Test(5) {
+		object.Field("hp")
+		stream.WriteInt(self.hp)
+	}
+	object.End()
+}
+
+func (self *defaultEquipment) WriteDataJson(w io.Writer) error {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.WriteDataJson(w, self)
+}
+
+func (self *defaultEquipment) WriteSyncJson(w io.Writer) error {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.WriteSyncJson(w, self)
+}
+
+func (self *defaultEquipment) ToMergePatch() interface{} {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.MergePatchOf(self)
+}
+
+func (self *defaultEquipment) ToJsonPatch() []bsonmodel.JsonPatchOperation {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.JsonPatchOf(self)
+}
+
+func (self *defaultEquipment) Changes() []bsonmodel.Change {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.ChangesOf(self)
+}
+
+func (self *defaultEquipment) Schema() *bsonmodel.ModelSchema {
+	return equipmentSchema
+}
+
+func (self *defaultEquipment) GetField(name string) (interface{}, error) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	switch name {
+	case BnameEquipmentId:
+		return self.id, nil
+	case BnameEquipmentRefId:
+		return self.refId, nil
+	case BnameEquipmentAtk:
+		return self.atk, nil
+	case BnameEquipmentDef:
+		return self.def, nil
+	case BnameEquipmentHp:
+		return self.hp, nil
+	default:
+		return nil, bsonmodel.NoSuchFieldError(name)
+	}
+}
+
+func (self *defaultEquipment) SetField(name string, value interface{}) error {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	switch name {
+	case BnameEquipmentId:
+		v, err := bsonmodel.ParseString(value)
+		if err != nil {
+			return err
+		}
+		self.SetId(v)
+	case BnameEquipmentRefId:
+		v, err := bsonmodel.ParseInt(value)
+		if err != nil {
+			return err
+		}
+		self.SetRefId(v)
+	case BnameEquipmentAtk:
+		v, err := bsonmodel.ParseInt(value)
+		if err != nil {
+			return err
+		}
+		self.SetAtk(v)
+	case BnameEquipmentDef:
+		v, err := bsonmodel.ParseInt(value)
+		if err != nil {
+			return err
+		}
+		self.SetDef(v)
+	case BnameEquipmentHp:
+		v, err := bsonmodel.ParseInt(value)
+		if err != nil {
+			return err
+		}
+		self.SetHp(v)
+	default:
+		return bsonmodel.NoSuchFieldError(name)
+	}
+	return nil
+}
+
+func (self *defaultEquipment) GetPath(xpath bsonmodel.DotNotation) (interface{}, error) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.GetPath(self, xpath)
+}
+
+func (self *defaultEquipment) SetPath(xpath bsonmodel.DotNotation, value interface{}) error {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.SetPath(self, xpath, value)
+}
+
 func (self *defaultEquipment) MarshalJSON() ([]byte, error) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	return jsoniter.Marshal(self)
 }
 
 func (self *defaultEquipment) Id() string {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	return self.id
 }
 
 func (self *defaultEquipment) SetId(id string) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	if self.id != id {
+		bsonmodel.SaveState(self)
+		old := self.id
 		self.id = id
 		self.updatedFields.Set(1)
+		self.EmitUpdated()
+		bsonmodel.EmitChange(self, "id", old, id)
 	}
 }
 
+func (self *defaultEquipment) OnIdChanged(listener func(oldValue string, newValue string)) (remove func(), err error) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.ObserveField(self, BnameEquipmentId, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(string), event.NewValue.(string))
+	})
+}
+
 func (self *defaultEquipment) RefId() int {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	return self.refId
 }
 
 func (self *defaultEquipment) SetRefId(refId int) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	if self.refId != refId {
+		bsonmodel.SaveState(self)
+		old := self.refId
 		self.refId = refId
 		self.updatedFields.Set(2)
 		self.EmitUpdated()
+		bsonmodel.EmitChange(self, "rid", old, refId)
 	}
 }
 
+func (self *defaultEquipment) OnRefIdChanged(listener func(oldValue int, newValue int)) (remove func(), err error) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.ObserveField(self, BnameEquipmentRefId, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(int), event.NewValue.(int))
+	})
+}
+
 func (self *defaultEquipment) Atk() int {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	return self.atk
 }
 
 func (self *defaultEquipment) SetAtk(atk int) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	if self.atk != atk {
+		bsonmodel.SaveState(self)
+		old := self.atk
 		self.atk = atk
 		self.updatedFields.Set(3)
 		self.EmitUpdated()
+		bsonmodel.EmitChange(self, "atk", old, atk)
 	}
 }
 
+func (self *defaultEquipment) OnAtkChanged(listener func(oldValue int, newValue int)) (remove func(), err error) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.ObserveField(self, BnameEquipmentAtk, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(int), event.NewValue.(int))
+	})
+}
+
 func (self *defaultEquipment) Def() int {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	return self.def
 }
 
 func (self *defaultEquipment) SetDef(def int) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	if self.def != def {
+		bsonmodel.SaveState(self)
+		old := self.def
 		self.def = def
 		self.updatedFields.Set(4)
 		self.EmitUpdated()
+		bsonmodel.EmitChange(self, "def", old, def)
 	}
 }
 
+func (self *defaultEquipment) OnDefChanged(listener func(oldValue int, newValue int)) (remove func(), err error) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.ObserveField(self, BnameEquipmentDef, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(int), event.NewValue.(int))
+	})
+}
+
 func (self *defaultEquipment) Hp() int {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	return self.hp
 }
 
 func (self *defaultEquipment) SetHp(hp int) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
 	if self.hp != hp {
+		bsonmodel.SaveState(self)
+		old := self.hp
 		self.hp = hp
 		self.updatedFields.Set(5)
 		self.EmitUpdated()
+		bsonmodel.EmitChange(self, "hp", old, hp)
 	}
 }
 
+func (self *defaultEquipment) OnHpChanged(listener func(oldValue int, newValue int)) (remove func(), err error) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	return bsonmodel.ObserveField(self, BnameEquipmentHp, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(int), event.NewValue.(int))
+	})
+}
+
+func (self *defaultEquipment) Clone() Equipment {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	clone := NewEquipment()
+	clone.CopyFrom(self)
+	clone.Reset()
+	return clone
+}
+
+func (self *defaultEquipment) CopyFrom(other Equipment) {
+	bsonmodel.CheckValueReleased(self, "Equipment")
+	self.SetId(other.Id())
+	self.SetRefId(other.RefId())
+	self.SetAtk(other.Atk())
+	self.SetDef(other.Def())
+	self.SetHp(other.Hp())
+}
+
 func NewEquipment() Equipment {
 	self := &defaultEquipment{updatedFields: &bitset.BitSet{}}
 	return self
@@ -297,6 +665,46 @@ func EquipmentFactory() bsonmodel.StringObjectMapValueFactory {
 	return equipmentFactory
 }
 
+type EquipmentPaths struct {
+	base bsonmodel.DotNotation
+}
+
+func (paths EquipmentPaths) Path() bsonmodel.DotNotation {
+	return paths.base
+}
+
+func (paths EquipmentPaths) Id() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameEquipmentId)
+}
+
+func (paths EquipmentPaths) RefId() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameEquipmentRefId)
+}
+
+func (paths EquipmentPaths) Atk() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameEquipmentAtk)
+}
+
+func (paths EquipmentPaths) Def() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameEquipmentDef)
+}
+
+func (paths EquipmentPaths) Hp() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameEquipmentHp)
+}
+
+type EquipmentMapPaths struct {
+	base bsonmodel.DotNotation
+}
+
+func (paths EquipmentMapPaths) Path() bsonmodel.DotNotation {
+	return paths.base
+}
+
+func (paths EquipmentMapPaths) Key(key string) EquipmentPaths {
+	return EquipmentPaths{paths.base.Resolve(key)}
+}
+
 type equipmentEncoder struct{}
 
 func (codec *equipmentEncoder) IsEmpty(ptr unsafe.Pointer) bool {
@@ -305,22 +713,18 @@ func (codec *equipmentEncoder) IsEmpty(ptr unsafe.Pointer) bool {
 
 func (codec *equipmentEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
 	p := ((*defaultEquipment)(ptr))
-	stream.WriteObjectStart()
-	stream.WriteObjectField("id")
+	object := bsonmodel.BeginJsonObject(stream)
+	object.Field("id")
 	stream.WriteString(p.id)
-	stream.WriteMore()
-	stream.WriteObjectField("refId")
+	object.Field("refId")
 	stream.WriteInt(p.refId)
-	stream.WriteMore()
-	stream.WriteObjectField("atk")
+	object.Field("atk")
 	stream.WriteInt(p.atk)
-	stream.WriteMore()
-	stream.WriteObjectField("def")
+	object.Field("def")
 	stream.WriteInt(p.def)
-	stream.WriteMore()
-	stream.WriteObjectField("hp")
+	object.Field("hp")
 	stream.WriteInt(p.hp)
-	stream.WriteObjectEnd()
+	object.End()
 }
 
 func init() {
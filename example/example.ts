@@ -0,0 +1,200 @@
+export interface PlayerData {
+  _id: number;
+  wlt: WalletData;
+  eqm: { [key: string]: EquipmentData };
+  itm: { [key: string]: number };
+  cs: CashInfoData;
+  _uv: number;
+  _ct: number;
+  _ut: number;
+}
+
+export interface Player {
+  uid: number;
+  wallet: Wallet;
+  equipments: { [key: string]: Equipment };
+  items: { [key: string]: number };
+  cash: CashInfo;
+}
+
+export interface PlayerSync {
+  uid?: number;
+  wallet?: WalletSync;
+  equipments?: { [key: string]: EquipmentSync };
+  items?: { [key: string]: number };
+  cash?: CashInfoSync;
+}
+
+export interface PlayerDelete {
+  wallet?: WalletDelete;
+  equipments?: { [key: string]: 1 };
+  items?: { [key: string]: 1 };
+  cash?: CashInfoDelete;
+}
+
+export interface WalletData {
+  ct: number;
+  cu: number;
+  d: number;
+}
+
+export interface Wallet {
+  coinTotal: number;
+  coin: number;
+  diamond: number;
+}
+
+export interface WalletSync {
+  coinTotal?: number;
+  coin?: number;
+  diamond?: number;
+}
+
+export interface WalletDelete {
+}
+
+export interface EquipmentData {
+  id: string;
+  rid: number;
+  atk: number;
+  def: number;
+  hp: number;
+}
+
+export interface Equipment {
+  id: string;
+  refId: number;
+  atk: number;
+  def: number;
+  hp: number;
+}
+
+export interface EquipmentSync {
+  id?: string;
+  refId?: number;
+  atk?: number;
+  def?: number;
+  hp?: number;
+}
+
+export interface EquipmentDelete {
+}
+
+export interface CashInfoData {
+  stg: { [key: string]: number };
+  cs?: number[];
+  ois?: string[];
+}
+
+export interface CashInfo {
+  stages: { [key: string]: number };
+  cards: number[] | null;
+  orderIds: string[] | null;
+}
+
+export interface CashInfoSync {
+  stages?: { [key: string]: number };
+  cards?: number[] | null;
+  orderIds?: string[] | null;
+}
+
+export interface CashInfoDelete {
+  stages?: { [key: string]: 1 };
+  cards?: 1;
+  orderIds?: 1;
+}
+
+export interface LoginLogData {
+  _id: string;
+  uid: number;
+  lt: number;
+}
+
+export interface LoginLog {
+  id: string;
+  uid: number;
+  loginTime: number;
+}
+
+export interface LoginLogSync {
+  id?: string;
+  uid?: number;
+  loginTime?: number;
+}
+
+export interface LoginLogDelete {
+}
+
+type JsonObject = { [key: string]: any };
+
+const REPLACE_KEY = '$replace';
+
+function isJsonObject(value: any): value is JsonObject {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+export function applySync(state: object, sync: object): void {
+  const target = state as JsonObject;
+  const source = sync as JsonObject;
+  for (const key of Object.keys(source)) {
+    const value = source[key];
+    if (isJsonObject(value) && value[REPLACE_KEY] === true) {
+      const replaced = { ...value };
+      delete replaced[REPLACE_KEY];
+      target[key] = replaced;
+    } else if (isJsonObject(value) && isJsonObject(target[key])) {
+      applySync(target[key], value);
+    } else {
+      target[key] = value;
+    }
+  }
+}
+
+function deleteKeys(target: JsonObject, keys: { [key: string]: 1 }): void {
+  for (const key of Object.keys(keys)) {
+    delete target[key];
+  }
+}
+
+export function applyPlayerSync(state: Player, sync: PlayerSync): void {
+  applySync(state, sync);
+}
+
+export function applyLoginLogSync(state: LoginLog, sync: LoginLogSync): void {
+  applySync(state, sync);
+}
+
+export function applyPlayerDelete(state: Player, del: PlayerDelete): void {
+  if (del.wallet !== undefined) {
+    applyWalletDelete(state.wallet, del.wallet);
+  }
+  if (del.equipments !== undefined) {
+    deleteKeys(state.equipments, del.equipments);
+  }
+  if (del.items !== undefined) {
+    deleteKeys(state.items, del.items);
+  }
+  if (del.cash !== undefined) {
+    applyCashInfoDelete(state.cash, del.cash);
+  }
+}
+
+export function applyWalletDelete(state: Wallet, del: WalletDelete): void {
+}
+
+export function applyCashInfoDelete(state: CashInfo, del: CashInfoDelete): void {
+  if (del.stages !== undefined) {
+    deleteKeys(state.stages, del.stages);
+  }
+  if (del.cards !== undefined) {
+    // removed list is encoded as null
+    state.cards = null;
+  }
+  if (del.orderIds !== undefined) {
+    // removed list is encoded as null
+    state.orderIds = null;
+  }
+}
+
+export function applyLoginLogDelete(state: LoginLog, del: LoginLogDelete): void {
+}
@@ -1,11 +1,15 @@
 package example
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/fmjsjx/bson-model-go/bsonmodel"
 	jsoniter "github.com/json-iterator/go"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -1793,3 +1797,2196 @@ func TestJsonMarshal(t *testing.T) {
 		}
 	}
 }
+
+func TestToMergePatch(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	player.Wallet().SetCoinTotal(5200)
+	player.Equipments().Remove("12345678-1234-5678-9abc-123456789abc")
+	player.Equipment("11111111-1111-1111-1111-111111111111").SetHp(20)
+	player.Items().Put(2001, 12)
+	player.Cash().Stages().Remove(1)
+	player.Cash().SetCards(nil)
+	player.SetUpdateTime(time.Now().Truncate(time.Millisecond))
+	now := player.UpdateTime()
+
+	patch := player.ToMergePatch().(map[string]interface{})
+	if 5 != len(patch) {
+		t.Errorf("The value expected <%v> but was <%v>", 5, len(patch))
+	}
+	wallet := patch["wlt"].(map[string]interface{})
+	if 5200 != wallet["ct"] {
+		t.Errorf("The value expected <%v> but was <%v>", 5200, wallet["ct"])
+	}
+	equipments := patch["eqm"].(map[string]interface{})
+	if v, ok := equipments["12345678-1234-5678-9abc-123456789abc"]; !ok || v != nil {
+		t.Errorf("The value expected <nil> but was <%v>", v)
+	}
+	equipmentPatch := equipments["11111111-1111-1111-1111-111111111111"].(map[string]interface{})
+	if 20 != equipmentPatch["hp"] {
+		t.Errorf("The value expected <%v> but was <%v>", 20, equipmentPatch["hp"])
+	}
+	items := patch["itm"].(map[string]interface{})
+	if 12 != items["2001"] {
+		t.Errorf("The value expected <%v> but was <%v>", 12, items["2001"])
+	}
+	cash := patch["cs"].(map[string]interface{})
+	if v, ok := cash["cs"]; !ok || v != nil {
+		t.Errorf("The value expected <nil> but was <%v>", v)
+	}
+	stages := cash["stg"].(map[string]interface{})
+	if v, ok := stages["1"]; !ok || v != nil {
+		t.Errorf("The value expected <nil> but was <%v>", v)
+	}
+	if now.UnixMilli() != patch["_ut"] {
+		t.Errorf("The value expected <%v> but was <%v>", now.UnixMilli(), patch["_ut"])
+	}
+
+	player.Reset()
+	patch = player.ToMergePatch().(map[string]interface{})
+	if 0 != len(patch) {
+		t.Errorf("The value expected <%v> but was <%v>", 0, len(patch))
+	}
+}
+
+func TestToJsonPatch(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	player.Wallet().SetCoinTotal(5200)
+	player.Equipments().Remove("12345678-1234-5678-9abc-123456789abc")
+	player.Equipment("11111111-1111-1111-1111-111111111111").SetHp(20)
+	player.Items().Put(2001, 12)
+	player.Cash().Stages().Remove(1)
+	player.Cash().SetCards(nil)
+	player.SetUpdateTime(time.Now().Truncate(time.Millisecond))
+	now := player.UpdateTime()
+
+	operations := player.ToJsonPatch()
+	expected := []bsonmodel.JsonPatchOperation{
+		{Op: "add", Path: "/_ut", Value: now.UnixMilli()},
+		{Op: "remove", Path: "/cs/cs"},
+		{Op: "remove", Path: "/cs/stg/1"},
+		{Op: "add", Path: "/eqm/11111111-1111-1111-1111-111111111111/hp", Value: 20},
+		{Op: "remove", Path: "/eqm/12345678-1234-5678-9abc-123456789abc"},
+		{Op: "add", Path: "/itm/2001", Value: 12},
+		{Op: "add", Path: "/wlt/ct", Value: 5200},
+	}
+	if len(expected) != len(operations) {
+		t.Fatalf("The value expected <%v> but was <%v>", len(expected), len(operations))
+	}
+	for i, operation := range operations {
+		if expected[i] != operation {
+			t.Errorf("The value expected <%v> but was <%v>", expected[i], operation)
+		}
+	}
+
+	player.Reset()
+	operations = player.Wallet().ToJsonPatch()
+	if 0 != len(operations) {
+		t.Errorf("The value expected <%v> but was <%v>", 0, len(operations))
+	}
+}
+
+func TestApplySync(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	state := make(map[string]interface{})
+	value, _ := player.MarshalToJsonString()
+	err := jsoniter.UnmarshalFromString(value, &state)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+
+	player.Wallet().SetCoinTotal(5200)
+	player.Equipments().Remove("12345678-1234-5678-9abc-123456789abc")
+	player.Equipment("11111111-1111-1111-1111-111111111111").SetHp(20)
+	player.Items().Put(2001, 12)
+	player.Cash().Stages().Remove(1)
+	player.Cash().SetCards(nil)
+	player.SetUpdateTime(time.Now().Truncate(time.Millisecond))
+	equipment2 := NewEquipment()
+	equipment2.SetId("22222222-2222-2222-2222-222222222222")
+	equipment2.SetRefId(1201)
+	player.Equipments().Put(equipment2.Id(), equipment2)
+	player.Cash().SetOrderIds([]string{"order-0", "order-1", "order-2"})
+	syncJson, _ := player.ToSyncJson()
+	deleteJson, _ := player.ToDeleteJson()
+	err = bsonmodel.ApplySyncJson(state, syncJson)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	err = bsonmodel.ApplyDeleteJson(PlayerSchema(), state, deleteJson)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+
+	expected := make(map[string]interface{})
+	value, _ = player.MarshalToJsonString()
+	err = jsoniter.UnmarshalFromString(value, &expected)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if !reflect.DeepEqual(expected, state) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, state)
+	}
+}
+
+func TestSyncReplaceMarker(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	player.SetSyncReplaceMarker(true)
+	state := make(map[string]interface{})
+	value, _ := player.MarshalToJsonString()
+	err := jsoniter.UnmarshalFromString(value, &state)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+
+	equipment := NewEquipment()
+	equipment.SetId("11111111-1111-1111-1111-111111111111")
+	equipment.SetRefId(1102)
+	player.Equipments().Put(equipment.Id(), equipment)
+	syncJson, _ := player.ToSyncJson()
+	sync := make(map[string]interface{})
+	err = jsoniter.UnmarshalFromString(syncJson, &sync)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	eq := sync["equipments"].(map[string]interface{})[equipment.Id()].(map[string]interface{})
+	if eq[bsonmodel.SyncReplaceKey] != true {
+		t.Errorf("The value expected <%v> but was <%v>", true, eq[bsonmodel.SyncReplaceKey])
+	}
+	err = bsonmodel.ApplySyncJson(state, syncJson)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+
+	expected := make(map[string]interface{})
+	value, _ = player.MarshalToJsonString()
+	err = jsoniter.UnmarshalFromString(value, &expected)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if !reflect.DeepEqual(expected, state) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, state)
+	}
+
+	other := NewPlayer()
+	other.Equipments().Put(equipment.Id(), equipment.Clone().(Equipment))
+	syncJson, _ = other.ToSyncJson()
+	sync = make(map[string]interface{})
+	jsoniter.UnmarshalFromString(syncJson, &sync)
+	eq = sync["equipments"].(map[string]interface{})[equipment.Id()].(map[string]interface{})
+	if _, ok := eq[bsonmodel.SyncReplaceKey]; ok {
+		t.Errorf("The value expected absent but was <%v>", eq[bsonmodel.SyncReplaceKey])
+	}
+}
+
+func TestObserve(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	var events []bsonmodel.ChangeEvent
+	listener := func(event *bsonmodel.ChangeEvent) {
+		events = append(events, *event)
+	}
+	_, err := bsonmodel.ObserveField(player.Wallet(), BnameWalletCoinTotal, listener)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	_, err = bsonmodel.Observe(player.Items(), listener)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	player.Wallet().SetCoinTotal(5200)
+	player.Wallet().SetCoinUsed(300)
+	player.Items().Put(2001, 12)
+	player.Items().Remove(2002)
+	expected := []bsonmodel.ChangeEvent{
+		{Path: bsonmodel.PathOfNames("wlt", "ct"), OldValue: 5000, NewValue: 5200},
+		{Path: bsonmodel.PathOfNames("itm", "2001"), OldValue: 10, NewValue: 12},
+		{Path: bsonmodel.PathOfNames("itm", "2002"), OldValue: 1, NewValue: nil},
+	}
+	if !reflect.DeepEqual(expected, events) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, events)
+	}
+
+	events = nil
+	remove, err := bsonmodel.Observe(player.Equipments(), listener)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	player.Observers().BeginBatch()
+	equipment := player.Equipment("11111111-1111-1111-1111-111111111111")
+	equipment.SetAtk(1)
+	equipment.SetAtk(2)
+	player.Equipments().Remove("12345678-1234-5678-9abc-123456789abc")
+	if len(events) != 0 {
+		t.Errorf("The value expected <%v> but was <%v>", 0, len(events))
+	}
+	player.Observers().EndBatch()
+	if len(events) != 2 {
+		t.Fatalf("The value expected <%v> but was <%v>", 2, len(events))
+	}
+	if events[0].Path.Value() != "eqm.11111111-1111-1111-1111-111111111111.atk" || events[0].OldValue != 0 || events[0].NewValue != 2 {
+		t.Errorf("Unexpected event <%v>", events[0])
+	}
+	if events[1].Path.Value() != "eqm.12345678-1234-5678-9abc-123456789abc" || events[1].NewValue != nil {
+		t.Errorf("Unexpected event <%v>", events[1])
+	}
+	remove()
+	events = nil
+	equipment.SetAtk(3)
+	if len(events) != 0 {
+		t.Errorf("The value expected <%v> but was <%v>", 0, len(events))
+	}
+
+	_, err = bsonmodel.Observe(NewEquipment(), listener)
+	if err == nil {
+		t.Error("The error expected but was nil")
+	}
+}
+
+func TestObserveTypedField(t *testing.T) {
+	player := NewPlayer()
+	player.Wallet().SetCoinTotal(5000)
+	player.Cash().SetCards([]int{1})
+	player.Reset()
+	var coinTotals [][2]int
+	remove, err := player.Wallet().OnCoinTotalChanged(func(oldValue int, newValue int) {
+		coinTotals = append(coinTotals, [2]int{oldValue, newValue})
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	var cards [][]int
+	_, err = player.Cash().OnCardsChanged(func(oldValue []int, newValue []int) {
+		cards = append(cards, oldValue, newValue)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	var updateVersions []int
+	_, err = player.OnUpdateVersionChanged(func(oldValue int, newValue int) {
+		updateVersions = append(updateVersions, oldValue, newValue)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	player.Wallet().SetCoinTotal(5200)
+	player.Wallet().SetCoinUsed(300)
+	player.Cash().SetCards(nil)
+	player.IncreaseUpdateVersion()
+	expectedCoinTotals := [][2]int{{5000, 5200}}
+	if !reflect.DeepEqual(expectedCoinTotals, coinTotals) {
+		t.Errorf("The value expected <%v> but was <%v>", expectedCoinTotals, coinTotals)
+	}
+	expectedCards := [][]int{{1}, nil}
+	if !reflect.DeepEqual(expectedCards, cards) {
+		t.Errorf("The value expected <%v> but was <%v>", expectedCards, cards)
+	}
+	expectedUpdateVersions := []int{0, 1}
+	if !reflect.DeepEqual(expectedUpdateVersions, updateVersions) {
+		t.Errorf("The value expected <%v> but was <%v>", expectedUpdateVersions, updateVersions)
+	}
+	remove()
+	player.Wallet().SetCoinTotal(5300)
+	if len(coinTotals) != 1 {
+		t.Errorf("The value expected <%v> but was <%v>", 1, len(coinTotals))
+	}
+
+	_, err = NewEquipment().OnAtkChanged(func(oldValue int, newValue int) {})
+	if err == nil {
+		t.Error("The error expected but was nil")
+	}
+}
+
+func TestObserversReleased(t *testing.T) {
+	player := AcquirePlayer()
+	_, err := player.Wallet().OnCoinTotalChanged(func(oldValue int, newValue int) {})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if NewPlayer().Observers().HasListeners() {
+		t.Error("The value expected false but was true")
+	}
+	player.Release()
+	player = AcquirePlayer()
+	if player.Observers().HasListeners() {
+		t.Error("The value expected false but was true")
+	}
+	player.Release()
+}
+
+func TestTransaction(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	player.Wallet().SetDiamond(20)
+	player.Items().Put(2003, 5)
+	document := player.ToDocument()
+	update := player.ToUpdate()
+
+	err := player.Begin()
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if player.Begin() == nil {
+		t.Error("The error expected but was nil")
+	}
+	player.Wallet().SetCoinTotal(5200)
+	player.Equipments().Remove("12345678-1234-5678-9abc-123456789abc")
+	player.Equipment("11111111-1111-1111-1111-111111111111").SetHp(20)
+	player.Items().Put(2001, 12)
+	player.Cash().Stages().Remove(1)
+	player.Cash().SetCards(nil)
+	player.SetUpdateTime(time.Now().Truncate(time.Millisecond))
+	equipment := NewEquipment()
+	equipment.SetId("11111111-1111-1111-1111-111111111111")
+	equipment.SetRefId(1102)
+	player.Equipments().Put(equipment.Id(), equipment)
+	player.Items().Clear()
+	player.Cash().Stages().Put(3, 1)
+	player.IncreaseUpdateVersion()
+	player.Reset()
+	err = player.Rollback()
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if player.Rollback() == nil {
+		t.Error("The error expected but was nil")
+	}
+
+	if !reflect.DeepEqual(document, player.ToDocument()) {
+		t.Errorf("The value expected <%v> but was <%v>", document, player.ToDocument())
+	}
+	if !reflect.DeepEqual(update, player.ToUpdate()) {
+		t.Errorf("The value expected <%v> but was <%v>", update, player.ToUpdate())
+	}
+	eq := player.Equipment("11111111-1111-1111-1111-111111111111")
+	if eq.RefId() != 1101 {
+		t.Errorf("The value expected <%v> but was <%v>", 1101, eq.RefId())
+	}
+	if eq.XPath().Value() != "eqm.11111111-1111-1111-1111-111111111111" {
+		t.Errorf("The value expected <%v> but was <%v>", "eqm.11111111-1111-1111-1111-111111111111", eq.XPath().Value())
+	}
+
+	err = player.Begin()
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	player.Wallet().SetCoinTotal(5200)
+	err = player.Commit()
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if player.Wallet().CoinTotal() != 5200 {
+		t.Errorf("The value expected <%v> but was <%v>", 5200, player.Wallet().CoinTotal())
+	}
+	if player.Commit() == nil {
+		t.Error("The error expected but was nil")
+	}
+}
+
+func TestClone(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	player.Wallet().SetCoinTotal(5200)
+	player.Equipments().Remove("12345678-1234-5678-9abc-123456789abc")
+	player.Equipment("11111111-1111-1111-1111-111111111111").SetHp(20)
+	player.Items().Put(2001, 12)
+	player.Cash().Stages().Remove(1)
+	player.Cash().SetCards(nil)
+	player.SetUpdateTime(time.Now().Truncate(time.Millisecond))
+	clone := player.Clone()
+	if !reflect.DeepEqual(player.ToDocument(), clone.ToDocument()) {
+		t.Errorf("The value expected <%v> but was <%v>", player.ToDocument(), clone.ToDocument())
+	}
+	if clone.AnyUpdated() {
+		t.Error("The value expected false but was true")
+	}
+	if len(clone.Items().Keys()) != 2 {
+		t.Errorf("The value expected <%v> but was <%v>", 2, len(clone.Items().Keys()))
+	}
+	player.Cash().SetOrderIds([]string{"order-0"})
+	if len(clone.Cash().OrderIds()) != 2 {
+		t.Errorf("The value expected <%v> but was <%v>", 2, len(clone.Cash().OrderIds()))
+	}
+
+	equipment := player.Equipment("11111111-1111-1111-1111-111111111111").Clone()
+	if equipment.Parent() != nil {
+		t.Errorf("The value expected <%v> but was <%v>", nil, equipment.Parent())
+	}
+	if equipment.Hp() != 20 {
+		t.Errorf("The value expected <%v> but was <%v>", 20, equipment.Hp())
+	}
+}
+
+func TestCopyFrom(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	backup := player.Clone()
+	player.Wallet().SetCoinTotal(5200)
+	player.Equipments().Remove("12345678-1234-5678-9abc-123456789abc")
+	player.Equipment("11111111-1111-1111-1111-111111111111").SetHp(20)
+	player.Items().Put(2001, 12)
+	player.Cash().Stages().Remove(1)
+	player.Cash().SetCards(nil)
+	player.SetUpdateTime(time.Now().Truncate(time.Millisecond))
+	player.Reset()
+
+	player.CopyFrom(backup)
+	if !reflect.DeepEqual(backup.ToDocument(), player.ToDocument()) {
+		t.Errorf("The value expected <%v> but was <%v>", backup.ToDocument(), player.ToDocument())
+	}
+	update := player.ToUpdate()
+	expected := bson.M{
+		"$set": bson.M{
+			"wlt.ct": 5000,
+			"eqm.12345678-1234-5678-9abc-123456789abc":    backup.Equipment("12345678-1234-5678-9abc-123456789abc").ToDocument(),
+			"eqm.11111111-1111-1111-1111-111111111111.hp": 12,
+			"itm.2001": 10,
+			"cs.stg.1": 2,
+			"cs.cs":    bson.A{1, 2},
+			"_ut":      primitive.NewDateTimeFromTime(createTime),
+		},
+	}
+	if !reflect.DeepEqual(expected, update) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, update)
+	}
+}
+
+func TestCopyFromMapValueId(t *testing.T) {
+	player := NewPlayer()
+	equipment := NewEquipment()
+	equipment.SetId("a")
+	equipment.SetAtk(12)
+	player.Equipments().Put("e1", equipment)
+	player.Reset()
+	other := player.Clone()
+	other.Equipment("e1").SetId("b")
+
+	player.CopyFrom(other)
+	if player.Equipment("e1").Id() != "b" {
+		t.Errorf("The value expected <%v> but was <%v>", "b", player.Equipment("e1").Id())
+	}
+	update := player.ToUpdate()
+	expected := bson.M{"$set": bson.M{"eqm.e1.id": "b"}}
+	if !reflect.DeepEqual(expected, update) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, update)
+	}
+}
+
+func TestLoadDocumentTracked(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	document := player.ToDocument()
+	document["wlt"].(bson.M)["ct"] = int32(5200)
+	delete(document["eqm"].(bson.M), "12345678-1234-5678-9abc-123456789abc")
+	document["itm"].(bson.M)["2003"] = int32(1)
+	document["_id"] = int64(123)
+
+	err := player.LoadDocumentTracked(document)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	expected := bson.M{
+		"$set":   bson.M{"wlt.ct": 5200, "itm.2003": 1},
+		"$unset": bson.M{"eqm.12345678-1234-5678-9abc-123456789abc": ""},
+	}
+	update := player.ToUpdate()
+	if !reflect.DeepEqual(expected, update) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, update)
+	}
+	if player.Equipment("11111111-1111-1111-1111-111111111111").Parent() == nil {
+		t.Error("The value expected not nil")
+	}
+}
+
+func TestChanges(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	if len(player.Changes()) != 0 {
+		t.Errorf("The value expected <%v> but was <%v>", 0, len(player.Changes()))
+	}
+	player.Wallet().SetCoinTotal(5200)
+	player.Equipments().Remove("12345678-1234-5678-9abc-123456789abc")
+	player.Equipment("11111111-1111-1111-1111-111111111111").SetHp(20)
+	player.Items().Put(2001, 12)
+	player.Cash().Stages().Remove(1)
+	player.Cash().SetCards(nil)
+	player.SetUpdateTime(time.Now().Truncate(time.Millisecond))
+	changes := player.Changes()
+	expected := []struct {
+		path string
+		kind bsonmodel.ChangeKind
+	}{
+		{"_ut", bsonmodel.ChangeUpdated},
+		{"cs.cs", bsonmodel.ChangeRemoved},
+		{"cs.stg.1", bsonmodel.ChangeRemoved},
+		{"eqm.11111111-1111-1111-1111-111111111111.hp", bsonmodel.ChangeUpdated},
+		{"eqm.12345678-1234-5678-9abc-123456789abc", bsonmodel.ChangeRemoved},
+		{"itm.2001", bsonmodel.ChangeUpdated},
+		{"wlt.ct", bsonmodel.ChangeUpdated},
+	}
+	if len(expected) != len(changes) {
+		t.Fatalf("The value expected <%v> but was <%v>", len(expected), len(changes))
+	}
+	for i, e := range expected {
+		if changes[i].Path.Value() != e.path || changes[i].Kind != e.kind {
+			t.Errorf("The value expected <%v %v> but was <%v %v>", e.path, e.kind, changes[i].Path, changes[i].Kind)
+		}
+	}
+	if changes[6].Value != 5200 {
+		t.Errorf("The value expected <%v> but was <%v>", 5200, changes[6].Value)
+	}
+	walletChanges := player.Wallet().Changes()
+	if len(walletChanges) != 1 || walletChanges[0].Path.Value() != "wlt.ct" {
+		t.Errorf("The value expected <%v> but was <%v>", "wlt.ct", walletChanges)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	schema := NewPlayer().Schema()
+	if schema != PlayerSchema() {
+		t.Errorf("The value expected <%v> but was <%v>", PlayerSchema(), schema)
+	}
+	if schema.Name != "Player" || schema.Type != bsonmodel.ModelTypeRoot {
+		t.Errorf("The value expected <%v %v> but was <%v %v>", "Player", bsonmodel.ModelTypeRoot, schema.Name, schema.Type)
+	}
+	if len(schema.Fields) != 8 {
+		t.Errorf("The value expected <%v> but was <%v>", 8, len(schema.Fields))
+	}
+	equipments := schema.FieldByBname(BnamePlayerEquipments)
+	if equipments.Name != "equipments" || equipments.Type != bsonmodel.FieldTypeMap || equipments.KeyType != "string" {
+		t.Errorf("Unexpected field schema <%v>", equipments)
+	}
+	if equipments.Model != EquipmentSchema() || equipments.Model.Type != bsonmodel.ModelTypeMapValue {
+		t.Errorf("The value expected <%v> but was <%v>", EquipmentSchema(), equipments.Model)
+	}
+	if !schema.Field("uid").Required || !schema.Field("createTime").JsonIgnore {
+		t.Error("The value expected true but was false")
+	}
+	coin := schema.Field("wallet").Model.Field("coin")
+	if !coin.Virtual || coin.Bname != "" || !reflect.DeepEqual([]string{"coinTotal", "coinUsed"}, coin.Sources) {
+		t.Errorf("Unexpected field schema <%v>", coin)
+	}
+	wallet := schema.Field("wallet").Model
+	if !wallet.Field("diamond").Long || wallet.Field("coinTotal").Long {
+		t.Errorf("Unexpected field schema <%v> <%v>", wallet.Field("diamond"), wallet.Field("coinTotal"))
+	}
+	if NewPlayer().Cash().Schema().Field("orderIds").ValueType != "string" {
+		t.Errorf("The value expected <%v> but was <%v>", "string", NewPlayer().Cash().Schema().Field("orderIds").ValueType)
+	}
+	if schema.Field("none") != nil || schema.FieldByBname("coin") != nil {
+		t.Error("The value expected nil")
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	coinTotal, err := player.GetPath(bsonmodel.PathOfNames("wlt.ct"))
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if coinTotal != 5000 {
+		t.Errorf("The value expected <%v> but was <%v>", 5000, coinTotal)
+	}
+	refId, err := player.GetPath(bsonmodel.PathOfNames("eqm", "11111111-1111-1111-1111-111111111111", "rid"))
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if refId != 1101 {
+		t.Errorf("The value expected <%v> but was <%v>", 1101, refId)
+	}
+	item, err := player.GetPath(bsonmodel.PathOfNames("itm.2002"))
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if item != 1 {
+		t.Errorf("The value expected <%v> but was <%v>", 1, item)
+	}
+	wallet, err := player.GetPath(bsonmodel.PathOfNames("wlt"))
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if wallet != player.Wallet() {
+		t.Errorf("The value expected <%v> but was <%v>", player.Wallet(), wallet)
+	}
+	for _, p := range []string{"none", "wlt.none", "eqm.none.atk", "itm.abc", "wlt.ct.none"} {
+		if _, err = player.GetPath(bsonmodel.PathOfNames(p)); err == nil {
+			t.Errorf("Expected error on path <%v> but not", p)
+		}
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	err := player.SetPath(bsonmodel.PathOfNames("wlt.ct"), int64(5200))
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	err = player.SetPath(bsonmodel.PathOfNames("eqm.11111111-1111-1111-1111-111111111111.hp"), float64(20))
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	err = player.SetPath(bsonmodel.PathOfNames("eqm.12345678-1234-5678-9abc-123456789abc.id"), "renamed")
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	err = player.SetPath(bsonmodel.PathOfNames("itm.2001"), int32(12))
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	err = player.SetPath(bsonmodel.PathOfNames("cs.cs"), bson.A{int32(3)})
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	err = player.SetPath(bsonmodel.PathOfNames("eqm.22222222-2222-2222-2222-222222222222"), bson.M{"id": "22222222-2222-2222-2222-222222222222", "rid": 1201, "atk": 5})
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	err = player.SetPath(bsonmodel.PathOfNames("cs.stg.1"), nil)
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	updateTime := time.Now().Truncate(time.Millisecond)
+	err = player.SetPath(bsonmodel.PathOfNames("_ut"), primitive.NewDateTimeFromTime(updateTime))
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if player.Wallet().CoinTotal() != 5200 {
+		t.Errorf("The value expected <%v> but was <%v>", 5200, player.Wallet().CoinTotal())
+	}
+	if player.Equipment("22222222-2222-2222-2222-222222222222").RefId() != 1201 {
+		t.Errorf("The value expected <%v> but was <%v>", 1201, player.Equipment("22222222-2222-2222-2222-222222222222").RefId())
+	}
+	if !updateTime.Equal(player.UpdateTime()) {
+		t.Errorf("The value expected <%v> but was <%v>", updateTime, player.UpdateTime())
+	}
+	update := player.ToUpdate()
+	dset := update["$set"].(bson.M)
+	expected := bson.M{
+		"wlt.ct": 5200,
+		"eqm.11111111-1111-1111-1111-111111111111.hp": 20,
+		"eqm.12345678-1234-5678-9abc-123456789abc.id": "renamed",
+		"itm.2001": 12,
+		"cs.cs":    bson.A{3},
+		"_ut":      primitive.NewDateTimeFromTime(updateTime),
+	}
+	for k, v := range expected {
+		if !reflect.DeepEqual(v, dset[k]) {
+			t.Errorf("The value expected <%v> but was <%v>", v, dset[k])
+		}
+	}
+	if _, ok := dset["eqm.22222222-2222-2222-2222-222222222222"]; !ok {
+		t.Error("The value expected true but was false")
+	}
+	if _, ok := update["$unset"].(bson.M)["cs.stg.1"]; !ok {
+		t.Error("The value expected true but was false")
+	}
+	if err = player.SetPath(bsonmodel.PathOfNames("wlt.ct"), "abc"); err == nil {
+		t.Error("Expected error but not")
+	}
+	if err = player.SetPath(bsonmodel.PathOfNames("wlt"), bson.M{}); err == nil {
+		t.Error("Expected error but not")
+	}
+	if err = player.SetPath(bsonmodel.PathOfNames("wlt.none"), 1); err == nil {
+		t.Error("Expected error but not")
+	}
+	if err = player.SetPath(bsonmodel.RootPath(), 1); err == nil {
+		t.Error("Expected error but not")
+	}
+}
+
+func TestWalk(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	paths := make([]string, 0)
+	err := bsonmodel.Walk(player, func(xpath bsonmodel.DotNotation, value interface{}) error {
+		paths = append(paths, xpath.Value())
+		if xpath.Value() == "eqm.11111111-1111-1111-1111-111111111111" {
+			return bsonmodel.SkipChildren
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	expected := []string{
+		"",
+		"_id",
+		"wlt", "wlt.ct", "wlt.cu", "wlt.d",
+		"eqm",
+		"eqm.11111111-1111-1111-1111-111111111111",
+		"eqm.12345678-1234-5678-9abc-123456789abc",
+		"eqm.12345678-1234-5678-9abc-123456789abc.id",
+		"eqm.12345678-1234-5678-9abc-123456789abc.rid",
+		"eqm.12345678-1234-5678-9abc-123456789abc.atk",
+		"eqm.12345678-1234-5678-9abc-123456789abc.def",
+		"eqm.12345678-1234-5678-9abc-123456789abc.hp",
+		"itm", "itm.2001", "itm.2002",
+		"cs", "cs.stg", "cs.stg.1", "cs.stg.2", "cs.cs", "cs.cs.0", "cs.cs.1", "cs.ois", "cs.ois.0", "cs.ois.1",
+		"_uv", "_ct", "_ut",
+	}
+	if !reflect.DeepEqual(expected, paths) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, paths)
+	}
+	total := 0
+	err = bsonmodel.Walk(player.Cash(), func(xpath bsonmodel.DotNotation, value interface{}) error {
+		if n, ok := value.(int); ok {
+			total += n
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if total != 6 {
+		t.Errorf("The value expected <%v> but was <%v>", 6, total)
+	}
+	stop := errors.New("stop")
+	count := 0
+	err = bsonmodel.Walk(player, func(xpath bsonmodel.DotNotation, value interface{}) error {
+		count++
+		if xpath.Value() == "wlt.ct" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || count != 4 {
+		t.Errorf("The value expected <%v %v> but was <%v %v>", stop, 4, err, count)
+	}
+}
+
+func TestMarshalBSON(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	data, err := bson.Marshal(player)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	document := bson.M{}
+	err = bson.Unmarshal(data, &document)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if !bsonmodel.ValueEquals(player.ToDocument(), document) {
+		t.Errorf("The value expected <%v> but was <%v>", player.ToDocument(), document)
+	}
+	decoded := NewPlayer()
+	err = bson.Unmarshal(data, decoded)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if !bsonmodel.ValueEquals(player.ToDocument(), decoded.ToDocument()) {
+		t.Errorf("The value expected <%v> but was <%v>", player.ToDocument(), decoded.ToDocument())
+	}
+	if decoded.AnyUpdated() {
+		t.Error("The value expected false but was true")
+	}
+	if decoded.Equipment("11111111-1111-1111-1111-111111111111").Hp() != 12 {
+		t.Errorf("The value expected <%v> but was <%v>", 12, decoded.Equipment("11111111-1111-1111-1111-111111111111").Hp())
+	}
+	if !reflect.DeepEqual([]string{"order-0", "order-1"}, decoded.Cash().OrderIds()) {
+		t.Errorf("The value expected <%v> but was <%v>", []string{"order-0", "order-1"}, decoded.Cash().OrderIds())
+	}
+	if !createTime.Equal(decoded.CreateTime()) {
+		t.Errorf("The value expected <%v> but was <%v>", createTime, decoded.CreateTime())
+	}
+}
+
+func TestLoadPlayerFromRaw(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	expected := NewPlayer()
+	expected.SetUid(123)
+	expected.Wallet().SetCoinTotal(5000)
+	expected.Wallet().SetCoinUsed(2000)
+	expected.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	expected.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	expected.Equipments().Put(equipment1.Id(), equipment1)
+	expected.Items().Put(2001, 10)
+	expected.Items().Put(2002, 1)
+	expected.Cash().Stages().Put(1, 2)
+	expected.Cash().Stages().Put(2, 1)
+	expected.Cash().SetCards([]int{1, 2})
+	expected.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	expected.SetUpdateVersion(1)
+	expected.SetCreateTime(createTime)
+	expected.SetUpdateTime(createTime)
+	expected.Reset()
+	document := expected.ToDocument()
+	document["_uv"] = int64(1)
+	document["wlt"].(bson.M)["ct"] = float64(5000)
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	player, err := LoadPlayerFromRaw(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if !bsonmodel.ValueEquals(expected.ToDocument(), player.ToDocument()) {
+		t.Errorf("The value expected <%v> but was <%v>", expected.ToDocument(), player.ToDocument())
+	}
+	if player.AnyUpdated() {
+		t.Error("The value expected false but was true")
+	}
+	equipment := player.Equipment("12345678-1234-5678-9abc-123456789abc")
+	if equipment.Parent() != player.Equipments() || equipment.Key() != "12345678-1234-5678-9abc-123456789abc" {
+		t.Errorf("The value expected <%v> but was <%v>", "12345678-1234-5678-9abc-123456789abc", equipment.Key())
+	}
+	if player.Items().Get(2001) != 10 {
+		t.Errorf("The value expected <%v> but was <%v>", 10, player.Items().Get(2001))
+	}
+	if !createTime.Equal(player.CreateTime()) {
+		t.Errorf("The value expected <%v> but was <%v>", createTime, player.CreateTime())
+	}
+	raw, err = bson.Marshal(bson.M{"_id": 123, "wlt": "str"})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	_, err = LoadPlayerFromRaw(raw)
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+	_, err = LoadPlayerFromRaw(raw[:10])
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+}
+
+func TestLoadPlayerFromJsonIterator(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	expected := NewPlayer()
+	expected.SetUid(123)
+	expected.Wallet().SetCoinTotal(5000)
+	expected.Wallet().SetCoinUsed(2000)
+	expected.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	expected.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	expected.Equipments().Put(equipment1.Id(), equipment1)
+	expected.Items().Put(2001, 10)
+	expected.Items().Put(2002, 1)
+	expected.Cash().Stages().Put(1, 2)
+	expected.Cash().Stages().Put(2, 1)
+	expected.Cash().SetCards([]int{1, 2})
+	expected.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	expected.SetUpdateVersion(1)
+	expected.SetCreateTime(createTime)
+	expected.SetUpdateTime(createTime)
+	expected.Reset()
+	dataJson, err := expected.ToDataJson()
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	iter := jsoniter.ParseString(jsoniter.ConfigDefault, dataJson)
+	player, err := LoadPlayerFromJsonIterator(iter)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if !bsonmodel.ValueEquals(expected.ToDocument(), player.ToDocument()) {
+		t.Errorf("The value expected <%v> but was <%v>", expected.ToDocument(), player.ToDocument())
+	}
+	if player.AnyUpdated() {
+		t.Error("The value expected false but was true")
+	}
+	equipment := player.Equipment("11111111-1111-1111-1111-111111111111")
+	if equipment.Parent() != player.Equipments() || equipment.Key() != "11111111-1111-1111-1111-111111111111" {
+		t.Errorf("The value expected <%v> but was <%v>", "11111111-1111-1111-1111-111111111111", equipment.Key())
+	}
+	if !createTime.Equal(player.CreateTime()) {
+		t.Errorf("The value expected <%v> but was <%v>", createTime, player.CreateTime())
+	}
+	unmarshaled := NewPlayer()
+	err = unmarshaled.UnmarshalDataJson([]byte(dataJson))
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if !bsonmodel.ValueEquals(expected.ToDocument(), unmarshaled.ToDocument()) {
+		t.Errorf("The value expected <%v> but was <%v>", expected.ToDocument(), unmarshaled.ToDocument())
+	}
+	err = unmarshaled.UnmarshalDataJson([]byte(`{"_id":123,"unknown":{"a":[1,2]},"cs":{"cs":[3],"ois":null}}`))
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if unmarshaled.Uid() != 123 || unmarshaled.Equipments().Size() != 0 || unmarshaled.Items().Size() != 0 {
+		t.Errorf("The value expected <%v> but was <%v>", `{"_id":123}`, unmarshaled.ToDocument())
+	}
+	if !reflect.DeepEqual([]int{3}, unmarshaled.Cash().Cards()) || unmarshaled.Cash().OrderIds() != nil {
+		t.Errorf("The value expected <%v> but was <%v>", []int{3}, unmarshaled.Cash().Cards())
+	}
+	if unmarshaled.Cash().Stages().Size() != 0 || !unmarshaled.CreateTime().IsZero() {
+		t.Errorf("The value expected <%v> but was <%v>", 0, unmarshaled.Cash().Stages().Size())
+	}
+	err = unmarshaled.UnmarshalDataJson([]byte(`{"_id":"abc"}`))
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+	err = unmarshaled.UnmarshalDataJson([]byte(`{"eqm":{"1":{"atk":[]}}}`))
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+}
+
+func TestJsonRoundTrip(t *testing.T) {
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetDiamond(10)
+	equipment := NewEquipment()
+	equipment.SetId("11111111-1111-1111-1111-111111111111")
+	equipment.SetRefId(1101)
+	equipment.SetDef(6)
+	player.Equipments().Put(equipment.Id(), equipment)
+	player.Items().Put(2001, 10)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().SetCards([]int{1, 2})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(time.Now())
+	data, err := json.Marshal(player)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+
+	unmarshaled := NewPlayer()
+	err = json.Unmarshal(data, unmarshaled)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if unmarshaled.Uid() != 123 || unmarshaled.Wallet().Coin() != 5000 || unmarshaled.Wallet().Diamond() != 10 {
+		t.Errorf("The value expected <%v> but was <%v>", string(data), unmarshaled.ToDocument())
+	}
+	if unmarshaled.Items().Get(2001) != 10 || unmarshaled.Cash().Stages().Get(1) != 2 {
+		t.Errorf("The value expected <%v> but was <%v>", string(data), unmarshaled.ToDocument())
+	}
+	e := unmarshaled.Equipment(equipment.Id())
+	if e == nil || e.RefId() != 1101 || e.Def() != 6 {
+		t.Errorf("The value expected <%v> but was <%v>", equipment, e)
+	}
+	if !reflect.DeepEqual([]int{1, 2}, unmarshaled.Cash().Cards()) || unmarshaled.Cash().OrderIds() != nil {
+		t.Errorf("The value expected <%v> but was <%v>", []int{1, 2}, unmarshaled.Cash().Cards())
+	}
+	// json-ignore fields are not in the JSON
+	if unmarshaled.UpdateVersion() != 0 || !unmarshaled.CreateTime().IsZero() {
+		t.Errorf("The value expected <%v> but was <%v>", 0, unmarshaled.UpdateVersion())
+	}
+	remarshaled, err := json.Marshal(unmarshaled)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	var expected, actual interface{}
+	json.Unmarshal(data, &expected)
+	json.Unmarshal(remarshaled, &actual)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("The value expected <%v> but was <%v>", string(data), string(remarshaled))
+	}
+
+	err = json.Unmarshal([]byte(`{"uid":"abc"}`), unmarshaled)
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+}
+
+func TestLoginLogJsonRoundTrip(t *testing.T) {
+	loginTime := time.Unix(1700000000, 0)
+	loginLog := NewLoginLog()
+	loginLog.SetId("log-1")
+	loginLog.SetUid(123)
+	loginLog.SetLoginTime(loginTime)
+	data, err := json.Marshal(loginLog)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	unmarshaled := NewLoginLog()
+	err = json.Unmarshal(data, unmarshaled)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if unmarshaled.Id() != "log-1" || unmarshaled.Uid() != 123 || !unmarshaled.LoginTime().Equal(loginTime) {
+		t.Errorf("The value expected <%v> but was <%v>", string(data), unmarshaled.ToDocument())
+	}
+
+	dataJson, err := loginLog.ToDataJson()
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	err = json.Unmarshal([]byte(dataJson), unmarshaled)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if unmarshaled.Id() != "log-1" || unmarshaled.Uid() != 123 || !unmarshaled.LoginTime().Equal(loginTime) {
+		t.Errorf("The value expected <%v> but was <%v>", dataJson, unmarshaled.ToDocument())
+	}
+}
+
+func TestEncodeDataAndSync(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	player.Wallet().SetCoinTotal(5200)
+	player.Equipments().Remove("12345678-1234-5678-9abc-123456789abc")
+	player.Equipment("11111111-1111-1111-1111-111111111111").SetHp(20)
+	player.Items().Put(2001, 12)
+	player.Cash().Stages().Remove(1)
+	player.Cash().SetCards(nil)
+	player.SetUpdateTime(time.Now().Truncate(time.Millisecond))
+	equipment := NewEquipment()
+	equipment.SetId("22222222-2222-2222-2222-222222222222")
+	equipment.SetAtk(5)
+	player.Equipments().Put(equipment.Id(), equipment)
+	assertJsonEquals := func(expected interface{}, value string) {
+		expectedJson, err := jsoniter.MarshalToString(expected)
+		if err != nil {
+			t.Fatalf("Unexpected error occurs: %e", err)
+		}
+		var e, v interface{}
+		jsoniter.UnmarshalFromString(expectedJson, &e)
+		err = jsoniter.UnmarshalFromString(value, &v)
+		if err != nil {
+			t.Fatalf("Unexpected error occurs: %e", err)
+		}
+		if !reflect.DeepEqual(e, v) {
+			t.Errorf("The value expected <%v> but was <%v>", expectedJson, value)
+		}
+	}
+	dataJson, err := player.ToDataJson()
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	assertJsonEquals(player.ToData(), dataJson)
+	for _, marker := range []bool{false, true} {
+		player.SetSyncReplaceMarker(marker)
+		syncJson, err := player.ToSyncJson()
+		if err != nil {
+			t.Fatalf("Unexpected error occurs: %e", err)
+		}
+		assertJsonEquals(player.ToSync(), syncJson)
+	}
+	player.SetSyncReplaceMarker(false)
+	buffer := &bytes.Buffer{}
+	err = player.WriteDataJson(buffer)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	assertJsonEquals(player.ToData(), buffer.String())
+	buffer.Reset()
+	err = player.Cash().WriteSyncJson(buffer)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	assertJsonEquals(player.Cash().ToSync(), buffer.String())
+	itemsJson, err := player.Items().ToDataJson()
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	assertJsonEquals(player.Items().ToData(), itemsJson)
+}
+
+func TestMapValueXPath(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	equipment := player.Equipment("11111111-1111-1111-1111-111111111111")
+	xpath := equipment.XPath()
+	if xpath.Value() != "eqm.11111111-1111-1111-1111-111111111111" {
+		t.Errorf("The value expected <%v> but was <%v>", "eqm.11111111-1111-1111-1111-111111111111", xpath.Value())
+	}
+	if equipment.XPath() != xpath {
+		t.Error("The value expected cached path but not")
+	}
+	player.Equipments().Remove(equipment.Id())
+	player.Equipments().Put("moved", equipment)
+	if equipment.XPath().Value() != "eqm.moved" {
+		t.Errorf("The value expected <%v> but was <%v>", "eqm.moved", equipment.XPath().Value())
+	}
+	player.Items().Put(2001, 11)
+	updates := player.ToUpdate()
+	if updates["$set"].(bson.M)["itm.2001"] != 11 {
+		t.Errorf("The value expected <%v> but was <%v>", 11, updates["$set"].(bson.M)["itm.2001"])
+	}
+}
+
+func TestToOrderedDocument(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	player.Items().Put(10, 1)
+	player.Items().Put(9, 1)
+	doc := player.ToOrderedDocument()
+	keys := make([]string, 0, len(doc))
+	for _, e := range doc {
+		keys = append(keys, e.Key)
+	}
+	expected := []string{"_id", "wlt", "eqm", "itm", "cs", "_uv", "_ct", "_ut"}
+	if !reflect.DeepEqual(expected, keys) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, keys)
+	}
+	keys = keys[:0]
+	for _, e := range doc[3].Value.(bson.D) {
+		keys = append(keys, e.Key)
+	}
+	expected = []string{"9", "10", "2001", "2002"}
+	if !reflect.DeepEqual(expected, keys) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, keys)
+	}
+	keys = keys[:0]
+	for _, e := range doc[2].Value.(bson.D) {
+		keys = append(keys, e.Key)
+	}
+	expected = []string{"11111111-1111-1111-1111-111111111111", "12345678-1234-5678-9abc-123456789abc"}
+	if !reflect.DeepEqual(expected, keys) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, keys)
+	}
+}
+
+func TestToOrderedUpdate(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := NewPlayer()
+	player.SetUid(123)
+	player.Wallet().SetCoinTotal(5000)
+	player.Wallet().SetCoinUsed(2000)
+	player.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	player.Items().Put(2001, 10)
+	player.Items().Put(2002, 1)
+	player.Cash().Stages().Put(1, 2)
+	player.Cash().Stages().Put(2, 1)
+	player.Cash().SetCards([]int{1, 2})
+	player.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	player.SetUpdateVersion(1)
+	player.SetCreateTime(createTime)
+	player.SetUpdateTime(createTime)
+	player.Reset()
+	player.Wallet().SetCoinTotal(5200)
+	player.Equipments().Remove("12345678-1234-5678-9abc-123456789abc")
+	player.Equipment("11111111-1111-1111-1111-111111111111").SetHp(20)
+	player.Items().Put(2001, 12)
+	player.Cash().Stages().Remove(1)
+	player.Cash().SetCards(nil)
+	player.SetUpdateTime(time.Now().Truncate(time.Millisecond))
+	player.Items().Put(10, 1)
+	updates := player.ToOrderedUpdate()
+	operators := make([]string, 0, len(updates))
+	for _, e := range updates {
+		operators = append(operators, e.Key)
+	}
+	expected := []string{"$set", "$unset"}
+	if !reflect.DeepEqual(expected, operators) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, operators)
+	}
+	keys := make([]string, 0)
+	for _, e := range updates[0].Value.(bson.D) {
+		keys = append(keys, e.Key)
+	}
+	expected = []string{"wlt.ct", "eqm.11111111-1111-1111-1111-111111111111.hp", "itm.10", "itm.2001", "_ut"}
+	if !reflect.DeepEqual(expected, keys) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, keys)
+	}
+	keys = keys[:0]
+	for _, e := range updates[1].Value.(bson.D) {
+		keys = append(keys, e.Key)
+	}
+	expected = []string{"eqm.12345678-1234-5678-9abc-123456789abc", "cs.stg.1", "cs.cs"}
+	if !reflect.DeepEqual(expected, keys) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, keys)
+	}
+}
+
+func TestAcquireAndRelease(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	player := AcquirePlayer()
+	sample := NewPlayer()
+	sample.SetUid(123)
+	sample.Wallet().SetCoinTotal(5000)
+	sample.Wallet().SetCoinUsed(2000)
+	sample.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	sample.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	sample.Equipments().Put(equipment1.Id(), equipment1)
+	sample.Items().Put(2001, 10)
+	sample.Items().Put(2002, 1)
+	sample.Cash().Stages().Put(1, 2)
+	sample.Cash().Stages().Put(2, 1)
+	sample.Cash().SetCards([]int{1, 2})
+	sample.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	sample.SetUpdateVersion(1)
+	sample.SetCreateTime(createTime)
+	sample.SetUpdateTime(createTime)
+	sample.Reset()
+	err := player.LoadDocument(sample.ToDocument())
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	player.Wallet().SetCoinTotal(5200)
+	player.Equipments().Remove("12345678-1234-5678-9abc-123456789abc")
+	player.Equipment("11111111-1111-1111-1111-111111111111").SetHp(20)
+	player.Items().Put(2001, 12)
+	player.Cash().Stages().Remove(1)
+	player.Cash().SetCards(nil)
+	player.SetUpdateTime(time.Now().Truncate(time.Millisecond))
+	player.Release()
+
+	player = AcquirePlayer()
+	if !reflect.DeepEqual(NewPlayer().ToDocument(), player.ToDocument()) {
+		t.Errorf("The value expected <%v> but was <%v>", NewPlayer().ToDocument(), player.ToDocument())
+	}
+	if player.AnyUpdated() {
+		t.Error("The value expected false but was true")
+	}
+	if player.Items().Size() != 0 || player.Equipments().Size() != 0 || player.Cash().Stages().Size() != 0 {
+		t.Error("Expected empty maps but not")
+	}
+	player.Release()
+
+	if bsonmodel.Debug {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic but not")
+			}
+		}()
+		player.ToUpdate()
+	}
+}
+
+func TestReleaseWithoutLazyDecoding(t *testing.T) {
+	player := AcquirePlayer()
+	err := player.LoadDocument(bson.M{"_id": 123, "eqm": bson.M{"id": "invalid"}, "itm": bson.M{"invalid": 1}})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	equipments, items := player.Equipments(), player.Items()
+	player.Release()
+	if !equipments.Loaded() || !items.Loaded() {
+		t.Error("Expected lazy sources dropped but not")
+	}
+	if bsonmodel.Debug {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic but not")
+			}
+		}()
+		equipments.Load()
+		return
+	}
+	if err = equipments.Load(); err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+	if err = items.Load(); err != nil {
+		t.Errorf("Unexpected error occurs: %e", err)
+	}
+}
+
+func TestLazyLoad(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	sample := NewPlayer()
+	sample.SetUid(123)
+	sample.Wallet().SetCoinTotal(5000)
+	sample.Wallet().SetCoinUsed(2000)
+	sample.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	sample.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	sample.Equipments().Put(equipment1.Id(), equipment1)
+	sample.Items().Put(2001, 10)
+	sample.Items().Put(2002, 1)
+	sample.Cash().Stages().Put(1, 2)
+	sample.Cash().Stages().Put(2, 1)
+	sample.Cash().SetCards([]int{1, 2})
+	sample.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	sample.SetUpdateVersion(1)
+	sample.SetCreateTime(createTime)
+	sample.SetUpdateTime(createTime)
+	sample.Reset()
+	document := sample.ToDocument()
+	player, err := LoadPlayerFromDocument(document)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if player.Equipments().Loaded() || player.Items().Loaded() {
+		t.Error("Expected lazy maps not loaded but loaded")
+	}
+	player.Wallet().SetCoinTotal(5200)
+	update := player.ToUpdate()
+	sync := player.ToSync()
+	if player.Equipments().Loaded() || player.Items().Loaded() {
+		t.Error("Expected lazy maps not loaded but loaded")
+	}
+	if len(update["$set"].(bson.M)) != 1 {
+		t.Errorf("The value expected <%v> but was <%v>", 1, len(update["$set"].(bson.M)))
+	}
+	if _, ok := sync.(map[string]interface{})["equipments"]; ok {
+		t.Error("Expected no equipments in sync but exists")
+	}
+	if player.Items().Get(2001) != 10 {
+		t.Errorf("The value expected <%v> but was <%v>", 10, player.Items().Get(2001))
+	}
+	if !player.Items().Loaded() || player.Equipments().Loaded() {
+		t.Error("Expected only items loaded but not")
+	}
+	if !reflect.DeepEqual(document["eqm"], player.ToDocument()["eqm"]) {
+		t.Errorf("The value expected <%v> but was <%v>", document["eqm"], player.ToDocument()["eqm"])
+	}
+
+	data, err := bson.Marshal(document)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	player = NewPlayer()
+	err = player.UnmarshalBSON(data)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	for i := range data {
+		data[i] = 0
+	}
+	if player.Equipments().Loaded() {
+		t.Error("Expected lazy maps not loaded but loaded")
+	}
+	if player.Equipment("11111111-1111-1111-1111-111111111111").Hp() != 12 {
+		t.Errorf("The value expected <%v> but was <%v>", 12, player.Equipment("11111111-1111-1111-1111-111111111111").Hp())
+	}
+
+	player, _ = LoadPlayerFromDocument(document)
+	player.Begin()
+	player.Items().Put(2001, 12)
+	player.Rollback()
+	if player.Items().Get(2001) != 10 || player.AnyUpdated() {
+		t.Errorf("The value expected <%v> but was <%v>", 10, player.Items().Get(2001))
+	}
+
+	err = player.LoadDocument(bson.M{"itm": bson.M{"1": "x"}})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if player.Items().Load() == nil {
+		t.Error("Expected error but not")
+	}
+	raw, err := bson.Marshal(bson.M{"itm": bson.M{"1": "x"}})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	err = player.UnmarshalBSON(raw)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if player.Items().Load() == nil {
+		t.Error("Expected error but not")
+	}
+	if player.LoadDocumentTracked(bson.M{"itm": bson.M{"1": "x"}}) == nil {
+		t.Error("Expected error but not")
+	}
+
+	player, _ = LoadPlayerFromDocument(document)
+	equipment := player.Equipment("11111111-1111-1111-1111-111111111111")
+	err = player.LoadDocument(document)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if equipment.Parent() != nil {
+		t.Errorf("The value expected <%v> but was <%v>", nil, equipment.Parent())
+	}
+	equipment.SetHp(20)
+	if player.AnyUpdated() {
+		t.Error("Expected no update but updated")
+	}
+}
+
+func TestPlayerProjection(t *testing.T) {
+	projection := PlayerProjection().Wallet().Cash().Build()
+	expected := bson.M{"wlt": 1, "cs": 1}
+	if !reflect.DeepEqual(expected, projection) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, projection)
+	}
+}
+
+func TestLoadPartialDocument(t *testing.T) {
+	createTime := time.Now().Add(-1 * time.Hour).Truncate(time.Millisecond)
+	sample := NewPlayer()
+	sample.SetUid(123)
+	sample.Wallet().SetCoinTotal(5000)
+	sample.Wallet().SetCoinUsed(2000)
+	sample.Wallet().SetDiamond(10)
+	equipment0 := NewEquipment()
+	equipment0.SetId("12345678-1234-5678-9abc-123456789abc")
+	equipment0.SetRefId(1001)
+	equipment0.SetAtk(12)
+	sample.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("11111111-1111-1111-1111-111111111111")
+	equipment1.SetRefId(1101)
+	equipment1.SetDef(6)
+	equipment1.SetHp(12)
+	sample.Equipments().Put(equipment1.Id(), equipment1)
+	sample.Items().Put(2001, 10)
+	sample.Items().Put(2002, 1)
+	sample.Cash().Stages().Put(1, 2)
+	sample.Cash().Stages().Put(2, 1)
+	sample.Cash().SetCards([]int{1, 2})
+	sample.Cash().SetOrderIds([]string{"order-0", "order-1"})
+	sample.SetUpdateVersion(1)
+	sample.SetCreateTime(createTime)
+	sample.SetUpdateTime(createTime)
+	sample.Reset()
+	document := sample.ToDocument()
+	projection := PlayerProjection().Wallet().Cash().Build()
+	partial := bson.M{"_id": document["_id"], "wlt": document["wlt"], "cs": document["cs"]}
+	player, err := LoadPlayerPartially(partial, projection)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if player.Uid() != 123 {
+		t.Errorf("The value expected <%v> but was <%v>", 123, player.Uid())
+	}
+	if player.Wallet().CoinTotal() != 5000 {
+		t.Errorf("The value expected <%v> but was <%v>", 5000, player.Wallet().CoinTotal())
+	}
+	if player.FieldLoaded("itm") || !player.FieldLoaded("wlt") {
+		t.Error("Expected only projected fields loaded but not")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic but not")
+			}
+		}()
+		player.Items()
+	}()
+	_, err = player.GetField("itm")
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+	err = player.SetField("_ut", time.Now())
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+
+	player.Wallet().SetCoinTotal(5200)
+	player.Cash().Stages().Remove(1)
+	updates := player.ToUpdate()
+	expected := bson.M{"$set": bson.M{"wlt.ct": 5200}, "$unset": bson.M{"cs.stg.1": ""}}
+	if !reflect.DeepEqual(expected, updates) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, updates)
+	}
+
+	sync := player.ToSync().(map[string]interface{})
+	if len(sync) != 2 || sync["wallet"] == nil || sync["cash"] == nil {
+		t.Errorf("The value expected <%v> but was <%v>", "[wallet cash]", sync)
+	}
+	doc := player.ToDocument()
+	if len(doc) != 3 || doc["_id"] != 123 || doc["wlt"] == nil || doc["cs"] == nil {
+		t.Errorf("The value expected <%v> but was <%v>", "[_id wlt cs]", doc)
+	}
+	data := player.ToData().(map[string]interface{})
+	if len(data) != 3 || data["_id"] != 123 || data["wlt"] == nil || data["cs"] == nil {
+		t.Errorf("The value expected <%v> but was <%v>", "[_id wlt cs]", data)
+	}
+	raw, err := player.MarshalBSON()
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if _, err = bson.Raw(raw).LookupErr("itm"); err == nil {
+		t.Error("Expected no items in BSON but exists")
+	}
+	dataJson, err := player.ToDataJson()
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	dataFields := make(map[string]interface{})
+	err = jsoniter.UnmarshalFromString(dataJson, &dataFields)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if len(dataFields) != 3 || dataFields["itm"] != nil {
+		t.Errorf("The value expected <%v> but was <%v>", "[_id wlt cs]", dataFields)
+	}
+	jsonString, err := player.MarshalToJsonString()
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	expectedJson := `{"uid":123,"wallet":{"coinTotal":5200,"coin":3200,"diamond":10},"cash":{"stages":{"2":1},"cards":[1,2],"orderIds":["order-0","order-1"]}}`
+	if expectedJson != jsonString {
+		t.Errorf("The value expected <%v> but was <%v>", expectedJson, jsonString)
+	}
+
+	player, err = LoadPlayerPartially(bson.M{"_id": 123}, bson.M{"_id": 1})
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if !player.FieldLoaded("_id") || player.FieldLoaded("wlt") {
+		t.Error("Expected only _id loaded but not")
+	}
+	doc = player.ToDocument()
+	if !reflect.DeepEqual(bson.M{"_id": 123}, doc) {
+		t.Errorf("The value expected <%v> but was <%v>", bson.M{"_id": 123}, doc)
+	}
+
+	_, err = LoadPlayerPartially(partial, bson.M{"wlt.ct": 1})
+	if err == nil {
+		t.Error("Expected error but not")
+	}
+
+	err = player.LoadDocument(document)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if !player.FieldLoaded("itm") || player.Items().Get(2001) != 10 {
+		t.Error("Expected all fields loaded but not")
+	}
+}
+
+func TestClonePartialPlayer(t *testing.T) {
+	document := bson.M{"_id": 123, "wlt": bson.M{"ct": 5000, "cu": 2000, "d": 10}}
+	player, err := LoadPlayerPartially(document, PlayerProjection().Wallet().Build())
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	clone := player.Clone()
+	if clone.Uid() != 123 {
+		t.Errorf("The value expected <%v> but was <%v>", 123, clone.Uid())
+	}
+	if clone.Wallet().Coin() != 3000 {
+		t.Errorf("The value expected <%v> but was <%v>", 3000, clone.Wallet().Coin())
+	}
+	if clone.FieldLoaded("itm") || !clone.FieldLoaded("wlt") {
+		t.Error("Expected only projected fields loaded but not")
+	}
+	doc := clone.ToDocument()
+	expected := bson.M{"_id": 123, "wlt": bson.M{"ct": 5000, "cu": 2000, "d": 10}}
+	if !reflect.DeepEqual(expected, doc) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, doc)
+	}
+	clone.Wallet().SetCoinTotal(5200)
+	updates := clone.ToUpdate()
+	expected = bson.M{"$set": bson.M{"wlt.ct": 5200}}
+	if !reflect.DeepEqual(expected, updates) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, updates)
+	}
+
+	full := NewPlayer()
+	full.SetUid(1)
+	full.Items().Put(2001, 10)
+	full.Reset()
+	full.CopyFrom(player)
+	if full.FieldLoaded("itm") {
+		t.Error("Expected items not loaded but loaded")
+	}
+	updates = full.ToUpdate()
+	expected = bson.M{"$set": bson.M{"_id": 123, "wlt.ct": 5000, "wlt.cu": 2000, "wlt.d": 10}}
+	if !reflect.DeepEqual(expected, updates) {
+		t.Errorf("The value expected <%v> but was <%v>", expected, updates)
+	}
+}
+
+func TestPlayerPath(t *testing.T) {
+	paths := []struct {
+		expected string
+		xpath    bsonmodel.DotNotation
+	}{
+		{"_id", PlayerPath.Uid()},
+		{"", PlayerPath.Path()},
+		{"wlt", PlayerPath.Wallet().Path()},
+		{"wlt.ct", PlayerPath.Wallet().CoinTotal()},
+		{"eqm", PlayerPath.Equipments().Path()},
+		{"eqm.abc", PlayerPath.Equipments().Key("abc").Path()},
+		{"eqm.abc.atk", PlayerPath.Equipments().Key("abc").Atk()},
+		{"itm.2001", PlayerPath.Items().Key(2001)},
+		{"cs.stg.1", PlayerPath.Cash().Stages().Key(1)},
+		{"cs.ois", PlayerPath.Cash().OrderIds()},
+	}
+	for _, p := range paths {
+		if p.xpath.Value() != p.expected {
+			t.Errorf("The value expected <%v> but was <%v>", p.expected, p.xpath.Value())
+		}
+	}
+
+	filter := bsonmodel.Filter(
+		bsonmodel.Gte(PlayerPath.Wallet().CoinTotal(), 100),
+		bsonmodel.Lt(PlayerPath.Wallet().CoinTotal(), 200),
+		bsonmodel.Exists(PlayerPath.Equipments().Key("abc").Path(), true),
+	)
+	expectedFilter := bson.D{
+		{Key: "wlt.ct", Value: bson.D{{Key: "$gte", Value: 100}, {Key: "$lt", Value: 200}}},
+		{Key: "eqm.abc", Value: bson.D{{Key: "$exists", Value: true}}},
+	}
+	if !reflect.DeepEqual(expectedFilter, filter) {
+		t.Errorf("The value expected <%v> but was <%v>", expectedFilter, filter)
+	}
+	sort := bsonmodel.Sort(bsonmodel.Desc(PlayerPath.UpdateTime()), bsonmodel.Asc(PlayerPath.Uid()))
+	expectedSort := bson.D{{Key: "_ut", Value: -1}, {Key: "_id", Value: 1}}
+	if !reflect.DeepEqual(expectedSort, sort) {
+		t.Errorf("The value expected <%v> but was <%v>", expectedSort, sort)
+	}
+}
+
+func TestPlayerIndexes(t *testing.T) {
+	indexes := PlayerIndexes()
+	if len(indexes) != 2 {
+		t.Fatalf("The value expected <%v> but was <%v>", 2, len(indexes))
+	}
+	expectedKeys := []bson.D{
+		{{Key: "wlt.ct", Value: -1}, {Key: "_id", Value: 1}},
+		{{Key: "cs.ois", Value: 1}},
+	}
+	for i, index := range indexes {
+		if !reflect.DeepEqual(expectedKeys[i], index.Keys) {
+			t.Errorf("The value expected <%v> but was <%v>", expectedKeys[i], index.Keys)
+		}
+	}
+	options := indexes[1].Options
+	if options.Name == nil || *options.Name != "ois_1" {
+		t.Errorf("The value expected <%v> but was <%v>", "ois_1", options.Name)
+	}
+	if options.Unique == nil || !*options.Unique {
+		t.Error("The value expected true but was false")
+	}
+	expectedPartial := bson.D{{Key: "cs.ois", Value: bson.D{{Key: "$exists", Value: true}}}}
+	if !reflect.DeepEqual(expectedPartial, options.PartialFilterExpression) {
+		t.Errorf("The value expected <%v> but was <%v>", expectedPartial, options.PartialFilterExpression)
+	}
+	if indexes[0].Options.Name != nil || indexes[0].Options.Unique != nil {
+		t.Error("Expected default options but not")
+	}
+	for _, index := range indexes {
+		if index.Options.ExpireAfterSeconds != nil {
+			t.Errorf("The value expected <%v> but was <%v>", nil, *index.Options.ExpireAfterSeconds)
+		}
+	}
+}
+
+func TestLoginLogIndexes(t *testing.T) {
+	indexes := LoginLogIndexes()
+	if len(indexes) != 2 {
+		t.Fatalf("The value expected <%v> but was <%v>", 2, len(indexes))
+	}
+	expectedKeys := []bson.D{
+		{{Key: "uid", Value: 1}, {Key: "lt", Value: -1}},
+		{{Key: "lt", Value: 1}},
+	}
+	for i, index := range indexes {
+		if !reflect.DeepEqual(expectedKeys[i], index.Keys) {
+			t.Errorf("The value expected <%v> but was <%v>", expectedKeys[i], index.Keys)
+		}
+	}
+	if indexes[0].Options.ExpireAfterSeconds != nil {
+		t.Errorf("The value expected <%v> but was <%v>", nil, *indexes[0].Options.ExpireAfterSeconds)
+	}
+	options := indexes[1].Options
+	if options.ExpireAfterSeconds == nil || *options.ExpireAfterSeconds != 2592000 {
+		t.Errorf("The value expected <%v> but was <%v>", 2592000, options.ExpireAfterSeconds)
+	}
+}
+
+func TestLoginLog(t *testing.T) {
+	loginTime := time.Unix(1700000000, 0)
+	loginLog := NewLoginLog()
+	loginLog.SetId("log-1")
+	loginLog.SetUid(123)
+	loginLog.SetLoginTime(loginTime)
+	expectedUpdate := bson.M{"$set": bson.M{"_id": "log-1", "uid": 123, "lt": primitive.NewDateTimeFromTime(loginTime)}}
+	if update := loginLog.ToUpdate(); !reflect.DeepEqual(expectedUpdate, update) {
+		t.Errorf("The value expected <%v> but was <%v>", expectedUpdate, update)
+	}
+	expectedSync := map[string]interface{}{"id": "log-1", "uid": 123, "loginTime": int64(1700000000)}
+	if sync := loginLog.ToSync(); !reflect.DeepEqual(expectedSync, sync) {
+		t.Errorf("The value expected <%v> but was <%v>", expectedSync, sync)
+	}
+	doc := loginLog.ToDocument()
+	loaded, err := LoadLoginLogFromDocument(doc)
+	if err != nil {
+		t.Fatalf("Unexpected error occurs: %e", err)
+	}
+	if loaded.Id() != "log-1" {
+		t.Errorf("The value expected <%v> but was <%v>", "log-1", loaded.Id())
+	}
+	if loaded.Uid() != 123 {
+		t.Errorf("The value expected <%v> but was <%v>", 123, loaded.Uid())
+	}
+	if !loaded.LoginTime().Equal(loginTime) {
+		t.Errorf("The value expected <%v> but was <%v>", loginTime, loaded.LoginTime())
+	}
+	if loaded.AnyUpdated() {
+		t.Error("Expected no update but not")
+	}
+}
+
+func TestObjectMapKeys(t *testing.T) {
+	player := NewPlayer()
+	equipment0 := NewEquipment()
+	equipment0.SetId("b")
+	player.Equipments().Put(equipment0.Id(), equipment0)
+	equipment1 := NewEquipment()
+	equipment1.SetId("a")
+	player.Equipments().Put(equipment1.Id(), equipment1)
+	keys := player.Equipments().Keys()
+	if len(keys) != 2 {
+		t.Fatalf("The value expected <%v> but was <%v>", 2, len(keys))
+	}
+	if !(keys[0] == "a" && keys[1] == "b") && !(keys[0] == "b" && keys[1] == "a") {
+		t.Errorf("The value expected <%v> but was <%v>", []string{"a", "b"}, keys)
+	}
+}
@@ -0,0 +1,796 @@
+package example
+
+import (
+	"io"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/bits-and-blooms/bitset"
+	"github.com/fmjsjx/bson-model-go/bsonmodel"
+	jsoniter "github.com/json-iterator/go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LoginLog interface {
+	bsonmodel.RootModel
+	Id() string
+	SetId(id string)
+	OnIdChanged(listener func(oldValue string, newValue string)) (remove func(), err error)
+	Uid() int
+	SetUid(uid int)
+	OnUidChanged(listener func(oldValue int, newValue int)) (remove func(), err error)
+	LoginTime() time.Time
+	SetLoginTime(loginTime time.Time)
+	OnLoginTimeChanged(listener func(oldValue time.Time, newValue time.Time)) (remove func(), err error)
+	Clone() LoginLog
+	CopyFrom(other LoginLog)
+}
+
+const (
+	BnameLoginLogId        = "_id"
+	BnameLoginLogUid       = "uid"
+	BnameLoginLogLoginTime = "lt"
+)
+
+var loginLogSchema = &bsonmodel.ModelSchema{
+	Name: "LoginLog",
+	Type: bsonmodel.ModelTypeRoot,
+	Fields: []*bsonmodel.FieldSchema{
+		{Name: "id", Bname: "_id", Type: bsonmodel.FieldTypeString, Required: true},
+		{Name: "uid", Bname: "uid", Type: bsonmodel.FieldTypeInt},
+		{Name: "loginTime", Bname: "lt", Type: bsonmodel.FieldTypeDateTime},
+	},
+}
+
+func LoginLogSchema() *bsonmodel.ModelSchema {
+	return loginLogSchema
+}
+
+type defaultLoginLog struct {
+	updatedFields *bitset.BitSet
+	observers     bsonmodel.ChangeObservers
+	transaction   bsonmodel.Transaction
+	released      bool
+	partial       bsonmodel.PartialState
+	replaceMarker bool
+	id            string
+	uid           int
+	loginTime     time.Time
+}
+
+func (self *defaultLoginLog) ToBson() interface{} {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return self.ToDocument()
+}
+
+func (self *defaultLoginLog) ToData() interface{} {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	data := make(map[string]interface{})
+	data["_id"] = self.id
+	data["uid"] = self.uid
+	data["lt"] = self.loginTime.UnixMilli()
+	self.partial.FilterFields(data)
+	return data
+}
+
+func (self *defaultLoginLog) LoadJsoniter(any jsoniter.Any) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	bsonmodel.SaveState(self)
+	self.partial.Clear()
+	if any.ValueType() != jsoniter.ObjectValue {
+		self.Reset()
+		return nil
+	}
+	id, err := bsonmodel.AnyStringValue(any.Get("_id"), "")
+	if err != nil {
+		return err
+	}
+	self.id = id
+	uid, err := bsonmodel.AnyIntValue(any.Get("uid"), 0)
+	if err != nil {
+		return err
+	}
+	self.uid = uid
+	loginTime, err := bsonmodel.AnyDateTimeValue(any.Get("lt"))
+	if err != nil {
+		return err
+	}
+	self.loginTime = loginTime
+	self.Reset()
+	return nil
+}
+
+func (self *defaultLoginLog) LoadJsonIterator(iter *jsoniter.Iterator) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	bsonmodel.SaveState(self)
+	self.partial.Clear()
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		self.Reset()
+		return bsonmodel.IterError(iter)
+	}
+	self.id = ""
+	self.uid = 0
+	self.loginTime = time.Time{}
+	var err error
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, field string) bool {
+		switch field {
+		case BnameLoginLogId, "id":
+			self.id, err = bsonmodel.IterStringValue(iter, "")
+		case BnameLoginLogUid:
+			self.uid, err = bsonmodel.IterIntValue(iter, 0)
+		case BnameLoginLogLoginTime:
+			self.loginTime, err = bsonmodel.IterDateTimeValue(iter)
+		case "loginTime":
+			self.loginTime, err = bsonmodel.IterUnixValue(iter)
+		default:
+			iter.Skip()
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	self.Reset()
+	return bsonmodel.IterError(iter)
+}
+
+func (self *defaultLoginLog) Reset() {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	bsonmodel.SaveState(self)
+	self.updatedFields.ClearAll()
+}
+
+func (self *defaultLoginLog) clear() {
+	self.id = ""
+	self.uid = 0
+	self.loginTime = time.Time{}
+}
+
+func (self *defaultLoginLog) AnyUpdated() bool {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return self.updatedFields.Any()
+}
+
+func (self *defaultLoginLog) AnyDeleted() bool {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return self.DeletedSize() > 0
+}
+
+func (self *defaultLoginLog) Parent() bsonmodel.BsonModel {
+	return nil
+}
+
+func (self *defaultLoginLog) XPath() bsonmodel.DotNotation {
+	return bsonmodel.RootPath()
+}
+
+func (self *defaultLoginLog) AppendUpdates(updates bson.M) bson.M {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	dset := bsonmodel.FixedEmbedded(updates, "$set")
+	updatedFields := self.updatedFields
+	if updatedFields.Test(1) {
+		dset["_id"] = self.id
+	}
+	if updatedFields.Test(2) {
+		dset["uid"] = self.uid
+	}
+	if updatedFields.Test(3) {
+		dset["lt"] = primitive.NewDateTimeFromTime(self.loginTime)
+	}
+	return updates
+}
+
+func (self *defaultLoginLog) ToDocument() bson.M {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	doc := bson.M{}
+	doc["_id"] = self.id
+	doc["uid"] = self.uid
+	doc["lt"] = primitive.NewDateTimeFromTime(self.loginTime)
+	self.partial.FilterFields(doc)
+	return doc
+}
+
+func (self *defaultLoginLog) LoadDocument(document bson.M) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	bsonmodel.SaveState(self)
+	self.partial.Clear()
+	id, err := bsonmodel.StringValue(document, "_id", "")
+	if err != nil {
+		return err
+	}
+	self.id = id
+	uid, err := bsonmodel.IntValue(document, "uid", 0)
+	if err != nil {
+		return err
+	}
+	self.uid = uid
+	loginTime, err := bsonmodel.DateTimeValue(document, "lt")
+	if err != nil {
+		return err
+	}
+	self.loginTime = loginTime
+	self.Reset()
+	return nil
+}
+
+func (self *defaultLoginLog) LoadRaw(raw bson.Raw) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	bsonmodel.SaveState(self)
+	self.partial.Clear()
+	id, err := bsonmodel.RawStringValue(raw, "_id", "")
+	if err != nil {
+		return err
+	}
+	self.id = id
+	uid, err := bsonmodel.RawIntValue(raw, "uid", 0)
+	if err != nil {
+		return err
+	}
+	self.uid = uid
+	loginTime, err := bsonmodel.RawDateTimeValue(raw, "lt")
+	if err != nil {
+		return err
+	}
+	self.loginTime = loginTime
+	self.Reset()
+	return nil
+}
+
+func (self *defaultLoginLog) LoadDocumentTracked(document bson.M) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	loaded := NewLoginLog()
+	err := loaded.LoadDocument(document)
+	if err != nil {
+		return err
+	}
+	self.CopyFrom(loaded)
+	return nil
+}
+
+func (self *defaultLoginLog) DeletedSize() int {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return 0
+}
+
+func (self *defaultLoginLog) FullyUpdate() bool {
+	return false
+}
+
+func (self *defaultLoginLog) SetFullyUpdate(fullyUpdate bool) {
+	// no effect
+}
+
+func (self *defaultLoginLog) Snapshot() func() {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	updatedFields := self.updatedFields.Clone()
+	id := self.id
+	uid := self.uid
+	loginTime := self.loginTime
+	return func() {
+		self.updatedFields = updatedFields
+		self.id = id
+		self.uid = uid
+		self.loginTime = loginTime
+	}
+}
+
+func (self *defaultLoginLog) ToSync() interface{} {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	sync := make(map[string]interface{})
+	updatedFields := self.updatedFields
+	if updatedFields.Test(1) {
+		sync["id"] = self.id
+	}
+	if updatedFields.Test(2) {
+		sync["uid"] = self.uid
+	}
+	if updatedFields.Test(3) {
+		sync["loginTime"] = self.loginTime.Unix()
+	}
+	return sync
+}
+
+func (self *defaultLoginLog) ToDelete() interface{} {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	delete := make(map[string]interface{})
+	return delete
+}
+
+func (self *defaultLoginLog) ToDataJson() (string, error) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.DataJsonOf(self)
+}
+
+func (self *defaultLoginLog) ToSyncJson() (string, error) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.SyncJsonOf(self)
+}
+
+func (self *defaultLoginLog) ToDeleteJson() (string, error) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return jsoniter.MarshalToString(self.ToDelete())
+}
+
+func (self *defaultLoginLog) EncodeData(stream *jsoniter.Stream) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	object := bsonmodel.BeginJsonObject(stream)
+	if self.partial.Loaded(BnameLoginLogId) {
+		object.Field("_id")
+		stream.WriteString(self.id)
+	}
+	if self.partial.Loaded(BnameLoginLogUid) {
+		object.Field("uid")
+		stream.WriteInt(self.uid)
+	}
+	if self.partial.Loaded(BnameLoginLogLoginTime) {
+		object.Field("lt")
+		stream.WriteInt64(self.loginTime.UnixMilli())
+	}
+	object.End()
+}
+
+func (self *defaultLoginLog) EncodeSync(stream *jsoniter.Stream) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	object := bsonmodel.BeginJsonObject(stream)
+	if self.updatedFields.Test(1) {
+		object.Field("id")
+		stream.WriteString(self.id)
+	}
+	if self.updatedFields.Test(2) {
+		object.Field("uid")
+		stream.WriteInt(self.uid)
+	}
+	if self.updatedFields.Test(3) {
+		object.Field("loginTime")
+		stream.WriteInt64(self.loginTime.Unix())
+	}
+	object.End()
+}
+
+func (self *defaultLoginLog) WriteDataJson(w io.Writer) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.WriteDataJson(w, self)
+}
+
+func (self *defaultLoginLog) WriteSyncJson(w io.Writer) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.WriteSyncJson(w, self)
+}
+
+func (self *defaultLoginLog) ToMergePatch() interface{} {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.MergePatchOf(self)
+}
+
+func (self *defaultLoginLog) ToJsonPatch() []bsonmodel.JsonPatchOperation {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.JsonPatchOf(self)
+}
+
+func (self *defaultLoginLog) Changes() []bsonmodel.Change {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.ChangesOf(self)
+}
+
+func (self *defaultLoginLog) Schema() *bsonmodel.ModelSchema {
+	return loginLogSchema
+}
+
+func (self *defaultLoginLog) GetField(name string) (interface{}, error) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	err := self.partial.CheckField(loginLogSchema, name)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case BnameLoginLogId:
+		return self.id, nil
+	case BnameLoginLogUid:
+		return self.uid, nil
+	case BnameLoginLogLoginTime:
+		return self.loginTime, nil
+	default:
+		return nil, bsonmodel.NoSuchFieldError(name)
+	}
+}
+
+func (self *defaultLoginLog) SetField(name string, value interface{}) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	err := self.partial.CheckField(loginLogSchema, name)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case BnameLoginLogId:
+		v, err := bsonmodel.ParseString(value)
+		if err != nil {
+			return err
+		}
+		self.SetId(v)
+	case BnameLoginLogUid:
+		v, err := bsonmodel.ParseInt(value)
+		if err != nil {
+			return err
+		}
+		self.SetUid(v)
+	case BnameLoginLogLoginTime:
+		v, err := bsonmodel.ParseDateTime(value)
+		if err != nil {
+			return err
+		}
+		self.SetLoginTime(v)
+	default:
+		return bsonmodel.NoSuchFieldError(name)
+	}
+	return nil
+}
+
+func (self *defaultLoginLog) GetPath(xpath bsonmodel.DotNotation) (interface{}, error) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.GetPath(self, xpath)
+}
+
+func (self *defaultLoginLog) SetPath(xpath bsonmodel.DotNotation, value interface{}) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.SetPath(self, xpath, value)
+}
+
+func (self *defaultLoginLog) ToUpdate() bson.M {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	if self.AnyUpdated() {
+		return self.partial.FilterUpdates(self.AppendUpdates(bson.M{}))
+	}
+	return bson.M{}
+}
+
+func (self *defaultLoginLog) ToOrderedDocument() bson.D {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.OrderDocument(self, self.ToDocument())
+}
+
+func (self *defaultLoginLog) ToOrderedUpdate() bson.D {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.OrderUpdates(self, self.ToUpdate())
+}
+
+func (self *defaultLoginLog) LoadPartialDocument(document bson.M, projection bson.M) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	partial, err := bsonmodel.PartialStateOf(loginLogSchema, projection)
+	if err != nil {
+		return err
+	}
+	err = self.LoadDocument(document)
+	if err != nil {
+		return err
+	}
+	self.partial = partial
+	return nil
+}
+
+func (self *defaultLoginLog) FieldLoaded(bname string) bool {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return self.partial.Loaded(bname)
+}
+
+func (self *defaultLoginLog) MarshalToJsonString() (string, error) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return jsoniter.MarshalToString(self)
+}
+
+func (self *defaultLoginLog) MarshalJSON() ([]byte, error) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return jsoniter.Marshal(self)
+}
+
+func (self *defaultLoginLog) UnmarshalJSON(data []byte) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	iter := jsoniter.ConfigDefault.BorrowIterator(data)
+	defer jsoniter.ConfigDefault.ReturnIterator(iter)
+	return self.LoadJsonIterator(iter)
+}
+
+func (self *defaultLoginLog) UnmarshalDataJson(data []byte) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	iter := jsoniter.ConfigDefault.BorrowIterator(data)
+	defer jsoniter.ConfigDefault.ReturnIterator(iter)
+	return self.LoadJsonIterator(iter)
+}
+
+func (self *defaultLoginLog) MarshalBSON() ([]byte, error) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bson.Marshal(self.ToDocument())
+}
+
+func (self *defaultLoginLog) UnmarshalBSON(data []byte) error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return self.LoadRaw(data)
+}
+
+func (self *defaultLoginLog) Observers() *bsonmodel.ChangeObservers {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return &self.observers
+}
+
+func (self *defaultLoginLog) Transaction() *bsonmodel.Transaction {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return &self.transaction
+}
+
+func (self *defaultLoginLog) SetSyncReplaceMarker(enabled bool) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	self.replaceMarker = enabled
+}
+
+func (self *defaultLoginLog) SyncReplaceMarker() bool {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return self.replaceMarker
+}
+
+func (self *defaultLoginLog) Begin() error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return self.transaction.Begin()
+}
+
+func (self *defaultLoginLog) Commit() error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return self.transaction.Commit()
+}
+
+func (self *defaultLoginLog) Rollback() error {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return self.transaction.Rollback()
+}
+
+func (self *defaultLoginLog) Id() string {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	self.partial.Check(BnameLoginLogId)
+	return self.id
+}
+
+func (self *defaultLoginLog) SetId(id string) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	self.partial.Check(BnameLoginLogId)
+	if self.id != id {
+		bsonmodel.SaveState(self)
+		old := self.id
+		self.id = id
+		self.updatedFields.Set(1)
+		bsonmodel.EmitChange(self, "_id", old, id)
+	}
+}
+
+func (self *defaultLoginLog) OnIdChanged(listener func(oldValue string, newValue string)) (remove func(), err error) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.ObserveField(self, BnameLoginLogId, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(string), event.NewValue.(string))
+	})
+}
+
+func (self *defaultLoginLog) Uid() int {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	self.partial.Check(BnameLoginLogUid)
+	return self.uid
+}
+
+func (self *defaultLoginLog) SetUid(uid int) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	self.partial.Check(BnameLoginLogUid)
+	if self.uid != uid {
+		bsonmodel.SaveState(self)
+		old := self.uid
+		self.uid = uid
+		self.updatedFields.Set(2)
+		bsonmodel.EmitChange(self, "uid", old, uid)
+	}
+}
+
+func (self *defaultLoginLog) OnUidChanged(listener func(oldValue int, newValue int)) (remove func(), err error) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.ObserveField(self, BnameLoginLogUid, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(int), event.NewValue.(int))
+	})
+}
+
+func (self *defaultLoginLog) LoginTime() time.Time {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	self.partial.Check(BnameLoginLogLoginTime)
+	return self.loginTime
+}
+
+func (self *defaultLoginLog) SetLoginTime(loginTime time.Time) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	self.partial.Check(BnameLoginLogLoginTime)
+	if self.loginTime != loginTime {
+		bsonmodel.SaveState(self)
+		old := self.loginTime
+		self.loginTime = loginTime
+		self.updatedFields.Set(3)
+		bsonmodel.EmitChange(self, "lt", old, loginTime)
+	}
+}
+
+func (self *defaultLoginLog) OnLoginTimeChanged(listener func(oldValue time.Time, newValue time.Time)) (remove func(), err error) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	return bsonmodel.ObserveField(self, BnameLoginLogLoginTime, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(time.Time), event.NewValue.(time.Time))
+	})
+}
+
+func (self *defaultLoginLog) Clone() LoginLog {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	clone := NewLoginLog()
+	clone.CopyFrom(self)
+	clone.Reset()
+	return clone
+}
+
+func (self *defaultLoginLog) CopyFrom(other LoginLog) {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	self.partial = other.(*defaultLoginLog).partial
+	if other.FieldLoaded(BnameLoginLogId) {
+		self.SetId(other.Id())
+	}
+	if other.FieldLoaded(BnameLoginLogUid) {
+		self.SetUid(other.Uid())
+	}
+	if other.FieldLoaded(BnameLoginLogLoginTime) {
+		self.SetLoginTime(other.LoginTime())
+	}
+}
+
+func NewLoginLog() LoginLog {
+	self := &defaultLoginLog{updatedFields: &bitset.BitSet{}}
+	return self
+}
+
+func (self *defaultLoginLog) Release() {
+	bsonmodel.CheckReleased(self.released, "LoginLog")
+	self.observers.Reset()
+	self.transaction = bsonmodel.Transaction{}
+	self.partial.Clear()
+	self.replaceMarker = false
+	self.Reset()
+	self.clear()
+	self.released = true
+	if !bsonmodel.Debug {
+		loginLogPool.Put(self)
+	}
+}
+
+var loginLogPool = sync.Pool{New: func() interface{} { return NewLoginLog() }}
+
+func AcquireLoginLog() LoginLog {
+	self := loginLogPool.Get().(*defaultLoginLog)
+	self.released = false
+	return self
+}
+
+func LoadLoginLogFromDocument(m bson.M) (loginLog LoginLog, err error) {
+	loginLog = NewLoginLog()
+	err = loginLog.LoadDocument(m)
+	return
+}
+
+func LoadLoginLogFromJsonIterator(iter *jsoniter.Iterator) (loginLog LoginLog, err error) {
+	loginLog = NewLoginLog()
+	err = loginLog.LoadJsonIterator(iter)
+	return
+}
+
+func LoadLoginLogFromRaw(raw bson.Raw) (loginLog LoginLog, err error) {
+	loginLog = NewLoginLog()
+	err = loginLog.LoadRaw(raw)
+	return
+}
+
+func LoadLoginLogFromJsoniter(any jsoniter.Any) (loginLog LoginLog, err error) {
+	loginLog = NewLoginLog()
+	err = loginLog.LoadJsoniter(any)
+	return
+}
+
+func LoadLoginLogPartially(m bson.M, projection bson.M) (loginLog LoginLog, err error) {
+	loginLog = NewLoginLog()
+	err = loginLog.LoadPartialDocument(m, projection)
+	return
+}
+
+type LoginLogProjectionBuilder struct {
+	projection bson.M
+}
+
+func LoginLogProjection() *LoginLogProjectionBuilder {
+	return &LoginLogProjectionBuilder{projection: bson.M{}}
+}
+
+func (builder *LoginLogProjectionBuilder) Id() *LoginLogProjectionBuilder {
+	builder.projection[BnameLoginLogId] = 1
+	return builder
+}
+
+func (builder *LoginLogProjectionBuilder) Uid() *LoginLogProjectionBuilder {
+	builder.projection[BnameLoginLogUid] = 1
+	return builder
+}
+
+func (builder *LoginLogProjectionBuilder) LoginTime() *LoginLogProjectionBuilder {
+	builder.projection[BnameLoginLogLoginTime] = 1
+	return builder
+}
+
+func (builder *LoginLogProjectionBuilder) Build() bson.M {
+	projection := make(bson.M, len(builder.projection))
+	for k, v := range builder.projection {
+		projection[k] = v
+	}
+	return projection
+}
+
+type LoginLogPaths struct {
+	base bsonmodel.DotNotation
+}
+
+var LoginLogPath = LoginLogPaths{bsonmodel.RootPath()}
+
+func (paths LoginLogPaths) Path() bsonmodel.DotNotation {
+	return paths.base
+}
+
+func (paths LoginLogPaths) Id() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameLoginLogId)
+}
+
+func (paths LoginLogPaths) Uid() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameLoginLogUid)
+}
+
+func (paths LoginLogPaths) LoginTime() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameLoginLogLoginTime)
+}
+
+func LoginLogIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "uid", Value: 1}, {Key: "lt", Value: -1}},
+			Options: options.Index(),
+		},
+		{
+			Keys:    bson.D{{Key: "lt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(2592000),
+		},
+	}
+}
+
+type loginLogEncoder struct{}
+
+func (codec *loginLogEncoder) IsEmpty(ptr unsafe.Pointer) bool {
+	return false
+}
+
+func (codec *loginLogEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	p := ((*defaultLoginLog)(ptr))
+	object := bsonmodel.BeginJsonObject(stream)
+	if p.partial.Loaded(BnameLoginLogId) {
+		object.Field("id")
+		stream.WriteString(p.id)
+	}
+	if p.partial.Loaded(BnameLoginLogUid) {
+		object.Field("uid")
+		stream.WriteInt(p.uid)
+	}
+	if p.partial.Loaded(BnameLoginLogLoginTime) {
+		object.Field("loginTime")
+		stream.WriteInt64(p.loginTime.Unix())
+	}
+	object.End()
+}
+
+func init() {
+	jsoniter.RegisterTypeEncoder("example.defaultLoginLog", &loginLogEncoder{})
+}
+
@@ -1,6 +1,8 @@
 package example
 
 import (
+	"io"
+	"sync"
 	"time"
 	"unsafe"
 
@@ -9,12 +11,15 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Player interface {
 	bsonmodel.RootModel
 	Uid() int
 	SetUid(uid int)
+	OnUidChanged(listener func(oldValue int, newValue int)) (remove func(), err error)
 	Wallet() Wallet
 	Equipments() bsonmodel.StringObjectMapModel
 	Equipment(id string) Equipment
@@ -23,10 +28,15 @@ type Player interface {
 	UpdateVersion() int
 	SetUpdateVersion(updateVersion int)
 	IncreaseUpdateVersion() int
+	OnUpdateVersionChanged(listener func(oldValue int, newValue int)) (remove func(), err error)
 	CreateTime() time.Time
 	SetCreateTime(createTime time.Time)
+	OnCreateTimeChanged(listener func(oldValue time.Time, newValue time.Time)) (remove func(), err error)
 	UpdateTime() time.Time
 	SetUpdateTime(updateTime time.Time)
+	OnUpdateTimeChanged(listener func(oldValue time.Time, newValue time.Time)) (remove func(), err error)
+	Clone() Player
+	CopyFrom(other Player)
 }
 
 const (
@@ -40,8 +50,32 @@ const (
 	BnamePlayerUpdateTime    = "_ut"
 )
 
+var playerSchema = &bsonmodel.ModelSchema{
+	Name: "Player",
+	Type: bsonmodel.ModelTypeRoot,
+	Fields: []*bsonmodel.FieldSchema{
+		{Name: "uid", Bname: "_id", Type: bsonmodel.FieldTypeInt, Required: true},
+		{Name: "wallet", Bname: "wlt", Type: bsonmodel.FieldTypeObject, Model: walletSchema},
+		{Name: "equipments", Bname: "eqm", Type: bsonmodel.FieldTypeMap, KeyType: "string", ValueType: "Equipment", Model: equipmentSchema, Lazy: true},
+		{Name: "items", Bname: "itm", Type: bsonmodel.FieldTypeSimpleMap, KeyType: "int", ValueType: "int", Lazy: true},
+		{Name: "cash", Bname: "cs", Type: bsonmodel.FieldTypeObject, Model: cashInfoSchema},
+		{Name: "updateVersion", Bname: "_uv", Type: bsonmodel.FieldTypeInt, JsonIgnore: true},
+		{Name: "createTime", Bname: "_ct", Type: bsonmodel.FieldTypeDateTime, JsonIgnore: true},
+		{Name: "updateTime", Bname: "_ut", Type: bsonmodel.FieldTypeDateTime, JsonIgnore: true},
+	},
+}
+
+func PlayerSchema() *bsonmodel.ModelSchema {
+	return playerSchema
+}
+
 type defaultPlayer struct {
 	updatedFields *bitset.BitSet
+	observers     bsonmodel.ChangeObservers
+	transaction   bsonmodel.Transaction
+	released      bool
+	partial       bsonmodel.PartialState
+	replaceMarker bool
 	uid           int
 	wallet        Wallet
 	equipments    bsonmodel.StringObjectMapModel
@@ -53,10 +87,12 @@ type defaultPlayer struct {
 }
 
 func (self *defaultPlayer) ToBson() interface{} {
+	bsonmodel.CheckReleased(self.released, "Player")
 	return self.ToDocument()
 }
 
 func (self *defaultPlayer) ToData() interface{} {
+	bsonmodel.CheckReleased(self.released, "Player")
 	data := make(map[string]interface{})
 	data["_id"] = self.uid
 	data["wlt"] = self.wallet.ToData()
@@ -66,10 +102,14 @@ func (self *defaultPlayer) ToData() interface{} {
 	data["_uv"] = self.updateVersion
 	data["_ct"] = self.createTime.UnixMilli()
 	data["_ut"] = self.updateTime.UnixMilli()
+	self.partial.FilterFields(data)
 	return data
 }
 
 func (self *defaultPlayer) LoadJsoniter(any jsoniter.Any) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	bsonmodel.SaveState(self)
+	self.partial.Clear()
 	if any.ValueType() != jsoniter.ObjectValue {
 		self.Reset()
 		return nil
@@ -130,7 +170,63 @@ func (self *defaultPlayer) LoadJsoniter(any jsoniter.Any) error {
 	return nil
 }
 
+func (self *defaultPlayer) LoadJsonIterator(iter *jsoniter.Iterator) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	bsonmodel.SaveState(self)
+	self.partial.Clear()
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		self.Reset()
+		return bsonmodel.IterError(iter)
+	}
+	self.uid = 0
+	self.updateVersion = 0
+	self.createTime = time.Time{}
+	self.updateTime = time.Time{}
+	equipmentsLoaded := false
+	itemsLoaded := false
+	var err error
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, field string) bool {
+		switch field {
+		case BnamePlayerUid, "uid":
+			self.uid, err = bsonmodel.IterIntValue(iter, 0)
+		case BnamePlayerWallet, "wallet":
+			err = self.wallet.LoadJsonIterator(iter)
+		case BnamePlayerEquipments, "equipments":
+			equipmentsLoaded = true
+			err = self.equipments.LoadJsonIterator(iter)
+		case BnamePlayerItems, "items":
+			itemsLoaded = true
+			err = self.items.LoadJsonIterator(iter)
+		case BnamePlayerCash, "cash":
+			err = self.cash.LoadJsonIterator(iter)
+		case BnamePlayerUpdateVersion:
+			self.updateVersion, err = bsonmodel.IterIntValue(iter, 0)
+		case BnamePlayerCreateTime:
+			self.createTime, err = bsonmodel.IterDateTimeValue(iter)
+		case BnamePlayerUpdateTime:
+			self.updateTime, err = bsonmodel.IterDateTimeValue(iter)
+		default:
+			iter.Skip()
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	if !equipmentsLoaded {
+		self.equipments.Clear()
+	}
+	if !itemsLoaded {
+		self.items.Clear()
+	}
+	self.Reset()
+	return bsonmodel.IterError(iter)
+}
+
 func (self *defaultPlayer) Reset() {
+	bsonmodel.CheckReleased(self.released, "Player")
+	bsonmodel.SaveState(self)
 	self.wallet.Reset()
 	self.equipments.Reset()
 	self.items.Reset()
@@ -138,11 +234,24 @@ func (self *defaultPlayer) Reset() {
 	self.updatedFields.ClearAll()
 }
 
+func (self *defaultPlayer) clear() {
+	self.uid = 0
+	self.wallet.(*defaultWallet).clear()
+	self.equipments.Discard()
+	self.items.Discard()
+	self.cash.(*defaultCashInfo).clear()
+	self.updateVersion = 0
+	self.createTime = time.Time{}
+	self.updateTime = time.Time{}
+}
+
 func (self *defaultPlayer) AnyUpdated() bool {
+	bsonmodel.CheckReleased(self.released, "Player")
 	return self.updatedFields.Any() || self.wallet.AnyUpdated() || self.equipments.AnyUpdated() || self.items.AnyUpdated() || self.cash.AnyUpdated()
 }
 
 func (self *defaultPlayer) AnyDeleted() bool {
+	bsonmodel.CheckReleased(self.released, "Player")
 	return self.DeletedSize() > 0
 }
 
@@ -155,6 +264,7 @@ func (self *defaultPlayer) XPath() bsonmodel.DotNotation {
 }
 
 func (self *defaultPlayer) AppendUpdates(updates bson.M) bson.M {
+	bsonmodel.CheckReleased(self.released, "Player")
 	dset := bsonmodel.FixedEmbedded(updates, "$set")
 	updatedFields := self.updatedFields
 	if updatedFields.Test(1) {
@@ -185,6 +295,7 @@ func (self *defaultPlayer) AppendUpdates(updates bson.M) bson.M {
 }
 
 func (self *defaultPlayer) ToDocument() bson.M {
+	bsonmodel.CheckReleased(self.released, "Player")
 	doc := bson.M{}
 	doc["_id"] = self.uid
 	doc["wlt"] = self.wallet.ToBson()
@@ -194,10 +305,14 @@ func (self *defaultPlayer) ToDocument() bson.M {
 	doc["_uv"] = self.updateVersion
 	doc["_ct"] = primitive.NewDateTimeFromTime(self.createTime)
 	doc["_ut"] = primitive.NewDateTimeFromTime(self.updateTime)
+	self.partial.FilterFields(doc)
 	return doc
 }
 
 func (self *defaultPlayer) LoadDocument(document bson.M) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	bsonmodel.SaveState(self)
+	self.partial.Clear()
 	uid, err := bsonmodel.IntValue(document, "_id", 0)
 	if err != nil {
 		return err
@@ -218,10 +333,7 @@ func (self *defaultPlayer) LoadDocument(document bson.M) error {
 		return err
 	}
 	if equipments != nil {
-		err = self.equipments.LoadDocument(equipments)
-		if err != nil {
-			return err
-		}
+		self.equipments.LoadDocumentLazily(equipments)
 	} else {
 		self.equipments.Clear()
 	}
@@ -230,10 +342,7 @@ func (self *defaultPlayer) LoadDocument(document bson.M) error {
 		return err
 	}
 	if items != nil {
-		err = self.items.LoadDocument(items)
-		if err != nil {
-			return err
-		}
+		self.items.LoadDocumentLazily(items)
 	} else {
 		self.items.Clear()
 	}
@@ -266,7 +375,93 @@ func (self *defaultPlayer) LoadDocument(document bson.M) error {
 	return nil
 }
 
+func (self *defaultPlayer) LoadRaw(raw bson.Raw) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	bsonmodel.SaveState(self)
+	self.partial.Clear()
+	uid, err := bsonmodel.RawIntValue(raw, "_id", 0)
+	if err != nil {
+		return err
+	}
+	self.uid = uid
+	wallet, err := bsonmodel.RawEmbeddedValue(raw, "wlt")
+	if err != nil {
+		return err
+	}
+	if wallet != nil {
+		err = self.wallet.LoadRaw(wallet)
+		if err != nil {
+			return err
+		}
+	}
+	equipments, err := bsonmodel.RawEmbeddedValue(raw, "eqm")
+	if err != nil {
+		return err
+	}
+	if equipments != nil {
+		self.equipments.LoadRawLazily(equipments)
+	} else {
+		self.equipments.Clear()
+	}
+	items, err := bsonmodel.RawEmbeddedValue(raw, "itm")
+	if err != nil {
+		return err
+	}
+	if items != nil {
+		self.items.LoadRawLazily(items)
+	} else {
+		self.items.Clear()
+	}
+	cash, err := bsonmodel.RawEmbeddedValue(raw, "cs")
+	if err != nil {
+		return err
+	}
+	if cash != nil {
+		err = self.cash.LoadRaw(cash)
+		if err != nil {
+			return err
+		}
+	}
+	updateVersion, err := bsonmodel.RawIntValue(raw, "_uv", 0)
+	if err != nil {
+		return err
+	}
+	self.updateVersion = updateVersion
+	createTime, err := bsonmodel.RawDateTimeValue(raw, "_ct")
+	if err != nil {
+		return err
+	}
+	self.createTime = createTime
+	updateTime, err := bsonmodel.RawDateTimeValue(raw, "_ut")
+	if err != nil {
+		return err
+	}
+	self.updateTime = updateTime
+	self.Reset()
+	return nil
+}
+
+func (self *defaultPlayer) LoadDocumentTracked(document bson.M) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	loaded := NewPlayer()
+	err := loaded.LoadDocument(document)
+	if err != nil {
+		return err
+	}
+	err = loaded.Equipments().Load()
+	if err != nil {
+		return err
+	}
+	err = loaded.Items().Load()
+	if err != nil {
+		return err
+	}
+	self.CopyFrom(loaded)
+	return nil
+}
+
 func (self *defaultPlayer) DeletedSize() int {
+	bsonmodel.CheckReleased(self.released, "Player")
 	n := 0
 	if self.wallet.AnyDeleted() {
 		n += 1
@@ -291,7 +486,24 @@ func (self *defaultPlayer) SetFullyUpdate(fullyUpdate bool) {
 	// no effect
 }
 
+func (self *defaultPlayer) Snapshot() func() {
+	bsonmodel.CheckReleased(self.released, "Player")
+	updatedFields := self.updatedFields.Clone()
+	uid := self.uid
+	updateVersion := self.updateVersion
+	createTime := self.createTime
+	updateTime := self.updateTime
+	return func() {
+		self.updatedFields = updatedFields
+		self.uid = uid
+		self.updateVersion = updateVersion
+		self.createTime = createTime
+		self.updateTime = updateTime
+	}
+}
+
 func (self *defaultPlayer) ToSync() interface{} {
+	bsonmodel.CheckReleased(self.released, "Player")
 	sync := make(map[string]interface{})
 	updatedFields := self.updatedFields
 	if updatedFields.Test(1) {
@@ -313,6 +525,7 @@ func (self *defaultPlayer) ToSync() interface{} {
 }
 
 func (self *defaultPlayer) ToDelete() interface{} {
+	bsonmodel.CheckReleased(self.released, "Player")
 	delete := make(map[string]interface{})
 	if self.wallet.AnyDeleted() {
 		delete["wallet"] = self.wallet.ToDelete()
@@ -330,52 +543,336 @@ func (self *defaultPlayer) ToDelete() interface{} {
 }
 
 func (self *defaultPlayer) ToDataJson() (string, error) {
-	return jsoniter.MarshalToString(self.ToData())
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.DataJsonOf(self)
 }
 
 func (self *defaultPlayer) ToSyncJson() (string, error) {
-	return jsoniter.MarshalToString(self.ToSync())
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.SyncJsonOf(self)
 }
 
 func (self *defaultPlayer) ToDeleteJson() (string, error) {
+	bsonmodel.CheckReleased(self.released, "Player")
 	return jsoniter.MarshalToString(self.ToDelete())
 }
 
+func (self *defaultPlayer) EncodeData(stream *jsoniter.Stream) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	object := bsonmodel.BeginJsonObject(stream)
+	if self.partial.Loaded(BnamePlayerUid) {
+		object.Field("_id")
+		stream.WriteInt(self.uid)
+	}
+	if self.partial.Loaded(BnamePlayerWallet) {
+		object.Field("wlt")
+		self.wallet.EncodeData(stream)
+	}
+	if self.partial.Loaded(BnamePlayerEquipments) {
+		object.Field("eqm")
+		self.equipments.EncodeData(stream)
+	}
+	if self.partial.Loaded(BnamePlayerItems) {
+		object.Field("itm")
+		self.items.EncodeData(stream)
+	}
+	if self.partial.Loaded(BnamePlayerCash) {
+		object.Field("cs")
+		self.cash.EncodeData(stream)
+	}
+	if self.partial.Loaded(BnamePlayerUpdateVersion) {
+		object.Field("_uv")
+		stream.WriteInt(self.updateVersion)
+	}
+	if self.partial.Loaded(BnamePlayerCreateTime) {
+		object.Field("_ct")
+		stream.WriteInt64(self.createTime.UnixMilli())
+	}
+	if self.partial.Loaded(BnamePlayerUpdateTime) {
+		object.Field("_ut")
+		stream.WriteInt64(self.updateTime.UnixMilli())
+	}
+	object.End()
+}
+
+func (self *defaultPlayer) EncodeSync(stream *jsoniter.Stream) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	object := bsonmodel.BeginJsonObject(stream)
+	if self.updatedFields.Test(1) {
+		object.Field("uid")
+		stream.WriteInt(self.uid)
+	}
+	if self.wallet.AnyUpdated() {
+		object.Field("wallet")
+		self.wallet.EncodeSync(stream)
+	}
+	if self.equipments.AnyUpdated() {
+		object.Field("equipments")
+		self.equipments.EncodeSync(stream)
+	}
+	if self.items.AnyUpdated() {
+		object.Field("items")
+		self.items.EncodeSync(stream)
+	}
+	if self.cash.AnyUpdated() {
+		object.Field("cash")
+		self.cash.EncodeSync(stream)
+	}
+	object.End()
+}
+
+func (self *defaultPlayer) WriteDataJson(w io.Writer) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.WriteDataJson(w, self)
+}
+
+func (self *defaultPlayer) WriteSyncJson(w io.Writer) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.WriteSyncJson(w, self)
+}
+
+func (self *defaultPlayer) ToMergePatch() interface{} {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.MergePatchOf(self)
+}
+
+func (self *defaultPlayer) ToJsonPatch() []bsonmodel.JsonPatchOperation {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.JsonPatchOf(self)
+}
+
+func (self *defaultPlayer) Changes() []bsonmodel.Change {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.ChangesOf(self)
+}
+
+func (self *defaultPlayer) Schema() *bsonmodel.ModelSchema {
+	return playerSchema
+}
+
+func (self *defaultPlayer) GetField(name string) (interface{}, error) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	err := self.partial.CheckField(playerSchema, name)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case BnamePlayerUid:
+		return self.uid, nil
+	case BnamePlayerWallet:
+		return self.wallet, nil
+	case BnamePlayerEquipments:
+		return self.equipments, nil
+	case BnamePlayerItems:
+		return self.items, nil
+	case BnamePlayerCash:
+		return self.cash, nil
+	case BnamePlayerUpdateVersion:
+		return self.updateVersion, nil
+	case BnamePlayerCreateTime:
+		return self.createTime, nil
+	case BnamePlayerUpdateTime:
+		return self.updateTime, nil
+	default:
+		return nil, bsonmodel.NoSuchFieldError(name)
+	}
+}
+
+func (self *defaultPlayer) SetField(name string, value interface{}) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	err := self.partial.CheckField(playerSchema, name)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case BnamePlayerUid:
+		v, err := bsonmodel.ParseInt(value)
+		if err != nil {
+			return err
+		}
+		self.SetUid(v)
+	case BnamePlayerUpdateVersion:
+		v, err := bsonmodel.ParseInt(value)
+		if err != nil {
+			return err
+		}
+		self.SetUpdateVersion(v)
+	case BnamePlayerCreateTime:
+		v, err := bsonmodel.ParseDateTime(value)
+		if err != nil {
+			return err
+		}
+		self.SetCreateTime(v)
+	case BnamePlayerUpdateTime:
+		v, err := bsonmodel.ParseDateTime(value)
+		if err != nil {
+			return err
+		}
+		self.SetUpdateTime(v)
+	case BnamePlayerWallet, BnamePlayerEquipments, BnamePlayerItems, BnamePlayerCash:
+		return bsonmodel.UnsettableFieldError(name)
+	default:
+		return bsonmodel.NoSuchFieldError(name)
+	}
+	return nil
+}
+
+func (self *defaultPlayer) GetPath(xpath bsonmodel.DotNotation) (interface{}, error) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.GetPath(self, xpath)
+}
+
+func (self *defaultPlayer) SetPath(xpath bsonmodel.DotNotation, value interface{}) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.SetPath(self, xpath, value)
+}
+
 func (self *defaultPlayer) ToUpdate() bson.M {
+	bsonmodel.CheckReleased(self.released, "Player")
 	if self.AnyUpdated() {
-		return self.AppendUpdates(bson.M{})
+		return self.partial.FilterUpdates(self.AppendUpdates(bson.M{}))
 	}
 	return bson.M{}
 }
 
+func (self *defaultPlayer) ToOrderedDocument() bson.D {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.OrderDocument(self, self.ToDocument())
+}
+
+func (self *defaultPlayer) ToOrderedUpdate() bson.D {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.OrderUpdates(self, self.ToUpdate())
+}
+
+func (self *defaultPlayer) LoadPartialDocument(document bson.M, projection bson.M) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	partial, err := bsonmodel.PartialStateOf(playerSchema, projection)
+	if err != nil {
+		return err
+	}
+	err = self.LoadDocument(document)
+	if err != nil {
+		return err
+	}
+	self.partial = partial
+	return nil
+}
+
+func (self *defaultPlayer) FieldLoaded(bname string) bool {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return self.partial.Loaded(bname)
+}
+
 func (self *defaultPlayer) MarshalToJsonString() (string, error) {
+	bsonmodel.CheckReleased(self.released, "Player")
 	return jsoniter.MarshalToString(self)
 }
 
 func (self *defaultPlayer) MarshalJSON() ([]byte, error) {
+	bsonmodel.CheckReleased(self.released, "Player")
 	return jsoniter.Marshal(self)
 }
 
+func (self *defaultPlayer) UnmarshalJSON(data []byte) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	iter := jsoniter.ConfigDefault.BorrowIterator(data)
+	defer jsoniter.ConfigDefault.ReturnIterator(iter)
+	return self.LoadJsonIterator(iter)
+}
+
+func (self *defaultPlayer) UnmarshalDataJson(data []byte) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	iter := jsoniter.ConfigDefault.BorrowIterator(data)
+	defer jsoniter.ConfigDefault.ReturnIterator(iter)
+	return self.LoadJsonIterator(iter)
+}
+
+func (self *defaultPlayer) MarshalBSON() ([]byte, error) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bson.Marshal(self.ToDocument())
+}
+
+func (self *defaultPlayer) UnmarshalBSON(data []byte) error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return self.LoadRaw(data)
+}
+
+func (self *defaultPlayer) Observers() *bsonmodel.ChangeObservers {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return &self.observers
+}
+
+func (self *defaultPlayer) Transaction() *bsonmodel.Transaction {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return &self.transaction
+}
+
+func (self *defaultPlayer) SetSyncReplaceMarker(enabled bool) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.replaceMarker = enabled
+}
+
+func (self *defaultPlayer) SyncReplaceMarker() bool {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return self.replaceMarker
+}
+
+func (self *defaultPlayer) Begin() error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return self.transaction.Begin()
+}
+
+func (self *defaultPlayer) Commit() error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return self.transaction.Commit()
+}
+
+func (self *defaultPlayer) Rollback() error {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return self.transaction.Rollback()
+}
+
 func (self *defaultPlayer) Uid() int {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerUid)
 	return self.uid
 }
 
 func (self *defaultPlayer) SetUid(uid int) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerUid)
 	if self.uid != uid {
+		bsonmodel.SaveState(self)
+		old := self.uid
 		self.uid = uid
 		self.updatedFields.Set(1)
+		bsonmodel.EmitChange(self, "_id", old, uid)
 	}
 }
 
+func (self *defaultPlayer) OnUidChanged(listener func(oldValue int, newValue int)) (remove func(), err error) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.ObserveField(self, BnamePlayerUid, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(int), event.NewValue.(int))
+	})
+}
+
 func (self *defaultPlayer) Wallet() Wallet {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerWallet)
 	return self.wallet
 }
 
 func (self *defaultPlayer) Equipments() bsonmodel.StringObjectMapModel {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerEquipments)
 	return self.equipments
 }
 
 func (self *defaultPlayer) Equipment(id string) Equipment {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerEquipments)
 	value := self.equipments.Get(id)
 	if value == nil {
 		return nil
@@ -384,50 +881,150 @@ func (self *defaultPlayer) Equipment(id string) Equipment {
 }
 
 func (self *defaultPlayer) Items() bsonmodel.IntSimpleMapModel {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerItems)
 	return self.items
 }
 
 func (self *defaultPlayer) Cash() CashInfo {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerCash)
 	return self.cash
 }
 
 func (self *defaultPlayer) UpdateVersion() int {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerUpdateVersion)
 	return self.updateVersion
 }
 
 func (self *defaultPlayer) SetUpdateVersion(updateVersion int) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerUpdateVersion)
 	if self.updateVersion != updateVersion {
+		bsonmodel.SaveState(self)
+		old := self.updateVersion
 		self.updateVersion = updateVersion
 		self.updatedFields.Set(6)
+		bsonmodel.EmitChange(self, "_uv", old, updateVersion)
 	}
 }
 
 func (self *defaultPlayer) IncreaseUpdateVersion() int {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerUpdateVersion)
+	bsonmodel.SaveState(self)
 	updateVersion := self.updateVersion + 1
 	self.updateVersion = updateVersion
 	self.updatedFields.Set(6)
+	bsonmodel.EmitChange(self, "_uv", updateVersion-1, updateVersion)
 	return updateVersion
 }
 
+func (self *defaultPlayer) OnUpdateVersionChanged(listener func(oldValue int, newValue int)) (remove func(), err error) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.ObserveField(self, BnamePlayerUpdateVersion, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(int), event.NewValue.(int))
+	})
+}
+
 func (self *defaultPlayer) CreateTime() time.Time {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerCreateTime)
 	return self.createTime
 }
 
 func (self *defaultPlayer) SetCreateTime(createTime time.Time) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerCreateTime)
 	if self.createTime != createTime {
+		bsonmodel.SaveState(self)
+		old := self.createTime
 		self.createTime = createTime
 		self.updatedFields.Set(7)
+		bsonmodel.EmitChange(self, "_ct", old, createTime)
 	}
 }
 
+func (self *defaultPlayer) OnCreateTimeChanged(listener func(oldValue time.Time, newValue time.Time)) (remove func(), err error) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.ObserveField(self, BnamePlayerCreateTime, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(time.Time), event.NewValue.(time.Time))
+	})
+}
+
 func (self *defaultPlayer) UpdateTime() time.Time {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerUpdateTime)
 	return self.updateTime
 }
 
 func (self *defaultPlayer) SetUpdateTime(updateTime time.Time) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial.Check(BnamePlayerUpdateTime)
 	if self.updateTime != updateTime {
+		bsonmodel.SaveState(self)
+		old := self.updateTime
 		self.updateTime = updateTime
 		self.updatedFields.Set(8)
+		bsonmodel.EmitChange(self, "_ut", old, updateTime)
+	}
+}
+
+func (self *defaultPlayer) OnUpdateTimeChanged(listener func(oldValue time.Time, newValue time.Time)) (remove func(), err error) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	return bsonmodel.ObserveField(self, BnamePlayerUpdateTime, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(time.Time), event.NewValue.(time.Time))
+	})
+}
+
+func (self *defaultPlayer) Clone() Player {
+	bsonmodel.CheckReleased(self.released, "Player")
+	clone := NewPlayer()
+	clone.CopyFrom(self)
+	clone.Reset()
+	return clone
+}
+
+func (self *defaultPlayer) CopyFrom(other Player) {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.partial = other.(*defaultPlayer).partial
+	if other.FieldLoaded(BnamePlayerUid) {
+		self.SetUid(other.Uid())
+	}
+	if other.FieldLoaded(BnamePlayerWallet) {
+		self.wallet.CopyFrom(other.Wallet())
+	}
+	if other.FieldLoaded(BnamePlayerEquipments) {
+		otherEquipments := other.Equipments()
+		for _, key := range self.equipments.Keys() {
+			if otherEquipments.Get(key) == nil {
+				self.equipments.Remove(key)
+			}
+		}
+		for _, key := range otherEquipments.Keys() {
+			value := otherEquipments.Get(key).(Equipment)
+			if current := self.equipments.Get(key); current != nil {
+				current.(Equipment).CopyFrom(value)
+			} else {
+				self.equipments.Put(key, value.Clone())
+			}
+		}
+	}
+	if other.FieldLoaded(BnamePlayerItems) {
+		self.items.CopyFrom(other.Items())
+	}
+	if other.FieldLoaded(BnamePlayerCash) {
+		self.cash.CopyFrom(other.Cash())
+	}
+	if other.FieldLoaded(BnamePlayerUpdateVersion) {
+		self.SetUpdateVersion(other.UpdateVersion())
+	}
+	if other.FieldLoaded(BnamePlayerCreateTime) {
+		self.SetCreateTime(other.CreateTime())
+	}
+	if other.FieldLoaded(BnamePlayerUpdateTime) {
+		self.SetUpdateTime(other.UpdateTime())
 	}
 }
 
@@ -440,18 +1037,169 @@ func NewPlayer() Player {
 	return self
 }
 
+func (self *defaultPlayer) Release() {
+	bsonmodel.CheckReleased(self.released, "Player")
+	self.observers.Reset()
+	self.transaction = bsonmodel.Transaction{}
+	self.partial.Clear()
+	self.replaceMarker = false
+	self.Reset()
+	self.clear()
+	self.released = true
+	if !bsonmodel.Debug {
+		playerPool.Put(self)
+	}
+}
+
+var playerPool = sync.Pool{New: func() interface{} { return NewPlayer() }}
+
+func AcquirePlayer() Player {
+	self := playerPool.Get().(*defaultPlayer)
+	self.released = false
+	return self
+}
+
 func LoadPlayerFromDocument(m bson.M) (player Player, err error) {
 	player = NewPlayer()
 	err = player.LoadDocument(m)
 	return
 }
 
+func LoadPlayerFromJsonIterator(iter *jsoniter.Iterator) (player Player, err error) {
+	player = NewPlayer()
+	err = player.LoadJsonIterator(iter)
+	return
+}
+
+func LoadPlayerFromRaw(raw bson.Raw) (player Player, err error) {
+	player = NewPlayer()
+	err = player.LoadRaw(raw)
+	return
+}
+
 func LoadPlayerFromJsoniter(any jsoniter.Any) (player Player, err error) {
 	player = NewPlayer()
 	err = player.LoadJsoniter(any)
 	return
 }
 
+func LoadPlayerPartially(m bson.M, projection bson.M) (player Player, err error) {
+	player = NewPlayer()
+	err = player.LoadPartialDocument(m, projection)
+	return
+}
+
+type PlayerProjectionBuilder struct {
+	projection bson.M
+}
+
+func PlayerProjection() *PlayerProjectionBuilder {
+	return &PlayerProjectionBuilder{projection: bson.M{}}
+}
+
+func (builder *PlayerProjectionBuilder) Uid() *PlayerProjectionBuilder {
+	builder.projection[BnamePlayerUid] = 1
+	return builder
+}
+
+func (builder *PlayerProjectionBuilder) Wallet() *PlayerProjectionBuilder {
+	builder.projection[BnamePlayerWallet] = 1
+	return builder
+}
+
+func (builder *PlayerProjectionBuilder) Equipments() *PlayerProjectionBuilder {
+	builder.projection[BnamePlayerEquipments] = 1
+	return builder
+}
+
+func (builder *PlayerProjectionBuilder) Items() *PlayerProjectionBuilder {
+	builder.projection[BnamePlayerItems] = 1
+	return builder
+}
+
+func (builder *PlayerProjectionBuilder) Cash() *PlayerProjectionBuilder {
+	builder.projection[BnamePlayerCash] = 1
+	return builder
+}
+
+func (builder *PlayerProjectionBuilder) UpdateVersion() *PlayerProjectionBuilder {
+	builder.projection[BnamePlayerUpdateVersion] = 1
+	return builder
+}
+
+func (builder *PlayerProjectionBuilder) CreateTime() *PlayerProjectionBuilder {
+	builder.projection[BnamePlayerCreateTime] = 1
+	return builder
+}
+
+func (builder *PlayerProjectionBuilder) UpdateTime() *PlayerProjectionBuilder {
+	builder.projection[BnamePlayerUpdateTime] = 1
+	return builder
+}
+
+func (builder *PlayerProjectionBuilder) Build() bson.M {
+	projection := make(bson.M, len(builder.projection))
+	for k, v := range builder.projection {
+		projection[k] = v
+	}
+	return projection
+}
+
+type PlayerPaths struct {
+	base bsonmodel.DotNotation
+}
+
+var PlayerPath = PlayerPaths{bsonmodel.RootPath()}
+
+func (paths PlayerPaths) Path() bsonmodel.DotNotation {
+	return paths.base
+}
+
+func (paths PlayerPaths) Uid() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnamePlayerUid)
+}
+
+func (paths PlayerPaths) Wallet() WalletPaths {
+	return WalletPaths{paths.base.Resolve(BnamePlayerWallet)}
+}
+
+func (paths PlayerPaths) Equipments() EquipmentMapPaths {
+	return EquipmentMapPaths{paths.base.Resolve(BnamePlayerEquipments)}
+}
+
+func (paths PlayerPaths) Items() bsonmodel.IntMapPath {
+	return bsonmodel.NewIntMapPath(paths.base.Resolve(BnamePlayerItems))
+}
+
+func (paths PlayerPaths) Cash() CashInfoPaths {
+	return CashInfoPaths{paths.base.Resolve(BnamePlayerCash)}
+}
+
+func (paths PlayerPaths) UpdateVersion() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnamePlayerUpdateVersion)
+}
+
+func (paths PlayerPaths) CreateTime() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnamePlayerCreateTime)
+}
+
+func (paths PlayerPaths) UpdateTime() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnamePlayerUpdateTime)
+}
+
+func PlayerIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "wlt.ct", Value: -1}, {Key: "_id", Value: 1}},
+			Options: options.Index(),
+		},
+		{
+			Keys:    bson.D{{Key: "cs.ois", Value: 1}},
+			Options: options.Index().SetName("ois_1").SetUnique(true).SetPartialFilterExpression(bson.D{{Key: "cs.ois", Value: bson.D{{Key: "$exists", Value: true}}}}),
+		},
+	}
+}
+
 type playerEncoder struct{}
 
 func (codec *playerEncoder) IsEmpty(ptr unsafe.Pointer) bool {
@@ -460,22 +1208,28 @@ func (codec *playerEncoder) IsEmpty(ptr unsafe.Pointer) bool {
 
 func (codec *playerEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
 	p := ((*defaultPlayer)(ptr))
-	stream.WriteObjectStart()
-	stream.WriteObjectField("uid")
-	stream.WriteInt(p.uid)
-	stream.WriteMore()
-	stream.WriteObjectField("wallet")
-	stream.WriteVal(p.wallet)
-	stream.WriteMore()
-	stream.WriteObjectField("equipments")
-	stream.WriteVal(p.equipments)
-	stream.WriteMore()
-	stream.WriteObjectField("items")
-	stream.WriteVal(p.items)
-	stream.WriteMore()
-	stream.WriteObjectField("cash")
-	stream.WriteVal(p.cash)
-	stream.WriteObjectEnd()
+	object := bsonmodel.BeginJsonObject(stream)
+	if p.partial.Loaded(BnamePlayerUid) {
+		object.Field("uid")
+		stream.WriteInt(p.uid)
+	}
+	if p.partial.Loaded(BnamePlayerWallet) {
+		object.Field("wallet")
+		stream.WriteVal(p.wallet)
+	}
+	if p.partial.Loaded(BnamePlayerEquipments) {
+		object.Field("equipments")
+		stream.WriteVal(p.equipments)
+	}
+	if p.partial.Loaded(BnamePlayerItems) {
+		object.Field("items")
+		stream.WriteVal(p.items)
+	}
+	if p.partial.Loaded(BnamePlayerCash) {
+		object.Field("cash")
+		stream.WriteVal(p.cash)
+	}
+	object.End()
 }
 
 func init() {
@@ -0,0 +1,94 @@
+//go:build bsonmodel_debug
+// +build bsonmodel_debug
+
+package example
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fmjsjx/bson-model-go/bsonmodel"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestUseAfterRelease(t *testing.T) {
+	uses := map[string]func(player Player){
+		"Uid":           func(player Player) { player.Uid() },
+		"SetUid":        func(player Player) { player.SetUid(1) },
+		"Wallet":        func(player Player) { player.Wallet() },
+		"Equipments":    func(player Player) { player.Equipments() },
+		"Equipment":     func(player Player) { player.Equipment("id") },
+		"Items":         func(player Player) { player.Items() },
+		"Cash":          func(player Player) { player.Cash() },
+		"SetUpdateTime": func(player Player) { player.SetUpdateTime(time.Now()) },
+		"LoadDocument":  func(player Player) { player.LoadDocument(bson.M{}) },
+		"LoadRaw":       func(player Player) { player.LoadRaw(nil) },
+		"ToDocument":    func(player Player) { player.ToDocument() },
+		"ToData":        func(player Player) { player.ToData() },
+		"ToSync":        func(player Player) { player.ToSync() },
+		"ToUpdate":      func(player Player) { player.ToUpdate() },
+		"MarshalBSON":   func(player Player) { player.MarshalBSON() },
+		"GetField":      func(player Player) { player.GetField(BnamePlayerUid) },
+		"Clone":         func(player Player) { player.Clone() },
+		"Observers":     func(player Player) { player.Observers() },
+		"Release":       func(player Player) { player.Release() },
+	}
+	for name, use := range uses {
+		player := AcquirePlayer()
+		player.SetUid(123)
+		player.Release()
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic on %s but not", name)
+				}
+			}()
+			use(player)
+		}()
+	}
+}
+
+type releasedChildren struct {
+	wallet     Wallet
+	cash       CashInfo
+	equipments bsonmodel.StringObjectMapModel
+	equipment  Equipment
+	items      bsonmodel.IntSimpleMapModel
+}
+
+func TestUseChildrenAfterRelease(t *testing.T) {
+	uses := map[string]func(c *releasedChildren){
+		"Wallet.CoinTotal":    func(c *releasedChildren) { c.wallet.CoinTotal() },
+		"Wallet.SetCoinTotal": func(c *releasedChildren) { c.wallet.SetCoinTotal(1) },
+		"Cash.SetCards":       func(c *releasedChildren) { c.cash.SetCards([]int{1}) },
+		"Cash.Stages.Put":     func(c *releasedChildren) { c.cash.Stages().Put(1, 2) },
+		"Equipments.Put":      func(c *releasedChildren) { c.equipments.Put("2", NewEquipment()) },
+		"Equipments.Get":      func(c *releasedChildren) { c.equipments.Get("1") },
+		"Equipment.Atk":       func(c *releasedChildren) { c.equipment.Atk() },
+		"Equipment.SetAtk":    func(c *releasedChildren) { c.equipment.SetAtk(1) },
+		"Items.Put":           func(c *releasedChildren) { c.items.Put(1, 1) },
+		"Items.Keys":          func(c *releasedChildren) { c.items.Keys() },
+	}
+	for name, use := range uses {
+		player := AcquirePlayer()
+		equipment := NewEquipment()
+		equipment.SetId("1")
+		player.Equipments().Put(equipment.Id(), equipment)
+		children := &releasedChildren{
+			wallet:     player.Wallet(),
+			cash:       player.Cash(),
+			equipments: player.Equipments(),
+			equipment:  equipment,
+			items:      player.Items(),
+		}
+		player.Release()
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic on %s but not", name)
+				}
+			}()
+			use(children)
+		}()
+	}
+}
@@ -1,6 +1,7 @@
 package example
 
 import (
+	"io"
 	"unsafe"
 
 	"github.com/bits-and-blooms/bitset"
@@ -13,11 +14,16 @@ type Wallet interface {
 	bsonmodel.ObjectModel
 	CoinTotal() int
 	SetCoinTotal(coinTotal int)
+	OnCoinTotalChanged(listener func(oldValue int, newValue int)) (remove func(), err error)
 	CoinUsed() int
 	SetCoinUsed(coinUsed int)
+	OnCoinUsedChanged(listener func(oldValue int, newValue int)) (remove func(), err error)
 	Coin() int
 	Diamond() int
 	SetDiamond(diamond int)
+	OnDiamondChanged(listener func(oldValue int, newValue int)) (remove func(), err error)
+	Clone() Wallet
+	CopyFrom(other Wallet)
 }
 
 const (
@@ -26,10 +32,26 @@ const (
 	BnameWalletDiamond   = "d"
 )
 
+var walletSchema = &bsonmodel.ModelSchema{
+	Name: "Wallet",
+	Type: bsonmodel.ModelTypeObject,
+	Fields: []*bsonmodel.FieldSchema{
+		{Name: "coinTotal", Bname: "ct", Type: bsonmodel.FieldTypeInt},
+		{Name: "coinUsed", Bname: "cu", Type: bsonmodel.FieldTypeInt, JsonIgnore: true},
+		{Name: "coin", Type: bsonmodel.FieldTypeInt, Virtual: true, Sources: []string{"coinTotal", "coinUsed"}},
+		{Name: "diamond", Bname: "d", Type: bsonmodel.FieldTypeInt, Long: true},
+	},
+}
+
+func WalletSchema() *bsonmodel.ModelSchema {
+	return walletSchema
+}
+
 var xpathWallet bsonmodel.DotNotation = bsonmodel.PathOfNames("wlt")
 
 type defaultWallet struct {
 	updatedFields *bitset.BitSet
+	released      bool
 	parent        Player
 	coinTotal     int
 	coinUsed      int
@@ -37,10 +59,12 @@ type defaultWallet struct {
 }
 
 func (self *defaultWallet) ToBson() interface{} {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	return self.ToDocument()
 }
 
 func (self *defaultWallet) ToData() interface{} {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	data := make(map[string]interface{})
 	data["ct"] = self.coinTotal
 	data["cu"] = self.coinUsed
@@ -49,6 +73,8 @@ func (self *defaultWallet) ToData() interface{} {
 }
 
 func (self *defaultWallet) LoadJsoniter(any jsoniter.Any) error {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	bsonmodel.SaveState(self)
 	if any.ValueType() != jsoniter.ObjectValue {
 		return nil
 	}
@@ -70,15 +96,58 @@ func (self *defaultWallet) LoadJsoniter(any jsoniter.Any) error {
 	return nil
 }
 
+func (self *defaultWallet) LoadJsonIterator(iter *jsoniter.Iterator) error {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	bsonmodel.SaveState(self)
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		return bsonmodel.IterError(iter)
+	}
+	self.coinTotal = 0
+	self.coinUsed = 0
+	self.diamond = 0
+	var err error
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, field string) bool {
+		switch field {
+		case BnameWalletCoinTotal, "coinTotal":
+			self.coinTotal, err = bsonmodel.IterIntValue(iter, 0)
+		case BnameWalletCoinUsed:
+			self.coinUsed, err = bsonmodel.IterIntValue(iter, 0)
+		case BnameWalletDiamond, "diamond":
+			self.diamond, err = bsonmodel.IterIntValue(iter, 0)
+		default:
+			iter.Skip()
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return bsonmodel.IterError(iter)
+}
+
 func (self *defaultWallet) Reset() {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	bsonmodel.SaveState(self)
 	self.updatedFields.ClearAll()
 }
 
+func (self *defaultWallet) clear() {
+	self.coinTotal = 0
+	self.coinUsed = 0
+	self.diamond = 0
+	if bsonmodel.Debug {
+		self.released = true
+	}
+}
+
 func (self *defaultWallet) AnyUpdated() bool {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	return self.updatedFields.Any()
 }
 
 func (self *defaultWallet) AnyDeleted() bool {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	return self.DeletedSize() > 0
 }
 
@@ -91,6 +160,7 @@ func (self *defaultWallet) XPath() bsonmodel.DotNotation {
 }
 
 func (self *defaultWallet) AppendUpdates(updates bson.M) bson.M {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	dset := bsonmodel.FixedEmbedded(updates, "$set")
 	xpath := self.XPath()
 	if self.FullyUpdate() {
@@ -111,6 +181,7 @@ func (self *defaultWallet) AppendUpdates(updates bson.M) bson.M {
 }
 
 func (self *defaultWallet) ToDocument() bson.M {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	doc := bson.M{}
 	doc["ct"] = self.coinTotal
 	doc["cu"] = self.coinUsed
@@ -119,6 +190,8 @@ func (self *defaultWallet) ToDocument() bson.M {
 }
 
 func (self *defaultWallet) LoadDocument(document bson.M) error {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	bsonmodel.SaveState(self)
 	coinTotal, err := bsonmodel.IntValue(document, "ct", 0)
 	if err != nil {
 		return err
@@ -137,7 +210,40 @@ func (self *defaultWallet) LoadDocument(document bson.M) error {
 	return nil
 }
 
+func (self *defaultWallet) LoadRaw(raw bson.Raw) error {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	bsonmodel.SaveState(self)
+	coinTotal, err := bsonmodel.RawIntValue(raw, "ct", 0)
+	if err != nil {
+		return err
+	}
+	self.coinTotal = coinTotal
+	coinUsed, err := bsonmodel.RawIntValue(raw, "cu", 0)
+	if err != nil {
+		return err
+	}
+	self.coinUsed = coinUsed
+	diamond, err := bsonmodel.RawIntValue(raw, "d", 0)
+	if err != nil {
+		return err
+	}
+	self.diamond = diamond
+	return nil
+}
+
+func (self *defaultWallet) LoadDocumentTracked(document bson.M) error {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	loaded := NewWallet(nil)
+	err := loaded.LoadDocument(document)
+	if err != nil {
+		return err
+	}
+	self.CopyFrom(loaded)
+	return nil
+}
+
 func (self *defaultWallet) DeletedSize() int {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	return 0
 }
 
@@ -146,6 +252,7 @@ func (self *defaultWallet) FullyUpdate() bool {
 }
 
 func (self *defaultWallet) SetFullyUpdate(fullyUpdate bool) {
+	bsonmodel.SaveState(self)
 	if fullyUpdate {
 		self.updatedFields.Set(0)
 	} else {
@@ -153,9 +260,24 @@ func (self *defaultWallet) SetFullyUpdate(fullyUpdate bool) {
 	}
 }
 
+func (self *defaultWallet) Snapshot() func() {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	updatedFields := self.updatedFields.Clone()
+	coinTotal := self.coinTotal
+	coinUsed := self.coinUsed
+	diamond := self.diamond
+	return func() {
+		self.updatedFields = updatedFields
+		self.coinTotal = coinTotal
+		self.coinUsed = coinUsed
+		self.diamond = diamond
+	}
+}
+
 func (self *defaultWallet) ToSync() interface{} {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	if self.FullyUpdate() {
-		return self
+		return bsonmodel.FullySync(self)
 	}
 	sync := make(map[string]interface{})
 	updatedFields := self.updatedFields
@@ -172,70 +294,261 @@ func (self *defaultWallet) ToSync() interface{} {
 }
 
 func (self *defaultWallet) ToDelete() interface{} {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	delete := make(map[string]interface{})
 	return delete
 }
 
 func (self *defaultWallet) ToDataJson() (string, error) {
-	return jsoniter.MarshalToString(self.ToData())
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	return bsonmodel.DataJsonOf(self)
 }
 
 func (self *defaultWallet) ToSyncJson() (string, error) {
-	return jsoniter.MarshalToString(self.ToSync())
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	return bsonmodel.SyncJsonOf(self)
 }
 
 func (self *defaultWallet) ToDeleteJson() (string, error) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	return jsoniter.MarshalToString(self.ToDelete())
 }
 
+func (self *defaultWallet) EncodeData(stream *jsoniter.Stream) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	object := bsonmodel.BeginJsonObject(stream)
+	object.Field("ct")
+	stream.WriteInt(self.coinTotal)
+	object.Field("cu")
+	stream.WriteInt(self.coinUsed)
+	object.Field("d")
+	stream.WriteInt(self.diamond)
+	object.End()
+}
+
+func (self *defaultWallet) EncodeSync(stream *jsoniter.Stream) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	if self.FullyUpdate() {
+		stream.WriteVal(bsonmodel.FullySync(self))
+		return
+	}
+	object := bsonmodel.BeginJsonObject(stream)
+	if self.updatedFields.Test(1) {
+		object.Field("coinTotal")
+		stream.WriteInt(self.coinTotal)
+	}
+	if self.updatedFields.Test(3) {
+		object.Field("coin")
+		stream.WriteInt(self.Coin())
+	}
+	if self.updatedFields.Test(4) {
+		object.Field("diamond")
+		stream.WriteInt(self.diamond)
+	}
+	object.End()
+}
+
+func (self *defaultWallet) WriteDataJson(w io.Writer) error {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	return bsonmodel.WriteDataJson(w, self)
+}
+
+func (self *defaultWallet) WriteSyncJson(w io.Writer) error {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	return bsonmodel.WriteSyncJson(w, self)
+}
+
+func (self *defaultWallet) ToMergePatch() interface{} {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	return bsonmodel.MergePatchOf(self)
+}
+
+func (self *defaultWallet) ToJsonPatch() []bsonmodel.JsonPatchOperation {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	return bsonmodel.JsonPatchOf(self)
+}
+
+func (self *defaultWallet) Changes() []bsonmodel.Change {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	return bsonmodel.ChangesOf(self)
+}
+
+func (self *defaultWallet) Schema() *bsonmodel.ModelSchema {
+	return walletSchema
+}
+
+func (self *defaultWallet) GetField(name string) (interface{}, error) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	switch name {
+	case BnameWalletCoinTotal:
+		return self.coinTotal, nil
+	case BnameWalletCoinUsed:
+		return self.coinUsed, nil
+	case BnameWalletDiamond:
+		return self.diamond, nil
+	default:
+		return nil, bsonmodel.NoSuchFieldError(name)
+	}
+}
+
+func (self *defaultWallet) SetField(name string, value interface{}) error {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	switch name {
+	case BnameWalletCoinTotal:
+		v, err := bsonmodel.ParseInt(value)
+		if err != nil {
+			return err
+		}
+		self.SetCoinTotal(v)
+	case BnameWalletCoinUsed:
+		v, err := bsonmodel.ParseInt(value)
+		if err != nil {
+			return err
+		}
+		self.SetCoinUsed(v)
+	case BnameWalletDiamond:
+		v, err := bsonmodel.ParseInt(value)
+		if err != nil {
+			return err
+		}
+		self.SetDiamond(v)
+	default:
+		return bsonmodel.NoSuchFieldError(name)
+	}
+	return nil
+}
+
+func (self *defaultWallet) GetPath(xpath bsonmodel.DotNotation) (interface{}, error) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	return bsonmodel.GetPath(self, xpath)
+}
+
+func (self *defaultWallet) SetPath(xpath bsonmodel.DotNotation, value interface{}) error {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	return bsonmodel.SetPath(self, xpath, value)
+}
+
 func (self *defaultWallet) MarshalJSON() ([]byte, error) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	return jsoniter.Marshal(self)
 }
 
 func (self *defaultWallet) CoinTotal() int {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	return self.coinTotal
 }
 
 func (self *defaultWallet) SetCoinTotal(coinTotal int) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	if self.coinTotal != coinTotal {
+		bsonmodel.SaveState(self)
+		old := self.coinTotal
 		self.coinTotal = coinTotal
 		self.updatedFields.Set(1)
 		self.updatedFields.Set(3)
+		bsonmodel.EmitChange(self, "ct", old, coinTotal)
 	}
 }
 
+func (self *defaultWallet) OnCoinTotalChanged(listener func(oldValue int, newValue int)) (remove func(), err error) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	return bsonmodel.ObserveField(self, BnameWalletCoinTotal, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(int), event.NewValue.(int))
+	})
+}
+
 func (self *defaultWallet) CoinUsed() int {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	return self.coinUsed
 }
 
 func (self *defaultWallet) SetCoinUsed(coinUsed int) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	if self.coinUsed != coinUsed {
+		bsonmodel.SaveState(self)
+		old := self.coinUsed
 		self.coinUsed = coinUsed
 		self.updatedFields.Set(2)
 		self.updatedFields.Set(3)
+		bsonmodel.EmitChange(self, "cu", old, coinUsed)
 	}
 }
 
+func (self *defaultWallet) OnCoinUsedChanged(listener func(oldValue int, newValue int)) (remove func(), err error) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	return bsonmodel.ObserveField(self, BnameWalletCoinUsed, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(int), event.NewValue.(int))
+	})
+}
+
 func (self *defaultWallet) Coin() int {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	return self.coinTotal - self.coinUsed
 }
 
 func (self *defaultWallet) Diamond() int {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	return self.diamond
 }
 
 func (self *defaultWallet) SetDiamond(diamond int) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
 	if self.diamond != diamond {
+		bsonmodel.SaveState(self)
+		old := self.diamond
 		self.diamond = diamond
 		self.updatedFields.Set(4)
+		bsonmodel.EmitChange(self, "d", old, diamond)
 	}
 }
 
+func (self *defaultWallet) OnDiamondChanged(listener func(oldValue int, newValue int)) (remove func(), err error) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	return bsonmodel.ObserveField(self, BnameWalletDiamond, func(event *bsonmodel.ChangeEvent) {
+		listener(event.OldValue.(int), event.NewValue.(int))
+	})
+}
+
+func (self *defaultWallet) Clone() Wallet {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	clone := NewWallet(nil)
+	clone.CopyFrom(self)
+	clone.Reset()
+	return clone
+}
+
+func (self *defaultWallet) CopyFrom(other Wallet) {
+	bsonmodel.CheckReleased(self.released, "Wallet")
+	self.SetCoinTotal(other.CoinTotal())
+	self.SetCoinUsed(other.CoinUsed())
+	self.SetDiamond(other.Diamond())
+}
+
 func NewWallet(parent Player) Wallet {
 	self := &defaultWallet{updatedFields: &bitset.BitSet{}, parent: parent}
 	return self
 }
 
+type WalletPaths struct {
+	base bsonmodel.DotNotation
+}
+
+func (paths WalletPaths) Path() bsonmodel.DotNotation {
+	return paths.base
+}
+
+func (paths WalletPaths) CoinTotal() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameWalletCoinTotal)
+}
+
+func (paths WalletPaths) CoinUsed() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameWalletCoinUsed)
+}
+
+func (paths WalletPaths) Diamond() bsonmodel.DotNotation {
+	return paths.base.Resolve(BnameWalletDiamond)
+}
+
 type walletEncoder struct{}
 
 func (codec *walletEncoder) IsEmpty(ptr unsafe.Pointer) bool {
@@ -244,16 +557,14 @@ func (codec *walletEncoder) IsEmpty(ptr unsafe.Pointer) bool {
 
 func (codec *walletEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
 	p := ((*defaultWallet)(ptr))
-	stream.WriteObjectStart()
-	stream.WriteObjectField("coinTotal")
+	object := bsonmodel.BeginJsonObject(stream)
+	object.Field("coinTotal")
 	stream.WriteInt(p.coinTotal)
-	stream.WriteMore()
-	stream.WriteObjectField("coin")
+	object.Field("coin")
 	stream.WriteInt(p.Coin())
-	stream.WriteMore()
-	stream.WriteObjectField("diamond")
+	object.Field("diamond")
 	stream.WriteInt(p.diamond)
-	stream.WriteObjectEnd()
+	object.End()
 }
 
 func init() {